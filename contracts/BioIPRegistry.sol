@@ -392,6 +392,21 @@ contract BioIPRegistry is ERC1155, Ownable {
         emit PermissionRevoked(tokenId, wallet, msg.sender);
     }
 
+    /**
+     * @dev Get the number of direct children of a BioIP
+     */
+    function childCount(uint256 parentTokenId) external view returns (uint256) {
+        return bioips[parentTokenId].childTokenIds.length;
+    }
+
+    /**
+     * @dev Get a single direct child of a BioIP by index
+     */
+    function childTokenIdAt(uint256 parentTokenId, uint256 index) external view returns (uint256) {
+        require(index < bioips[parentTokenId].childTokenIds.length, "Index out of range");
+        return bioips[parentTokenId].childTokenIds[index];
+    }
+
     /**
      * @dev Get lineage for a BioIP (all ancestors)
      */
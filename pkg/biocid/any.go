@@ -0,0 +1,77 @@
+package biocid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scheme identifies the URI scheme of a parsed identifier
+type Scheme int
+
+const (
+	// SchemeBioCID is a complete biocid:// identifier
+	SchemeBioCID Scheme = iota + 1
+
+	// SchemeBiofs is a biofs:// URI naming the NFT but not the content
+	SchemeBiofs
+)
+
+// String returns the scheme name
+func (s Scheme) String() string {
+	switch s {
+	case SchemeBioCID:
+		return "biocid"
+	case SchemeBiofs:
+		return "biofs"
+	default:
+		return "unknown"
+	}
+}
+
+// Identifier is the result of ParseAny
+type Identifier struct {
+	Scheme Scheme
+	BioCID *BioCID   // Complete for biocid://; for biofs:// only Version and the NFT fields are set
+	URL    *BiofsURL // The parsed URI for biofs://, nil otherwise
+}
+
+// NeedsResolution reports whether the BioCID lacks its ContentHash and ConsentSig
+// and must be completed from on-chain data before use
+func (id *Identifier) NeedsResolution() bool {
+	return id.Scheme == SchemeBiofs
+}
+
+// ParseAny parses either a biocid:// identifier or a biofs:// URI
+// A biofs:// URI yields a partial BioCID; check NeedsResolution before relying on it
+func ParseAny(s string) (*Identifier, error) {
+	if isBiofsURI(s) {
+		u, err := ParseBiofsURL(s)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Identifier{
+			Scheme: SchemeBiofs,
+			BioCID: &BioCID{
+				Version:    "v1",
+				Chain:      normalizeChain(u.Chain),
+				Collection: normalizeCollection(u.Collection),
+				TokenID:    u.TokenID,
+			},
+			URL: u,
+		}, nil
+	}
+
+	b, err := ParseBioCID(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w (expected biocid:// or biofs://)", err)
+	}
+
+	return &Identifier{Scheme: SchemeBioCID, BioCID: b}, nil
+}
+
+// isBiofsURI reports whether s uses the biofs scheme, in either of the forms ParseBiofsURL accepts
+func isBiofsURI(s string) bool {
+	scheme, _, ok := strings.Cut(strings.TrimSpace(s), ":")
+	return ok && strings.EqualFold(scheme, "biofs")
+}
@@ -0,0 +1,91 @@
+package biocid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAny(t *testing.T) {
+	b := validBioCID()
+	handle, err := b.ToBase58()
+	if err != nil {
+		t.Fatalf("ToBase58 failed: %v", err)
+	}
+	lower := strings.ToLower(testCollection)
+
+	tests := []struct {
+		name       string
+		input      string
+		wantScheme Scheme
+		wantRef    NFTReference
+		wantPath   string
+		wantErr    error
+	}{
+		{name: "biocid", input: b.String(), wantScheme: SchemeBioCID, wantRef: b.NFTRef()},
+		{
+			name:       "biofs with path",
+			input:      "biofs://story/" + testCollection + "/1/calls/chr1.vcf",
+			wantScheme: SchemeBiofs,
+			wantRef:    b.NFTRef(),
+			wantPath:   "/calls/chr1.vcf",
+		},
+		{name: "biofs without path", input: "biofs://story/" + testCollection + "/1", wantScheme: SchemeBiofs, wantRef: b.NFTRef(), wantPath: "/"},
+		{name: "biofs normalized", input: "biofs://Story/" + lower + "/1", wantScheme: SchemeBiofs, wantRef: b.NFTRef(), wantPath: "/"},
+		{name: "biofs missing token", input: "biofs://story/" + testCollection, wantErr: ErrInvalidURI},
+		{name: "bare base58 handle", input: handle, wantErr: ErrInvalidBioCID},
+		{name: "empty", input: "", wantErr: ErrInvalidBioCID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseAny(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAny failed: %v", err)
+			}
+
+			if id.Scheme != tt.wantScheme {
+				t.Errorf("scheme = %s, want %s", id.Scheme, tt.wantScheme)
+			}
+			if got := id.BioCID.NFTRef(); got != tt.wantRef {
+				t.Errorf("reference = %s, want %s", got, tt.wantRef)
+			}
+
+			if tt.wantScheme == SchemeBioCID {
+				if id.NeedsResolution() || id.URL != nil || !id.BioCID.Equal(b) {
+					t.Errorf("got %+v, want the complete BioCID", id)
+				}
+				return
+			}
+			if !id.NeedsResolution() {
+				t.Error("biofs identifier does not need resolution")
+			}
+			if id.BioCID.ContentHash != "" || id.BioCID.ConsentSig != "" {
+				t.Errorf("partial BioCID carries content %q and signature %q", id.BioCID.ContentHash, id.BioCID.ConsentSig)
+			}
+			if id.URL == nil || id.URL.Path != tt.wantPath {
+				t.Errorf("URL = %+v, want path %q", id.URL, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestParseBioCIDRejectsBiofs(t *testing.T) {
+	_, err := ParseBioCID("biofs://story/" + testCollection + "/1")
+	if !errors.Is(err, ErrInvalidBioCID) || !strings.Contains(err.Error(), "ParseAny") {
+		t.Errorf("got %v, want a pointer to ParseAny", err)
+	}
+}
+
+func TestSchemeString(t *testing.T) {
+	for scheme, want := range map[Scheme]string{SchemeBioCID: "biocid", SchemeBiofs: "biofs", Scheme(0): "unknown"} {
+		if got := scheme.String(); got != want {
+			t.Errorf("Scheme(%d) = %q, want %q", scheme, got, want)
+		}
+	}
+}
@@ -2,10 +2,18 @@ package biocid
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"reflect"
 	"strings"
+	"unicode"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/multiformats/go-multibase"
 	"github.com/multiformats/go-multihash"
 )
@@ -14,47 +22,110 @@ import (
 // Format: biocid://v1/<chain>/<collection>/<tokenId>/<contentHash>/<consentSig>
 type BioCID struct {
 	Version     string // Protocol version (v1)
-	Chain       string // EVM chain (story, avalanche, ethereum)
+	Chain       Chain  // EVM chain (story, avalanche, ethereum)
 	Collection  string // NFT contract address
 	TokenID     string // Token ID
 	ContentHash string // SHA256 hash of content
 	ConsentSig  string // Owner's consent signature
+
+	// ConsentFormat is the consent message format ConsentSig was produced under
+	// Zero means unknown; verification then tries every supported format
+	// It is held in memory only: String, ParseBioCID and JSON do not carry it and Equal ignores
+	// it, so parsed and decoded BioCIDs start at zero
+	ConsentFormat uint8
 }
 
 // NFTReference identifies the NFT that gates access to content
 type NFTReference struct {
-	Chain      string
+	Chain      Chain
 	Collection string
 	TokenID    string
 }
 
 // NewBioCID creates a new BioCID from components
-func NewBioCID(chain, collection, tokenID string, content []byte, consentSig string) (*BioCID, error) {
+// A non-empty consentSig is stored in canonical form (see CanonicalizeSig)
+func NewBioCID(chain Chain, collection, tokenID string, content []byte, consentSig string) (*BioCID, error) {
 	// Validate inputs
 	if chain == "" || collection == "" || tokenID == "" {
 		return nil, fmt.Errorf("chain, collection, and tokenID are required")
 	}
 
+	consentSig, err := canonicalConsentSig(consentSig)
+	if err != nil {
+		return nil, err
+	}
+
 	// Compute content hash
-	hash := sha256.Sum256(content)
-	contentHash := hex.EncodeToString(hash[:])
+	contentHash := ContentHash(sha256.Sum256(content)).Hex()
 
 	return &BioCID{
 		Version:     "v1",
-		Chain:       chain,
-		Collection:  collection,
+		Chain:       normalizeChain(chain),
+		Collection:  normalizeCollection(collection),
 		TokenID:     tokenID,
 		ContentHash: contentHash,
 		ConsentSig:  consentSig,
 	}, nil
 }
 
+// hashChunkSize bounds memory use when hashing streamed content
+const hashChunkSize = 1 << 20
+
+// NewBioCIDFromReader creates a new BioCID by streaming content from r
+// Large genomic files (VCF, BAM) are hashed without being loaded into memory
+func NewBioCIDFromReader(chain Chain, collection, tokenID string, r io.Reader, consentSig string) (*BioCID, error) {
+	if chain == "" || collection == "" || tokenID == "" {
+		return nil, fmt.Errorf("chain, collection, and tokenID are required")
+	}
+
+	consentSig, err := canonicalConsentSig(consentSig)
+	if err != nil {
+		return nil, err
+	}
+
+	contentHash, err := hashReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BioCID{
+		Version:     "v1",
+		Chain:       normalizeChain(chain),
+		Collection:  normalizeCollection(collection),
+		TokenID:     tokenID,
+		ContentHash: contentHash,
+		ConsentSig:  consentSig,
+	}, nil
+}
+
+// canonicalConsentSig canonicalizes a consent signature, leaving an empty one unsigned
+func canonicalConsentSig(sig string) (string, error) {
+	if sig == "" {
+		return "", nil
+	}
+	return CanonicalizeSig(sig)
+}
+
+// hashReader returns the hex SHA256 of everything read from r
+func hashReader(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.CopyBuffer(hasher, r, make([]byte, hashChunkSize)); err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 // ParseBioCID parses a BioCID string
 // Format: biocid://v1/<chain>/<collection>/<tokenId>/<contentHash>/<consentSig>
+// The consent signature may be omitted; use IsSigned to tell such BioCIDs apart
+// The signature is kept verbatim so String round-trips it; Normalize puts it in canonical form
 func ParseBioCID(s string) (*BioCID, error) {
 	// Remove biocid:// prefix
 	if !strings.HasPrefix(s, "biocid://") {
-		return nil, fmt.Errorf("invalid biocid: must start with biocid://")
+		if isBiofsURI(s) {
+			return nil, fmt.Errorf("%w: got a biofs:// URI, which must be resolved on-chain; use ParseAny", ErrInvalidBioCID)
+		}
+		return nil, fmt.Errorf("%w: must start with biocid://", ErrInvalidBioCID)
 	}
 
 	s = strings.TrimPrefix(s, "biocid://")
@@ -62,31 +133,124 @@ func ParseBioCID(s string) (*BioCID, error) {
 	// Split into components
 	parts := strings.Split(s, "/")
 	if len(parts) < 5 {
-		return nil, fmt.Errorf("invalid biocid format: expected at least 5 parts, got %d", len(parts))
+		return nil, fmt.Errorf("%w: expected at least 5 parts, got %d", ErrInvalidBioCID, len(parts))
+	}
+
+	// Consent sig may contain / when produced by older encoders
+	segments := append(parts[:5:5], strings.Join(parts[5:], "/"))
+
+	return fromSegments(segments)
+}
+
+// bioCIDSegments names the BioCID string segments in order, for error messages
+var bioCIDSegments = []string{"version", "chain", "collection", "tokenID", "contentHash", "consentSig"}
+
+// ParseBioCIDStrict parses a BioCID string, rejecting inputs ParseBioCID tolerates
+// Empty segments, extra or missing segments, and whitespace are all errors
+func ParseBioCIDStrict(s string) (*BioCID, error) {
+	if !strings.HasPrefix(s, "biocid://") {
+		return nil, fmt.Errorf("%w: must start with biocid://", ErrInvalidBioCID)
+	}
+
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		return nil, fmt.Errorf("%w: whitespace at offset %d", ErrInvalidBioCID, i)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(s, "biocid://"), "/")
+	if len(segments) != len(bioCIDSegments) {
+		return nil, fmt.Errorf("%w: expected %d segments, got %d", ErrInvalidBioCID, len(bioCIDSegments), len(segments))
+	}
+
+	for i, seg := range segments {
+		if seg == "" {
+			return nil, fmt.Errorf("%w: %s segment is empty", ErrInvalidBioCID, bioCIDSegments[i])
+		}
+	}
+
+	b, err := fromSegments(segments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Escaped whitespace is rejected as well
+	for i, seg := range []string{b.Version, b.Chain.String(), b.Collection, b.TokenID, b.ContentHash, b.ConsentSig} {
+		if strings.IndexFunc(seg, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("%w: %s segment contains whitespace", ErrInvalidBioCID, bioCIDSegments[i])
+		}
+	}
+
+	return b, nil
+}
+
+// fromSegments percent-decodes the six BioCID segments into a BioCID
+func fromSegments(segments []string) (*BioCID, error) {
+	for i, seg := range segments {
+		decoded, err := url.PathUnescape(seg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s segment: %w", ErrInvalidBioCID, bioCIDSegments[i], err)
+		}
+		segments[i] = decoded
 	}
 
 	return &BioCID{
-		Version:     parts[0],
-		Chain:       parts[1],
-		Collection:  parts[2],
-		TokenID:     parts[3],
-		ContentHash: parts[4],
-		ConsentSig:  strings.Join(parts[5:], "/"), // Consent sig may contain /
+		Version:     segments[0],
+		Chain:       normalizeChain(Chain(segments[1])),
+		Collection:  normalizeCollection(segments[2]),
+		TokenID:     segments[3],
+		ContentHash: segments[4],
+		ConsentSig:  segments[5],
 	}, nil
 }
 
 // String returns the BioCID as a string
+// Each segment is percent-encoded so ParseBioCID(b.String()) round-trips
 func (b *BioCID) String() string {
 	return fmt.Sprintf("biocid://%s/%s/%s/%s/%s/%s",
+		url.PathEscape(b.Version),
+		url.PathEscape(b.Chain.String()),
+		url.PathEscape(b.Collection),
+		url.PathEscape(b.TokenID),
+		url.PathEscape(b.ContentHash),
+		url.PathEscape(b.ConsentSig),
+	)
+}
+
+// ShortString returns an abbreviated form of the BioCID for logs and UIs
+// The collection, content hash, and signature are cut to their leading and trailing characters
+// The scheme is written as "biocid:" rather than "biocid://" so the result never parses as a BioCID
+func (b *BioCID) ShortString() string {
+	sig := "unsigned"
+	if b.IsSigned() {
+		sig = "sig:" + abbreviate(b.ConsentSig, 6, 4)
+	}
+
+	return fmt.Sprintf("biocid:%s/%s/%s/%s/%s/%s",
 		b.Version,
 		b.Chain,
-		b.Collection,
+		abbreviate(b.Collection, 6, 4),
 		b.TokenID,
-		b.ContentHash,
-		b.ConsentSig,
+		abbreviate(b.ContentHash, 4, 4),
+		sig,
 	)
 }
 
+// abbreviate keeps the first head and last tail characters of s, joined by an ellipsis
+// Characters are counted in runes so multi-byte ones are never split
+// Strings too short to gain from abbreviation are returned unchanged
+func abbreviate(s string, head, tail int) string {
+	runes := []rune(s)
+	if len(runes) <= head+tail+1 {
+		return s
+	}
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+// IsSigned reports whether the BioCID carries a consent signature
+// It does not check that the signature is well-formed or valid
+func (b *BioCID) IsSigned() bool {
+	return b.ConsentSig != ""
+}
+
 // NFTRef returns the NFT reference from this BioCID
 func (b *BioCID) NFTRef() NFTReference {
 	return NFTReference{
@@ -96,8 +260,54 @@ func (b *BioCID) NFTRef() NFTReference {
 	}
 }
 
+// TokenIDBig returns the token ID as a big.Int
+func (b *BioCID) TokenIDBig() (*big.Int, error) {
+	return ParseTokenID(b.TokenID)
+}
+
+// ParseTokenID parses a non-negative decimal token ID
+// Hex, signed, and non-numeric forms are rejected
+func ParseTokenID(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: tokenID is required", ErrInvalidTokenID)
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("%w: %q is not a non-negative decimal integer", ErrInvalidTokenID, s)
+		}
+	}
+
+	tokenID, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a non-negative decimal integer", ErrInvalidTokenID, s)
+	}
+
+	return tokenID, nil
+}
+
 // ToMultihash converts BioCID to a multihash (for DHT)
+// The preimage is length-prefixed and versioned so distinct BioCIDs never share a digest
 func (b *BioCID) ToMultihash() (multihash.Multihash, error) {
+	hash := b.Digest()
+
+	mh, err := multihash.Encode(hash[:], multihash.SHA2_256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multihash: %w", err)
+	}
+
+	return mh, nil
+}
+
+// Digest returns the SHA256 identifier digest wrapped by ToMultihash
+// This is the bytes32 bioCID value stored on-chain
+func (b *BioCID) Digest() [32]byte {
+	return sha256.Sum256(b.identifierPreimage())
+}
+
+// ToMultihashLegacy returns the multihash produced by the original colon-joined scheme
+// Distinct BioCIDs can collide under this scheme; use it only to match old identifiers
+func (b *BioCID) ToMultihashLegacy() (multihash.Multihash, error) {
 	// Create unique identifier from BioCID components
 	identifier := fmt.Sprintf("%s:%s:%s:%s",
 		b.Chain,
@@ -118,6 +328,27 @@ func (b *BioCID) ToMultihash() (multihash.Multihash, error) {
 	return mh, nil
 }
 
+// identifierDomain separates BioCID identifier preimages from other SHA256 inputs
+const identifierDomain = "biocid-identifier"
+
+// identifierVersion is bumped whenever the preimage layout changes
+const identifierVersion byte = 1
+
+// identifierPreimage encodes the identifying fields unambiguously
+// Layout: domain || version || (uint32 big-endian length || bytes) for each field
+func (b *BioCID) identifierPreimage() []byte {
+	fields := []string{b.Chain.String(), b.Collection, b.TokenID, b.ContentHash}
+
+	buf := []byte(identifierDomain)
+	buf = append(buf, identifierVersion)
+	for _, field := range fields {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+
+	return buf
+}
+
 // ToBase58 returns the BioCID encoded as base58
 func (b *BioCID) ToBase58() (string, error) {
 	mh, err := b.ToMultihash()
@@ -133,59 +364,154 @@ func (b *BioCID) ToBase58() (string, error) {
 	return encoded, nil
 }
 
-// Validate checks if the BioCID is valid
+// Validate checks if the BioCID is valid against DefaultChains
 func (b *BioCID) Validate() error {
+	return b.ValidateWith(DefaultChains)
+}
+
+// ValidateWith checks if the BioCID is valid against the given chain registry
+func (b *BioCID) ValidateWith(chains *ChainRegistry) error {
 	if b.Version != "v1" {
-		return fmt.Errorf("unsupported version: %s", b.Version)
+		return fmt.Errorf("%w: unsupported version: %s", ErrInvalidBioCID, b.Version)
 	}
 
 	if b.Chain == "" {
-		return fmt.Errorf("chain is required")
+		return fmt.Errorf("%w: chain is required", ErrInvalidBioCID)
 	}
 
-	validChains := map[string]bool{
-		"story":     true,
-		"avalanche": true,
-		"ethereum":  true,
-	}
-	if !validChains[b.Chain] {
-		return fmt.Errorf("unsupported chain: %s", b.Chain)
+	if _, ok := chains.Lookup(b.Chain.String()); !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChain, b.Chain)
 	}
 
-	if !strings.HasPrefix(b.Collection, "0x") || len(b.Collection) != 42 {
-		return fmt.Errorf("invalid collection address: %s", b.Collection)
+	if err := validateCollection(b.Collection); err != nil {
+		return err
 	}
 
 	if b.TokenID == "" {
-		return fmt.Errorf("tokenID is required")
+		return fmt.Errorf("%w: tokenID is required", ErrInvalidBioCID)
+	}
+
+	if _, err := ParseTokenID(b.TokenID); err != nil {
+		return err
+	}
+
+	if _, err := ParseContentHash(b.ContentHash); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBioCID, err)
+	}
+
+	// The identifier digest covers the hash as written, so only the lowercase form is canonical
+	if b.ContentHash != strings.ToLower(b.ContentHash) {
+		return fmt.Errorf("%w: %w: must be lowercase hex", ErrInvalidBioCID, ErrInvalidContentHash)
 	}
 
-	if len(b.ContentHash) != 64 { // SHA256 hex = 64 chars
-		return fmt.Errorf("invalid content hash length: expected 64, got %d", len(b.ContentHash))
+	if !b.IsSigned() {
+		return fmt.Errorf("%w: %w", ErrInvalidBioCID, ErrUnsignedBioCID)
 	}
 
 	if !strings.HasPrefix(b.ConsentSig, "0x") {
-		return fmt.Errorf("invalid consent signature: must start with 0x")
+		return fmt.Errorf("%w: invalid consent signature: must start with 0x", ErrInvalidBioCID)
+	}
+
+	return nil
+}
+
+// ValidateAll validates every BioCID against DefaultChains and reports all failures
+// The returned error joins one error per invalid BioCID, tagged with its index and string form
+func ValidateAll(cids []*BioCID) error {
+	var errs []error
+	for i, b := range cids {
+		if b == nil {
+			errs = append(errs, fmt.Errorf("biocid[%d]: %w: nil", i, ErrInvalidBioCID))
+			continue
+		}
+
+		if err := b.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("biocid[%d] %s: %w", i, b, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Normalize lowercases the chain, checksums the collection address and canonicalizes the
+// consent signature in place
+// A signature CanonicalizeSig rejects is left as-is so Validate can report it
+func (b *BioCID) Normalize() {
+	b.Chain = normalizeChain(b.Chain)
+	b.Collection = normalizeCollection(b.Collection)
+	if sig, err := CanonicalizeSig(b.ConsentSig); err == nil {
+		b.ConsentSig = sig
+	}
+}
+
+// normalizeChain returns the canonical lowercase chain name
+func normalizeChain(chain Chain) Chain {
+	return Chain(strings.ToLower(chain.String()))
+}
+
+// normalizeCollection returns the EIP-55 checksummed form of a collection address
+// Addresses with an incorrect mixed-case checksum are left as-is so Validate rejects them
+func normalizeCollection(collection string) string {
+	if !strings.HasPrefix(collection, "0x") || !common.IsHexAddress(collection) {
+		return collection
+	}
+
+	checksummed := common.HexToAddress(collection).Hex()
+	digits := collection[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return checksummed
+	}
+
+	return collection
+}
+
+// validateCollection checks that a collection is a hex address with a valid EIP-55 checksum
+// All-lowercase and all-uppercase addresses carry no checksum and are accepted
+func validateCollection(collection string) error {
+	if !strings.HasPrefix(collection, "0x") || !common.IsHexAddress(collection) {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, collection)
+	}
+
+	digits := collection[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+
+	expected := common.HexToAddress(collection).Hex()
+	if collection != expected {
+		return fmt.Errorf("%w: checksum mismatch: %s (expected %s)", ErrInvalidAddress, collection, expected)
 	}
 
 	return nil
 }
 
 // Equal checks if two BioCIDs are equal
+// Chain and collection are compared in normalized form; ConsentFormat is ignored
 func (b *BioCID) Equal(other *BioCID) bool {
+	return b.EqualContent(other) && b.ConsentSig == other.ConsentSig
+}
+
+// EqualContent checks if two BioCIDs identify the same content on the same token
+// It compares everything Equal does except the consent signature
+func (b *BioCID) EqualContent(other *BioCID) bool {
 	return b.Version == other.Version &&
-		b.Chain == other.Chain &&
-		b.Collection == other.Collection &&
+		normalizeChain(b.Chain) == normalizeChain(other.Chain) &&
+		normalizeCollection(b.Collection) == normalizeCollection(other.Collection) &&
 		b.TokenID == other.TokenID &&
-		b.ContentHash == other.ContentHash &&
-		b.ConsentSig == other.ConsentSig
+		b.ContentHash == other.ContentHash
 }
 
 // VerifyContent verifies that content matches the hash in BioCID
 func (b *BioCID) VerifyContent(content []byte) bool {
-	hash := sha256.Sum256(content)
-	computedHash := hex.EncodeToString(hash[:])
-	return computedHash == b.ContentHash
+	return ContentHash(sha256.Sum256(content)).Hex() == b.ContentHash
+}
+
+// VerifyContentReader verifies that streamed content matches the hash in BioCID
+func (b *BioCID) VerifyContentReader(r io.Reader) (bool, error) {
+	computedHash, err := hashReader(r)
+	if err != nil {
+		return false, err
+	}
+	return computedHash == b.ContentHash, nil
 }
 
 // NFTReference methods
@@ -195,15 +521,20 @@ func (n NFTReference) String() string {
 	return fmt.Sprintf("%s/%s/%s", n.Chain, n.Collection, n.TokenID)
 }
 
+// TokenIDBig returns the token ID as a big.Int
+func (n NFTReference) TokenIDBig() (*big.Int, error) {
+	return ParseTokenID(n.TokenID)
+}
+
 // ParseNFTRef parses an NFT reference string
 func ParseNFTRef(s string) (NFTReference, error) {
 	parts := strings.Split(s, "/")
 	if len(parts) != 3 {
-		return NFTReference{}, fmt.Errorf("invalid nft reference format")
+		return NFTReference{}, fmt.Errorf("%w: invalid nft reference format", ErrInvalidURI)
 	}
 
 	return NFTReference{
-		Chain:      parts[0],
+		Chain:      Chain(parts[0]),
 		Collection: parts[1],
 		TokenID:    parts[2],
 	}, nil
@@ -212,6 +543,44 @@ func ParseNFTRef(s string) (NFTReference, error) {
 // ToBiofsURI converts BioCID to a biofs:// URI
 // Format: biofs://<chain>/<collection>/<tokenId>/<path>
 func (b *BioCID) ToBiofsURI(path string) string {
+	return b.ToBiofsURIWithQuery(path, nil, "")
+}
+
+// ToBiofsURIWithQuery converts BioCID to a biofs:// URI with a query string and fragment
+// Format: biofs://<chain>/<collection>/<tokenId>/<path>?<query>#<fragment>
+func (b *BioCID) ToBiofsURIWithQuery(path string, query url.Values, fragment string) string {
+	return (&BiofsURL{
+		NFTReference: b.NFTRef(),
+		Path:         path,
+		Query:        query,
+		Fragment:     fragment,
+	}).String()
+}
+
+// BiofsURL is a parsed biofs:// URI
+// The embedded NFTReference provides Chain, Collection, and TokenID
+type BiofsURL struct {
+	NFTReference
+	Path     string     // Decoded file path, always starting with "/"
+	Query    url.Values // e.g. region=chr1:100-200 or range=0-1023
+	Fragment string     // Decoded fragment
+
+	// Encoded forms seen by ParseBiofsURL, reused by String while the fields are unchanged
+	rawPath     string
+	rawQuery    string
+	rawFragment string
+}
+
+// BiofsURI is a parsed biofs:// URI
+//
+// Deprecated: use BiofsURL
+type BiofsURI = BiofsURL
+
+// String encodes the URI, escaping the path, query, and fragment as needed
+// A URL returned by ParseBiofsURL and left unmodified encodes back to its input
+// in canonical biofs:// form, keeping the original percent-encoding and query order
+func (u *BiofsURL) String() string {
+	path := u.Path
 	if path == "" {
 		path = "/"
 	}
@@ -219,50 +588,108 @@ func (b *BioCID) ToBiofsURI(path string) string {
 		path = "/" + path
 	}
 
-	return fmt.Sprintf("biofs://%s/%s/%s%s",
-		b.Chain,
-		b.Collection,
-		b.TokenID,
-		path,
-	)
+	full := "/" + u.Collection + "/" + u.TokenID + path
+	if path == "/" {
+		// Keep a parsed URI without a file path free of the trailing slash
+		if unescaped, err := url.PathUnescape(u.rawPath); err == nil && unescaped+"/" == full {
+			full = unescaped
+		}
+	}
+
+	// net/url ignores RawPath and RawFragment unless they decode to Path and Fragment
+	out := url.URL{
+		Scheme:      "biofs",
+		Host:        u.Chain.String(),
+		Path:        full,
+		RawPath:     u.rawPath,
+		RawQuery:    u.Query.Encode(),
+		Fragment:    u.Fragment,
+		RawFragment: u.rawFragment,
+	}
+	if u.rawQuery != "" {
+		if parsed, err := url.ParseQuery(u.rawQuery); err == nil && reflect.DeepEqual(parsed, u.Query) {
+			out.RawQuery = u.rawQuery
+		}
+	}
+	return out.String()
 }
 
 // ParseBiofsURI parses a biofs:// URI and returns NFT reference and path
+// Any query string or fragment is dropped; use ParseBiofsURL to keep them
 func ParseBiofsURI(uri string) (NFTReference, string, error) {
-	// Remove biofs:// prefix
-	if !strings.HasPrefix(uri, "biofs://") {
-		return NFTReference{}, "", fmt.Errorf("invalid biofs URI: must start with biofs://")
+	parsed, err := ParseBiofsURL(uri)
+	if err != nil {
+		return NFTReference{}, "", err
+	}
+
+	return parsed.NFTReference, parsed.Path, nil
+}
+
+// ParseBiofsURIFull parses a biofs:// URI including its query string and fragment
+//
+// Deprecated: use ParseBiofsURL
+func ParseBiofsURIFull(uri string) (*BiofsURI, error) {
+	return ParseBiofsURL(uri)
+}
+
+// ParseBiofsURL parses a biofs:// URI including its query string and fragment
+// Surrounding whitespace is trimmed, the scheme is matched case-insensitively, and the
+// non-hierarchical biofs:<chain>/... form is accepted; the path and fragment are
+// percent-decoded following net/url semantics
+func ParseBiofsURL(raw string) (*BiofsURL, error) {
+	raw = strings.TrimSpace(raw)
+
+	scheme, rest, ok := strings.Cut(raw, ":")
+	if !ok || !strings.EqualFold(scheme, "biofs") {
+		return nil, fmt.Errorf("%w: must start with biofs://", ErrInvalidURI)
+	}
+	if !strings.HasPrefix(rest, "//") {
+		rest = "//" + rest
 	}
 
-	uri = strings.TrimPrefix(uri, "biofs://")
+	u, err := url.Parse("biofs:" + rest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
 
-	// Split into parts
-	parts := strings.SplitN(uri, "/", 4)
-	if len(parts) < 3 {
-		return NFTReference{}, "", fmt.Errorf("invalid biofs URI format")
+	// Split into collection, tokenId, and path
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 3)
+	if u.Host == "" || len(parts) < 2 {
+		return nil, fmt.Errorf("%w: expected <chain>/<collection>/<tokenId>", ErrInvalidURI)
 	}
 
-	nftRef := NFTReference{
-		Chain:      parts[0],
-		Collection: parts[1],
-		TokenID:    parts[2],
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid query: %w", ErrInvalidURI, err)
 	}
 
 	path := "/"
-	if len(parts) == 4 {
-		path = "/" + parts[3]
+	if len(parts) == 3 {
+		path = "/" + parts[2]
 	}
 
-	return nftRef, path, nil
+	return &BiofsURL{
+		NFTReference: NFTReference{
+			Chain:      Chain(u.Host),
+			Collection: parts[0],
+			TokenID:    parts[1],
+		},
+		Path:        path,
+		Query:       query,
+		Fragment:    u.Fragment,
+		rawPath:     u.EscapedPath(),
+		rawQuery:    u.RawQuery,
+		rawFragment: u.EscapedFragment(),
+	}, nil
 }
 
 // DerivativeInfo represents derivative relationship metadata
 type DerivativeInfo struct {
-	ParentBioCID   *BioCID   // Parent BioCID (nil if root)
-	ChildBioCIDs   []*BioCID // Child BioCIDs
-	Generation     int       // 0=root, 1=child, 2=grandchild, etc
-	LicenseTokenID string    // License token used to create this derivative
-	LicenseTermsID string    // PIL license terms ID
+	ParentBioCID   *BioCID   `json:"parentBioCID"`   // Parent BioCID (nil if root)
+	ChildBioCIDs   []*BioCID `json:"childBioCIDs"`   // Child BioCIDs
+	Generation     int       `json:"generation"`     // 0=root, 1=child, 2=grandchild, etc
+	LicenseTokenID string    `json:"licenseTokenId"` // License token used to create this derivative
+	LicenseTermsID string    `json:"licenseTermsId"` // PIL license terms ID
 }
 
 // IsRoot returns true if this is a root BioIP (no parent)
@@ -300,10 +727,10 @@ func (d *DerivativeInfo) SetParent(parent *BioCID) {
 
 // LineageMetadata represents complete lineage information
 type LineageMetadata struct {
-	Self       *BioCID   // Current BioCID
-	Ancestors  []*BioCID // All ancestors (parent, grandparent, etc)
-	Descendants []*BioCID // All descendants (children, grandchildren, etc)
-	Generation int       // Generation number (0=root)
+	Self        *BioCID   `json:"self"`        // Current BioCID
+	Ancestors   []*BioCID `json:"ancestors"`   // All ancestors (parent, grandparent, etc)
+	Descendants []*BioCID `json:"descendants"` // All descendants (children, grandchildren, etc)
+	Generation  int       `json:"generation"`  // Generation number (0=root)
 }
 
 // GetAncestorCount returns number of ancestors
@@ -338,4 +765,4 @@ func (l *LineageMetadata) GetParent() *BioCID {
 		return l.Ancestors[0]
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,597 @@
+package biocid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const (
+	testCollection = "0xC91940118822d247B46d1eba6b7Ed2a16F3adc36"
+	testHash       = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	// testSig is a well-formed low-s signature, so Normalize keeps it verbatim
+	testSig = "0x" + testHash + "1f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" + "1b"
+)
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		sig  string
+	}{
+		{name: "unsigned", sig: ""},
+		{name: "hex signature", sig: testSig},
+		{name: "high-s signature", sig: highS(t, testSig)},
+		{name: "uppercase signature", sig: "0x" + strings.ToUpper(testSig[2:])},
+		{name: "slash", sig: "abc/def"},
+		{name: "percent", sig: "100%"},
+		{name: "query and fragment", sig: "a?b#c"},
+		{name: "space", sig: "a b"},
+		{name: "plus", sig: "a+b"},
+		{name: "control bytes", sig: "a\x00b\nc\x7f"},
+		{name: "invalid UTF-8", sig: "a\xff\xfeb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &BioCID{
+				Version:     "v1",
+				Chain:       "story",
+				Collection:  testCollection,
+				TokenID:     "1",
+				ContentHash: testHash,
+				ConsentSig:  tt.sig,
+			}
+
+			parsed, err := ParseBioCID(b.String())
+			if err != nil {
+				t.Fatalf("ParseBioCID(%q) failed: %v", b.String(), err)
+			}
+			if !parsed.Equal(b) {
+				t.Errorf("got %+v, want %+v", parsed, b)
+			}
+			if parsed.String() != b.String() {
+				t.Errorf("reformatted as %s, want %s", parsed, b)
+			}
+		})
+	}
+}
+
+func TestParseBioCIDLegacySlashInSig(t *testing.T) {
+	parsed, err := ParseBioCID("biocid://v1/story/" + testCollection + "/1/" + testHash + "/abc/def")
+	if err != nil {
+		t.Fatalf("ParseBioCID failed: %v", err)
+	}
+	if parsed.ConsentSig != "abc/def" {
+		t.Errorf("got sig %q, want %q", parsed.ConsentSig, "abc/def")
+	}
+}
+
+func TestNormalizeCanonicalizesSig(t *testing.T) {
+	key, _ := testKey(t)
+	canonical, err := SignConsent("story", testCollection, "1", testHash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		sig  string
+		want string
+	}{
+		{name: "canonical", sig: canonical, want: canonical},
+		{name: "high s", sig: highS(t, canonical), want: canonical},
+		{name: "uppercase hex", sig: "0x" + strings.ToUpper(canonical[2:]), want: canonical},
+		{name: "legacy slash", sig: "abc/def", want: "abc/def"},
+		{name: "wrong length", sig: canonical[:len(canonical)-4], want: canonical[:len(canonical)-4]},
+		{name: "unsigned", sig: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseBioCID("biocid://v1/story/" + testCollection + "/1/" + testHash + "/" + tt.sig)
+			if err != nil {
+				t.Fatalf("ParseBioCID failed: %v", err)
+			}
+			if parsed.ConsentSig != tt.sig {
+				t.Errorf("parsed sig %q, want it verbatim", parsed.ConsentSig)
+			}
+
+			parsed.Normalize()
+			if parsed.ConsentSig != tt.want {
+				t.Errorf("normalized sig %q, want %q", parsed.ConsentSig, tt.want)
+			}
+		})
+	}
+}
+
+// validBioCID returns a BioCID that passes Validate
+func validBioCID() *BioCID {
+	return &BioCID{
+		Version:     "v1",
+		Chain:       "story",
+		Collection:  testCollection,
+		TokenID:     "1",
+		ContentHash: testHash,
+		ConsentSig:  testSig,
+	}
+}
+
+func TestValidateCollectionChecksum(t *testing.T) {
+	tests := []struct {
+		name       string
+		collection string
+		wantErr    bool
+	}{
+		{name: "checksummed", collection: testCollection},
+		{name: "lowercase", collection: strings.ToLower(testCollection)},
+		{name: "uppercase", collection: "0x" + strings.ToUpper(testCollection[2:])},
+		{name: "bad checksum", collection: "0xc91940118822D247B46d1eBA6B7Ed2A16F3aDC36", wantErr: true},
+		{name: "too short", collection: "0xC91940118822D247B46d1eBA6B7Ed2A16F3aDC", wantErr: true},
+		{name: "missing prefix", collection: testCollection[2:], wantErr: true},
+		{name: "not hex", collection: "0xZ91940118822D247B46d1eBA6B7Ed2A16F3aDC36", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.Collection = tt.collection
+
+			err := b.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidAddress)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseTokenID(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokenID string
+		want    string
+		wantErr bool
+	}{
+		{name: "zero", tokenID: "0", want: "0"},
+		{name: "decimal", tokenID: "42", want: "42"},
+		{name: "leading zeros", tokenID: "007", want: "7"},
+		{name: "beyond uint64", tokenID: "115792089237316195423570985008687907853269984665640564039457584007913129639935", want: "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{name: "empty", tokenID: "", wantErr: true},
+		{name: "negative", tokenID: "-1", wantErr: true},
+		{name: "signed", tokenID: "+1", wantErr: true},
+		{name: "hex", tokenID: "0x1", wantErr: true},
+		{name: "non-numeric", tokenID: "abc", wantErr: true},
+		{name: "whitespace", tokenID: " 1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTokenID(tt.tokenID)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTokenID) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidTokenID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTokenID failed: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTokenID(t *testing.T) {
+	b := validBioCID()
+	b.TokenID = "-5"
+	if err := b.Validate(); !errors.Is(err, ErrInvalidTokenID) {
+		t.Errorf("got %v, want %v", err, ErrInvalidTokenID)
+	}
+}
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestNewBioCIDFromReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "empty", content: nil},
+		{name: "small", content: []byte("##fileformat=VCFv4.2\n")},
+		{name: "spans several chunks", content: bytes.Repeat([]byte("ACGT"), hashChunkSize)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := NewBioCID("story", testCollection, "1", tt.content, "")
+			if err != nil {
+				t.Fatalf("NewBioCID failed: %v", err)
+			}
+			got, err := NewBioCIDFromReader("story", testCollection, "1", bytes.NewReader(tt.content), "")
+			if err != nil {
+				t.Fatalf("NewBioCIDFromReader failed: %v", err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got, want)
+			}
+
+			ok, err := got.VerifyContentReader(bytes.NewReader(tt.content))
+			if err != nil || !ok {
+				t.Errorf("VerifyContentReader = %v, %v; want true", ok, err)
+			}
+			if ok, _ := got.VerifyContentReader(strings.NewReader("tampered")); ok {
+				t.Error("VerifyContentReader accepted different content")
+			}
+		})
+	}
+}
+
+func TestNewBioCIDFromReaderError(t *testing.T) {
+	if _, err := NewBioCIDFromReader("story", testCollection, "1", errReader{}, ""); err == nil {
+		t.Error("expected read error")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name           string
+		chain          Chain
+		collection     string
+		wantChain      Chain
+		wantCollection string
+	}{
+		{name: "canonical", chain: "story", collection: testCollection, wantChain: "story", wantCollection: testCollection},
+		{name: "uppercase chain", chain: "STORY", collection: testCollection, wantChain: "story", wantCollection: testCollection},
+		{name: "lowercase collection", chain: "Story", collection: strings.ToLower(testCollection), wantChain: "story", wantCollection: testCollection},
+		{name: "uppercase collection", chain: "story", collection: "0x" + strings.ToUpper(testCollection[2:]), wantChain: "story", wantCollection: testCollection},
+		{name: "bad checksum kept", chain: "story", collection: "0xc91940118822D247B46d1eBA6B7Ed2A16F3aDC36", wantChain: "story", wantCollection: "0xc91940118822D247B46d1eBA6B7Ed2A16F3aDC36"},
+		{name: "not an address", chain: "story", collection: "collection", wantChain: "story", wantCollection: "collection"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.Chain, b.Collection = tt.chain, tt.collection
+			b.Normalize()
+			if b.Chain != tt.wantChain || b.Collection != tt.wantCollection {
+				t.Errorf("got %s/%s, want %s/%s", b.Chain, b.Collection, tt.wantChain, tt.wantCollection)
+			}
+
+			// Parsing and construction normalize the same way
+			parsed, err := ParseBioCID("biocid://v1/" + tt.chain.String() + "/" + tt.collection + "/1/" + testHash + "/")
+			if err != nil {
+				t.Fatalf("ParseBioCID failed: %v", err)
+			}
+			if parsed.Chain != tt.wantChain || parsed.Collection != tt.wantCollection {
+				t.Errorf("parsed %s/%s, want %s/%s", parsed.Chain, parsed.Collection, tt.wantChain, tt.wantCollection)
+			}
+		})
+	}
+}
+
+func TestEqualNormalizes(t *testing.T) {
+	a := validBioCID()
+	b := validBioCID()
+	b.Chain = "STORY"
+	b.Collection = strings.ToLower(testCollection)
+	if !a.Equal(b) {
+		t.Error("BioCIDs differing only in case should be equal")
+	}
+}
+
+func TestToMultihashSeparatesFields(t *testing.T) {
+	tuple := func(chain Chain, collection, tokenID, contentHash string) *BioCID {
+		return &BioCID{Version: "v1", Chain: chain, Collection: collection, TokenID: tokenID, ContentHash: contentHash}
+	}
+
+	tests := []struct {
+		name string
+		a, b *BioCID
+	}{
+		{name: "colon shifted between fields", a: tuple("story", "a:b", "1", testHash), b: tuple("story", "a", "b:1", testHash)},
+		{name: "empty field", a: tuple("story", "", "x:1", testHash), b: tuple("story:", "x", "1", testHash)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legacyA, err := tt.a.ToMultihashLegacy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			legacyB, err := tt.b.ToMultihashLegacy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(legacyA, legacyB) {
+				t.Fatal("expected the tuples to collide under the legacy scheme")
+			}
+
+			mhA, err := tt.a.ToMultihash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			mhB, err := tt.b.ToMultihash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(mhA, mhB) {
+				t.Error("distinct tuples share a multihash")
+			}
+		})
+	}
+}
+
+func TestDigestPreimage(t *testing.T) {
+	b := validBioCID()
+
+	var preimage []byte
+	preimage = append(preimage, "biocid-identifier"...)
+	preimage = append(preimage, 1)
+	for _, field := range []string{"story", testCollection, "1", testHash} {
+		preimage = binary.BigEndian.AppendUint32(preimage, uint32(len(field)))
+		preimage = append(preimage, field...)
+	}
+	want := sha256.Sum256(preimage)
+
+	if got := b.Digest(); got != want {
+		t.Fatalf("Digest() = %x, want %x", got, want)
+	}
+
+	mh, err := b.ToMultihash()
+	if err != nil {
+		t.Fatalf("ToMultihash failed: %v", err)
+	}
+	// SHA2-256 multihash: 0x12 code, 0x20 length, then the digest
+	if !bytes.Equal(mh[:2], []byte{0x12, 0x20}) || !bytes.Equal(mh[2:], want[:]) {
+		t.Errorf("ToMultihash() = %x, want 1220%x", []byte(mh), want)
+	}
+
+	// The consent signature does not contribute to the identifier
+	unsigned := validBioCID()
+	unsigned.ConsentSig = ""
+	if unsigned.Digest() != want {
+		t.Error("consent signature changed the digest")
+	}
+}
+
+func TestParseBioCIDStrict(t *testing.T) {
+	valid := validBioCID().String()
+	sig := testSig
+	prefix := "biocid://v1/story/" + testCollection + "/1/"
+
+	tests := []struct {
+		name    string
+		input   string
+		wantMsg string // Expected fragment of the error message
+		lenient bool   // Whether ParseBioCID accepts the input
+	}{
+		{name: "valid", input: valid, lenient: true},
+		{name: "double slash", input: "biocid://v1/story//1/" + testHash + "/" + sig, wantMsg: "collection segment is empty", lenient: true},
+		{name: "trailing slash", input: valid + "/", wantMsg: "expected 6 segments, got 7", lenient: true},
+		{name: "missing consent sig", input: prefix + testHash, wantMsg: "expected 6 segments, got 5", lenient: true},
+		{name: "empty consent sig", input: prefix + testHash + "/", wantMsg: "consentSig segment is empty", lenient: true},
+		{name: "whitespace", input: prefix + " " + testHash + "/" + sig, wantMsg: "whitespace at offset", lenient: true},
+		{name: "escaped whitespace", input: prefix + "%20" + testHash + "/" + sig, wantMsg: "contentHash segment contains whitespace", lenient: true},
+		{name: "bad escape", input: prefix + "%zz/" + sig, wantMsg: "contentHash segment"},
+		{name: "wrong scheme", input: "biofs://story/" + testCollection + "/1", wantMsg: "must start with biocid://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBioCIDStrict(tt.input)
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("ParseBioCIDStrict failed: %v", err)
+				}
+				if !got.Equal(validBioCID()) {
+					t.Errorf("got %s", got)
+				}
+			} else {
+				if !errors.Is(err, ErrInvalidBioCID) || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Errorf("got %v, want an ErrInvalidBioCID mentioning %q", err, tt.wantMsg)
+				}
+			}
+
+			if _, err := ParseBioCID(tt.input); (err == nil) != tt.lenient {
+				t.Errorf("ParseBioCID error = %v, want lenient acceptance %v", err, tt.lenient)
+			}
+		})
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	unsupported := validBioCID()
+	unsupported.Chain = "nowhere"
+	badHash := validBioCID()
+	badHash.ContentHash = "xyz"
+
+	tests := []struct {
+		name string
+		cids []*BioCID
+		want []string // Substrings of the joined error, one per failure
+	}{
+		{name: "empty", cids: nil},
+		{name: "all valid", cids: []*BioCID{validBioCID(), validBioCID()}},
+		{
+			name: "mixed",
+			cids: []*BioCID{validBioCID(), unsupported, nil, validBioCID(), badHash},
+			want: []string{"biocid[1] " + unsupported.String(), "biocid[2]: ", "biocid[4] " + badHash.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAll(tt.cids)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateAll failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != len(tt.want) {
+				t.Fatalf("got %v, want %d failures", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := joined.Unwrap()[i].Error(); !strings.HasPrefix(got, want) {
+					t.Errorf("failure %d = %q, want prefix %q", i, got, want)
+				}
+			}
+			if !errors.Is(err, ErrUnsupportedChain) || !errors.Is(err, ErrInvalidContentHash) {
+				t.Errorf("%v does not wrap every cause", err)
+			}
+		})
+	}
+}
+
+func TestValidateContentHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{name: "lowercase hex", hash: testHash},
+		{name: "uppercase hex", hash: strings.ToUpper(testHash), wantErr: true},
+		{name: "mixed case", hash: "9F" + testHash[2:], wantErr: true},
+		{name: "64 non-hex characters", hash: strings.Repeat("z", 64), wantErr: true},
+		{name: "one non-hex character", hash: testHash[:63] + "g", wantErr: true},
+		{name: "0x prefix", hash: "0x" + testHash[2:], wantErr: true},
+		{name: "short", hash: testHash[:62], wantErr: true},
+		{name: "empty", hash: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.ContentHash = tt.hash
+
+			err := b.Validate()
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Validate failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidBioCID) || !errors.Is(err, ErrInvalidContentHash) {
+				t.Errorf("got %v, want %v and %v", err, ErrInvalidBioCID, ErrInvalidContentHash)
+			}
+		})
+	}
+}
+
+func TestParseBioCIDMissingSig(t *testing.T) {
+	base := "biocid://v1/story/" + testCollection + "/1/" + testHash
+	sig := testSig
+
+	tests := []struct {
+		name       string
+		s          string
+		wantSigned bool
+		wantErr    error // From Validate
+	}{
+		{name: "signed", s: base + "/" + sig, wantSigned: true},
+		{name: "trailing slash", s: base + "/", wantErr: ErrUnsignedBioCID},
+		{name: "no signature segment", s: base, wantErr: ErrUnsignedBioCID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseBioCID(tt.s)
+			if err != nil {
+				t.Fatalf("ParseBioCID failed: %v", err)
+			}
+			if b.IsSigned() != tt.wantSigned {
+				t.Errorf("IsSigned = %v, want %v", b.IsSigned(), tt.wantSigned)
+			}
+
+			err = b.Validate()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidBioCID) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v and %v", err, ErrInvalidBioCID, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortString(t *testing.T) {
+	short := validBioCID()
+	short.Collection = "0xC9"
+	short.ContentHash = "9f86d081"
+	short.ConsentSig = "0x1b"
+	unsigned := validBioCID()
+	unsigned.ConsentSig = ""
+	multiByte := validBioCID()
+	multiByte.ConsentSig = "äöüßéèêëïîôû"
+
+	tests := []struct {
+		name string
+		b    *BioCID
+		want string
+	}{
+		{name: "signed", b: validBioCID(), want: "biocid:v1/story/0xC919…dc36/1/9f86…0a08/sig:0x9f86…081b"},
+		{name: "unsigned", b: unsigned, want: "biocid:v1/story/0xC919…dc36/1/9f86…0a08/unsigned"},
+		{name: "short fields kept whole", b: short, want: "biocid:v1/story/0xC9/1/9f86d081/sig:0x1b"},
+		{name: "multi-byte signature", b: multiByte, want: "biocid:v1/story/0xC919…dc36/1/9f86…0a08/sig:äöüßéè…ïîôû"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.b.ShortString()
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("ShortString %q is not valid UTF-8", got)
+			}
+			if _, err := ParseBioCID(got); err == nil {
+				t.Errorf("ShortString %q parses as a BioCID", got)
+			}
+		})
+	}
+}
+
+func TestEqualContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		edit        func(b *BioCID)
+		wantEqual   bool
+		wantContent bool
+	}{
+		{name: "identical", edit: func(b *BioCID) {}, wantEqual: true, wantContent: true},
+		{name: "other signature", edit: func(b *BioCID) { b.ConsentSig = testSig[:len(testSig)-2] + "1c" }, wantContent: true},
+		{name: "unsigned", edit: func(b *BioCID) { b.ConsentSig = "" }, wantContent: true},
+		{name: "collection case", edit: func(b *BioCID) { b.Collection = strings.ToLower(testCollection) }, wantEqual: true, wantContent: true},
+		{name: "other token", edit: func(b *BioCID) { b.TokenID = "2" }},
+		{name: "other content", edit: func(b *BioCID) { b.ContentHash = strings.Repeat("0", 64) }},
+		{name: "other chain", edit: func(b *BioCID) { b.Chain = "sepolia" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := validBioCID(), validBioCID()
+			tt.edit(b)
+			if got := a.Equal(b); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v", got, tt.wantEqual)
+			}
+			if got := a.EqualContent(b); got != tt.wantContent {
+				t.Errorf("EqualContent = %v, want %v", got, tt.wantContent)
+			}
+			if a.EqualContent(b) != b.EqualContent(a) {
+				t.Error("EqualContent is not symmetric")
+			}
+		})
+	}
+}
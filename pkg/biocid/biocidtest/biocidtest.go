@@ -0,0 +1,133 @@
+// Package biocidtest builds valid, deterministic BioCIDs for tests and fixtures
+package biocidtest
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Defaults used by New when not overridden
+const (
+	DefaultChain      = biocid.ChainStory
+	DefaultCollection = "0xC91940118822d247B46d1eba6b7Ed2a16F3adc36"
+	DefaultTokenID    = "1"
+	DefaultContent    = "biocidtest content"
+)
+
+// defaultKeyHex is a well-known throwaway key; never use it for anything of value
+const defaultKeyHex = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// DefaultKey signs consent for BioCIDs built by New unless WithKey is given
+var DefaultKey = mustKey(defaultKeyHex)
+
+// Option overrides a field of the BioCID built by New
+type Option func(*config)
+
+type config struct {
+	chain      biocid.Chain
+	collection string
+	tokenID    string
+	content    []byte
+	key        *ecdsa.PrivateKey
+	unsigned   bool
+}
+
+// WithChain sets the chain
+func WithChain(chain biocid.Chain) Option {
+	return func(c *config) {
+		c.chain = chain
+	}
+}
+
+// WithCollection sets the collection address
+func WithCollection(collection string) Option {
+	return func(c *config) {
+		c.collection = collection
+	}
+}
+
+// WithTokenID sets the token ID
+func WithTokenID(tokenID string) Option {
+	return func(c *config) {
+		c.tokenID = tokenID
+	}
+}
+
+// WithContent sets the content whose hash the BioCID carries
+func WithContent(content []byte) Option {
+	return func(c *config) {
+		c.content = content
+	}
+}
+
+// WithKey signs consent with key instead of DefaultKey
+func WithKey(key *ecdsa.PrivateKey) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+// Unsigned leaves the consent signature empty
+func Unsigned() Option {
+	return func(c *config) {
+		c.unsigned = true
+	}
+}
+
+// New returns a BioCID that passes Validate, signed by DefaultKey unless overridden
+// With Unsigned, Validate fails only with biocid.ErrUnsignedBioCID; the same options always
+// produce the same BioCID, and New panics if the options make that impossible
+func New(opts ...Option) *biocid.BioCID {
+	cfg := config{
+		chain:      DefaultChain,
+		collection: DefaultCollection,
+		tokenID:    DefaultTokenID,
+		content:    []byte(DefaultContent),
+		key:        DefaultKey,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	b, err := biocid.NewBioCID(cfg.chain, cfg.collection, cfg.tokenID, cfg.content, "")
+	if err != nil {
+		panic(fmt.Sprintf("biocidtest: %v", err))
+	}
+
+	if !cfg.unsigned {
+		sig, err := biocid.SignConsent(b.Chain, b.Collection, b.TokenID, b.ContentHash, cfg.key)
+		if err != nil {
+			panic(fmt.Sprintf("biocidtest: %v", err))
+		}
+		b.ConsentSig = sig
+		b.ConsentFormat = biocid.ConsentFormatCurrent
+	}
+
+	if err := b.Validate(); err != nil && !(cfg.unsigned && errors.Is(err, biocid.ErrUnsignedBioCID)) {
+		panic(fmt.Sprintf("biocidtest: %v", err))
+	}
+
+	return b
+}
+
+// Signer returns the address of key, or of DefaultKey if key is nil
+func Signer(key *ecdsa.PrivateKey) common.Address {
+	if key == nil {
+		key = DefaultKey
+	}
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// mustKey parses a hex private key
+func mustKey(hex string) *ecdsa.PrivateKey {
+	key, err := crypto.HexToECDSA(hex)
+	if err != nil {
+		panic(fmt.Sprintf("biocidtest: invalid key: %v", err))
+	}
+	return key
+}
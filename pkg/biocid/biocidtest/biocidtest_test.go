@@ -0,0 +1,84 @@
+package biocidtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDefaultCollectionChecksummed(t *testing.T) {
+	if want := common.HexToAddress(DefaultCollection).Hex(); DefaultCollection != want {
+		t.Errorf("DefaultCollection = %s, want the EIP-55 form %s", DefaultCollection, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantSigner common.Address
+		check      func(b *biocid.BioCID) bool
+		wantErr    error // From Validate
+	}{
+		{name: "defaults", wantSigner: Signer(nil), check: func(b *biocid.BioCID) bool {
+			return b.Chain == DefaultChain && b.TokenID == DefaultTokenID && b.VerifyContent([]byte(DefaultContent))
+		}},
+		{name: "chain", opts: []Option{WithChain("base")}, wantSigner: Signer(nil), check: func(b *biocid.BioCID) bool {
+			return b.Chain == "base"
+		}},
+		{name: "token", opts: []Option{WithTokenID("42")}, wantSigner: Signer(nil), check: func(b *biocid.BioCID) bool {
+			return b.TokenID == "42"
+		}},
+		{name: "content", opts: []Option{WithContent([]byte("##fileformat=VCFv4.2"))}, wantSigner: Signer(nil), check: func(b *biocid.BioCID) bool {
+			return b.VerifyContent([]byte("##fileformat=VCFv4.2"))
+		}},
+		{name: "lowercase collection", opts: []Option{WithCollection("0x00000000000000000000000000000000000000c3")}, wantSigner: Signer(nil), check: func(b *biocid.BioCID) bool {
+			return common.HexToAddress(b.Collection) == common.HexToAddress("0xc3")
+		}},
+		{name: "key", opts: []Option{WithKey(other)}, wantSigner: Signer(other)},
+		{name: "unsigned", opts: []Option{Unsigned()}, wantErr: biocid.ErrUnsignedBioCID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.opts...)
+			if !b.Equal(New(tt.opts...)) {
+				t.Error("same options built different BioCIDs")
+			}
+			if tt.check != nil && !tt.check(b) {
+				t.Errorf("option not applied: %+v", b)
+			}
+
+			err := b.Validate()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Validate = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+
+			if ok, err := biocid.VerifyConsentSig(b, tt.wantSigner); err != nil || !ok {
+				t.Errorf("not signed by %s: %v, %v", tt.wantSigner.Hex(), ok, err)
+			}
+		})
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to panic on an unusable collection")
+		}
+	}()
+	New(WithCollection("not an address"))
+}
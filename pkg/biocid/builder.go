@@ -0,0 +1,42 @@
+package biocid
+
+import "encoding/hex"
+
+// WithCollection returns a copy of b with its collection replaced
+// The copy's consent signature is cleared if the collection changes, since it no longer covers the BioCID
+func (b *BioCID) WithCollection(collection string) *BioCID {
+	out := *b
+	out.Collection = normalizeCollection(collection)
+	if out.Collection != b.Collection {
+		out.clearConsent()
+	}
+	return &out
+}
+
+// WithTokenID returns a copy of b with its token ID replaced
+// The copy's consent signature is cleared if the token ID changes
+func (b *BioCID) WithTokenID(tokenID string) *BioCID {
+	out := *b
+	out.TokenID = tokenID
+	if out.TokenID != b.TokenID {
+		out.clearConsent()
+	}
+	return &out
+}
+
+// WithContentHash returns a copy of b with its content hash set to the given SHA256 digest
+// The copy's consent signature is cleared if the content hash changes
+func (b *BioCID) WithContentHash(hash []byte) *BioCID {
+	out := *b
+	out.ContentHash = hex.EncodeToString(hash)
+	if out.ContentHash != b.ContentHash {
+		out.clearConsent()
+	}
+	return &out
+}
+
+// clearConsent drops a consent signature that no longer matches the signed fields
+func (b *BioCID) clearConsent() {
+	b.ConsentSig = ""
+	b.ConsentFormat = 0
+}
@@ -0,0 +1,99 @@
+package biocid
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuilders(t *testing.T) {
+	same := sha256.Sum256([]byte("test"))
+	other := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name      string
+		build     func(b *BioCID) *BioCID
+		check     func(b *BioCID) bool
+		wantValid bool // The copy keeps its consent signature
+	}{
+		{
+			name:  "new collection",
+			build: func(b *BioCID) *BioCID { return b.WithCollection("0x00000000000000000000000000000000000000C3") },
+			check: func(b *BioCID) bool { return b.Collection == "0x00000000000000000000000000000000000000C3" },
+		},
+		{
+			name:      "same collection in lowercase",
+			build:     func(b *BioCID) *BioCID { return b.WithCollection(strings.ToLower(testCollection)) },
+			check:     func(b *BioCID) bool { return b.Collection == testCollection },
+			wantValid: true,
+		},
+		{
+			name:  "new token",
+			build: func(b *BioCID) *BioCID { return b.WithTokenID("2") },
+			check: func(b *BioCID) bool { return b.TokenID == "2" },
+		},
+		{
+			name:      "same token",
+			build:     func(b *BioCID) *BioCID { return b.WithTokenID("1") },
+			check:     func(b *BioCID) bool { return b.TokenID == "1" },
+			wantValid: true,
+		},
+		{
+			name:  "new content",
+			build: func(b *BioCID) *BioCID { return b.WithContentHash(other[:]) },
+			check: func(b *BioCID) bool { return b.ContentHash == ContentHash(other).Hex() },
+		},
+		{
+			name:      "same content",
+			build:     func(b *BioCID) *BioCID { return b.WithContentHash(same[:]) },
+			check:     func(b *BioCID) bool { return b.ContentHash == testHash },
+			wantValid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.ConsentFormat = 2
+			orig := *b
+
+			got := tt.build(b)
+			if got == b {
+				t.Fatal("builder returned the receiver instead of a copy")
+			}
+			if *b != orig {
+				t.Errorf("receiver changed to %+v", *b)
+			}
+			if !tt.check(got) {
+				t.Errorf("field not replaced: %+v", *got)
+			}
+
+			if tt.wantValid {
+				if got.ConsentSig != orig.ConsentSig || got.ConsentFormat != orig.ConsentFormat {
+					t.Errorf("signature dropped for an unchanged field: %q (format %d)", got.ConsentSig, got.ConsentFormat)
+				}
+				return
+			}
+			if got.ConsentSig != "" || got.ConsentFormat != 0 {
+				t.Errorf("stale signature kept: %q (format %d)", got.ConsentSig, got.ConsentFormat)
+			}
+		})
+	}
+}
+
+func TestBuildersChain(t *testing.T) {
+	other := sha256.Sum256([]byte("other"))
+	b := validBioCID()
+
+	got := b.WithTokenID("9").WithContentHash(other[:])
+	if got.TokenID != "9" || got.ContentHash != ContentHash(other).Hex() || got.ConsentSig != "" {
+		t.Errorf("got %+v", *got)
+	}
+	// The rebuilt BioCID must be re-signed before it validates
+	if err := got.Validate(); !errors.Is(err, ErrUnsignedBioCID) {
+		t.Errorf("got %v, want %v", err, ErrUnsignedBioCID)
+	}
+	if b.TokenID != "1" || b.ConsentSig == "" {
+		t.Errorf("original changed to %+v", *b)
+	}
+}
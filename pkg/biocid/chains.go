@@ -0,0 +1,110 @@
+package biocid
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Chain names an EVM chain as it appears in BioCIDs and biofs:// URIs
+// Manager and BioCID APIs take a Chain; String and ParseChain convert to and from plain names
+type Chain string
+
+// Chains registered in DefaultChains
+const (
+	ChainStory     Chain = "story"
+	ChainAvalanche Chain = "avalanche"
+	ChainEthereum  Chain = "ethereum"
+	ChainPolygon   Chain = "polygon"
+	ChainBase      Chain = "base"
+)
+
+// ParseChain returns the chain named s, matched case-insensitively against DefaultChains
+func ParseChain(s string) (Chain, error) {
+	return DefaultChains.ParseChain(s)
+}
+
+// String returns the chain name
+func (c Chain) String() string {
+	return string(c)
+}
+
+// ChainInfo describes an EVM chain BioFS can address
+type ChainInfo struct {
+	Name    string   // Chain name used in BioCIDs and biofs:// URIs
+	RPCURL  string   // JSON-RPC endpoint
+	ChainID *big.Int // EIP-155 chain ID
+}
+
+// ChainRegistry holds the chains known to BioCID validation and RPC clients
+type ChainRegistry struct {
+	mu     sync.RWMutex
+	chains map[string]ChainInfo
+}
+
+// DefaultChains is the registry used by Validate and by managers created without a registry
+// Register private networks here to make them available package-wide
+var DefaultChains = NewDefaultChainRegistry()
+
+// NewChainRegistry creates an empty chain registry
+func NewChainRegistry() *ChainRegistry {
+	return &ChainRegistry{
+		chains: make(map[string]ChainInfo),
+	}
+}
+
+// NewDefaultChainRegistry creates a registry preloaded with the supported public chains
+func NewDefaultChainRegistry() *ChainRegistry {
+	r := NewChainRegistry()
+	r.Register(ChainStory.String(), "https://rpc.story.foundation", big.NewInt(1514))
+	r.Register(ChainAvalanche.String(), "https://api.avax.network/ext/bc/C/rpc", big.NewInt(43114))
+	r.Register(ChainEthereum.String(), "https://eth.llamarpc.com", big.NewInt(1))
+	r.Register(ChainPolygon.String(), "https://polygon-rpc.com", big.NewInt(137))
+	r.Register(ChainBase.String(), "https://mainnet.base.org", big.NewInt(8453))
+	return r
+}
+
+// Register adds or replaces a chain in the registry
+func (r *ChainRegistry) Register(name, rpcURL string, chainID *big.Int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chains[name] = ChainInfo{
+		Name:    name,
+		RPCURL:  rpcURL,
+		ChainID: chainID,
+	}
+}
+
+// Lookup returns the chain registered under name
+func (r *ChainRegistry) Lookup(name string) (ChainInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.chains[name]
+	return info, ok
+}
+
+// ParseChain returns the chain named s, matched case-insensitively against the registry
+func (r *ChainRegistry) ParseChain(s string) (Chain, error) {
+	chain := normalizeChain(Chain(strings.TrimSpace(s)))
+	if _, ok := r.Lookup(chain.String()); !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedChain, s)
+	}
+	return chain, nil
+}
+
+// Names returns the registered chain names in sorted order
+func (r *ChainRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.chains))
+	for name := range r.chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
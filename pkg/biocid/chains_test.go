@@ -0,0 +1,145 @@
+package biocid
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestChainRegistry(t *testing.T) {
+	r := NewChainRegistry()
+	r.Register("devnet", "http://127.0.0.1:8545", big.NewInt(1337))
+
+	info, ok := r.Lookup("devnet")
+	if !ok || info.RPCURL != "http://127.0.0.1:8545" || info.ChainID.Int64() != 1337 {
+		t.Fatalf("Lookup(devnet) = %+v, %v", info, ok)
+	}
+	if _, ok := r.Lookup("story"); ok {
+		t.Error("empty registry should not know story")
+	}
+
+	r.Register("devnet", "http://127.0.0.1:9545", big.NewInt(31337))
+	if info, _ := r.Lookup("devnet"); info.ChainID.Int64() != 31337 {
+		t.Errorf("Register did not replace devnet: %+v", info)
+	}
+
+	if got := NewDefaultChainRegistry().Names(); !reflect.DeepEqual(got, []string{"avalanche", "base", "ethereum", "polygon", "story"}) {
+		t.Errorf("default chains: got %v", got)
+	}
+}
+
+func TestValidateWithRegistry(t *testing.T) {
+	custom := NewChainRegistry()
+	custom.Register("devnet", "http://127.0.0.1:8545", big.NewInt(1337))
+
+	tests := []struct {
+		name    string
+		chain   Chain
+		chains  *ChainRegistry
+		wantErr error
+	}{
+		{name: "default chain", chain: "story", chains: DefaultChains},
+		{name: "unknown chain", chain: "devnet", chains: DefaultChains, wantErr: ErrUnsupportedChain},
+		{name: "registered chain", chain: "devnet", chains: custom},
+		{name: "chain missing from custom registry", chain: "story", chains: custom, wantErr: ErrUnsupportedChain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.Chain = tt.chain
+
+			err := b.ValidateWith(tt.chains)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateWith failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolygonAndBase(t *testing.T) {
+	tests := []struct {
+		chain   string
+		chainID int64
+	}{
+		{chain: "polygon", chainID: 137},
+		{chain: "base", chainID: 8453},
+	}
+	for _, tt := range tests {
+		t.Run(tt.chain, func(t *testing.T) {
+			info, ok := DefaultChains.Lookup(tt.chain)
+			if !ok || info.ChainID.Int64() != tt.chainID || info.RPCURL == "" {
+				t.Fatalf("Lookup(%s) = %+v, %v", tt.chain, info, ok)
+			}
+
+			b := validBioCID()
+			b.Chain = Chain(tt.chain)
+			parsed, err := ParseBioCID(b.String())
+			if err != nil {
+				t.Fatalf("ParseBioCID failed: %v", err)
+			}
+			if err := parsed.Validate(); err != nil {
+				t.Errorf("Validate failed: %v", err)
+			}
+
+			ref, err := ParseNFTRef(tt.chain + "/" + testCollection + "/1")
+			if err != nil {
+				t.Fatalf("ParseNFTRef failed: %v", err)
+			}
+			if _, ok := DefaultChains.Lookup(ref.Chain.String()); !ok {
+				t.Errorf("reference chain %q is not resolvable", ref.Chain)
+			}
+		})
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	custom := NewChainRegistry()
+	custom.Register("devnet", "http://127.0.0.1:8545", big.NewInt(1337))
+
+	tests := []struct {
+		name    string
+		parse   func(string) (Chain, error)
+		s       string
+		want    Chain
+		wantErr bool
+	}{
+		{name: "default", parse: ParseChain, s: "story", want: ChainStory},
+		{name: "mixed case", parse: ParseChain, s: "Avalanche", want: ChainAvalanche},
+		{name: "surrounding space", parse: ParseChain, s: " base ", want: ChainBase},
+		{name: "unknown", parse: ParseChain, s: "devnet", wantErr: true},
+		{name: "empty", parse: ParseChain, s: "", wantErr: true},
+		{name: "custom registry", parse: custom.ParseChain, s: "DEVNET", want: "devnet"},
+		{name: "default chain in custom registry", parse: custom.ParseChain, s: "story", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.s)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedChain) {
+					t.Fatalf("got %q, %v; want %v", got, err, ErrUnsupportedChain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseChain failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Every chain constant names a default chain
+	for _, c := range []Chain{ChainStory, ChainAvalanche, ChainEthereum, ChainPolygon, ChainBase} {
+		if _, ok := DefaultChains.Lookup(c.String()); !ok {
+			t.Errorf("%s is not in DefaultChains", c)
+		}
+	}
+}
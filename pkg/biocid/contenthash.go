@@ -0,0 +1,43 @@
+package biocid
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// ContentHash is the SHA-256 digest of content addressed by a BioCID
+type ContentHash [32]byte
+
+// ParseContentHash parses a 64-character hex content hash, as stored in BioCID.ContentHash
+func ParseContentHash(s string) (ContentHash, error) {
+	var h ContentHash
+	if len(s) != 2*len(h) {
+		return h, fmt.Errorf("%w: expected 64 hex characters, got %d", ErrInvalidContentHash, len(s))
+	}
+
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return ContentHash{}, fmt.Errorf("%w: %w", ErrInvalidContentHash, err)
+	}
+
+	return h, nil
+}
+
+// Hex returns the hash as 64 lowercase hex characters without a 0x prefix
+func (h ContentHash) Hex() string {
+	return hex.EncodeToString(h[:])
+}
+
+// String returns the hash in hex
+func (h ContentHash) String() string {
+	return h.Hex()
+}
+
+// Bytes returns a copy of the hash as a byte slice
+func (h ContentHash) Bytes() []byte {
+	return append([]byte(nil), h[:]...)
+}
+
+// IsZero reports whether the hash is unset
+func (h ContentHash) IsZero() bool {
+	return h == ContentHash{}
+}
@@ -0,0 +1,32 @@
+package biocid
+
+import "errors"
+
+var (
+	// ErrInvalidBioCID is returned when a BioCID string or value is malformed
+	ErrInvalidBioCID = errors.New("invalid biocid")
+
+	// ErrInvalidContentHash is returned when a content hash is not 32 bytes of hex
+	ErrInvalidContentHash = errors.New("invalid content hash")
+
+	// ErrInvalidTokenID is returned when a token ID is not a non-negative decimal integer
+	ErrInvalidTokenID = errors.New("invalid tokenID")
+
+	// ErrInvalidAddress is returned when a collection is not a valid hex address
+	ErrInvalidAddress = errors.New("invalid collection address")
+
+	// ErrInvalidURI is returned when a biofs:// URI or NFT reference is malformed
+	ErrInvalidURI = errors.New("invalid biofs URI")
+
+	// ErrUnsupportedChain is returned when a chain is not in the chain registry
+	ErrUnsupportedChain = errors.New("unsupported chain")
+
+	// ErrUnsignedBioCID is returned when a BioCID that must be signed has no consent signature
+	ErrUnsignedBioCID = errors.New("biocid has no consent signature")
+
+	// ErrInvalidSignature is returned when a consent signature cannot be decoded or recovered
+	ErrInvalidSignature = errors.New("invalid consent signature")
+
+	// ErrNotRegistered is returned by LookupByBase58 for unknown handles
+	ErrNotRegistered = errors.New("biocid not registered")
+)
@@ -0,0 +1,49 @@
+package biocid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorsIs(t *testing.T) {
+	unsupported := validBioCID()
+	unsupported.Chain = "nowhere"
+	badHash := validBioCID()
+	badHash.ContentHash = "xyz"
+	unsigned := validBioCID()
+	unsigned.ConsentSig = ""
+	badCollection := validBioCID()
+	badCollection.Collection = "0x1234"
+
+	tests := []struct {
+		name string
+		err  func() error
+		want []error
+	}{
+		{name: "wrong scheme", err: func() error { _, err := ParseBioCID("ipfs://x"); return err }, want: []error{ErrInvalidBioCID}},
+		{name: "too few parts", err: func() error { _, err := ParseBioCID("biocid://v1/story"); return err }, want: []error{ErrInvalidBioCID}},
+		{name: "strict empty segment", err: func() error { _, err := ParseBioCIDStrict("biocid://v1//a/1/b/c"); return err }, want: []error{ErrInvalidBioCID}},
+		{name: "token ID", err: func() error { _, err := ParseTokenID("-1"); return err }, want: []error{ErrInvalidTokenID}},
+		{name: "unsupported chain", err: unsupported.Validate, want: []error{ErrUnsupportedChain}},
+		{name: "content hash", err: badHash.Validate, want: []error{ErrInvalidBioCID, ErrInvalidContentHash}},
+		{name: "unsigned", err: unsigned.Validate, want: []error{ErrInvalidBioCID, ErrUnsignedBioCID}},
+		{name: "collection", err: badCollection.Validate, want: []error{ErrInvalidAddress}},
+		{name: "batch", err: func() error { return ValidateAll([]*BioCID{validBioCID(), nil, unsigned}) }, want: []error{ErrInvalidBioCID, ErrUnsignedBioCID}},
+		{name: "nft reference", err: func() error { _, err := ParseNFTRef("story"); return err }, want: []error{ErrInvalidURI}},
+		{name: "biofs URI", err: func() error { _, _, err := ParseBiofsURI("biocid://story/x/1"); return err }, want: []error{ErrInvalidURI}},
+		{name: "unregistered", err: func() error { _, err := LookupByBase58("unknown"); return err }, want: []error{ErrNotRegistered}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("%v does not match %v", err, want)
+				}
+			}
+		})
+	}
+}
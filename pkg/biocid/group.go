@@ -0,0 +1,47 @@
+package biocid
+
+// GroupByNFTRef groups BioCIDs by the token they reference, keyed by NFTReference.String()
+// Chain and collection are normalized first, so each key parses back with ParseNFTRef and
+// differently cased references to one token share a group; input order is kept within each group
+func GroupByNFTRef(cids []*BioCID) map[string][]*BioCID {
+	groups := make(map[string][]*BioCID)
+	for _, b := range cids {
+		if b == nil {
+			continue
+		}
+		key := normalizedNFTRef(b).String()
+		groups[key] = append(groups[key], b)
+	}
+	return groups
+}
+
+// GroupByCollection groups BioCIDs by chain and collection, then by token ID
+// Outer keys are "<chain>/<collection>" in normalized form; joined with "/" and a token ID
+// from the inner map they form a key of GroupByNFTRef
+func GroupByCollection(cids []*BioCID) map[string]map[string][]*BioCID {
+	groups := make(map[string]map[string][]*BioCID)
+	for _, b := range cids {
+		if b == nil {
+			continue
+		}
+
+		ref := normalizedNFTRef(b)
+		key := ref.Chain.String() + "/" + ref.Collection
+		tokens, ok := groups[key]
+		if !ok {
+			tokens = make(map[string][]*BioCID)
+			groups[key] = tokens
+		}
+		tokens[ref.TokenID] = append(tokens[ref.TokenID], b)
+	}
+	return groups
+}
+
+// normalizedNFTRef returns b's NFT reference with chain and collection in canonical form
+func normalizedNFTRef(b *BioCID) NFTReference {
+	return NFTReference{
+		Chain:      normalizeChain(b.Chain),
+		Collection: normalizeCollection(b.Collection),
+		TokenID:    b.TokenID,
+	}
+}
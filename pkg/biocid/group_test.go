@@ -0,0 +1,84 @@
+package biocid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tokenCID returns a valid BioCID for tokenID in collection on chain
+func tokenCID(chain Chain, collection, tokenID string) *BioCID {
+	b := validBioCID()
+	b.Chain = chain
+	b.Collection = collection
+	b.TokenID = tokenID
+	return b
+}
+
+func TestGroupByNFTRef(t *testing.T) {
+	other := "0x00000000000000000000000000000000000000C3"
+	a1 := tokenCID("story", testCollection, "1")
+	a1Lower := tokenCID("STORY", strings.ToLower(testCollection), "1")
+	a2 := tokenCID("story", testCollection, "2")
+	b1 := tokenCID("story", other, "1")
+
+	tests := []struct {
+		name string
+		cids []*BioCID
+		want map[string][]*BioCID
+	}{
+		{name: "empty", want: map[string][]*BioCID{}},
+		{name: "nil entries skipped", cids: []*BioCID{nil, a1, nil}, want: map[string][]*BioCID{
+			"story/" + testCollection + "/1": {a1},
+		}},
+		{name: "case-insensitive references", cids: []*BioCID{a1, a2, a1Lower, b1}, want: map[string][]*BioCID{
+			"story/" + testCollection + "/1": {a1, a1Lower},
+			"story/" + testCollection + "/2": {a2},
+			"story/" + other + "/1":          {b1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GroupByNFTRef(tt.cids)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for key := range got {
+				ref, err := ParseNFTRef(key)
+				if err != nil || ref.String() != key {
+					t.Errorf("key %q does not round-trip: %v, %v", key, ref, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupByCollection(t *testing.T) {
+	other := "0x00000000000000000000000000000000000000C3"
+	a1 := tokenCID("story", testCollection, "1")
+	a1Lower := tokenCID("Story", strings.ToLower(testCollection), "1")
+	a2 := tokenCID("story", testCollection, "2")
+	b1 := tokenCID("story", other, "1")
+	c1 := tokenCID("base", testCollection, "1")
+	cids := []*BioCID{a1, nil, b1, a2, c1, a1Lower}
+
+	want := map[string]map[string][]*BioCID{
+		"story/" + testCollection: {"1": {a1, a1Lower}, "2": {a2}},
+		"story/" + other:          {"1": {b1}},
+		"base/" + testCollection:  {"1": {c1}},
+	}
+	got := GroupByCollection(cids)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// Outer and inner keys join into GroupByNFTRef keys
+	byRef := GroupByNFTRef(cids)
+	for collection, tokens := range got {
+		for tokenID, group := range tokens {
+			if !reflect.DeepEqual(byRef[collection+"/"+tokenID], group) {
+				t.Errorf("%s/%s: got %v from GroupByNFTRef, want %v", collection, tokenID, byRef[collection+"/"+tokenID], group)
+			}
+		}
+	}
+}
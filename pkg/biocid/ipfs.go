@@ -0,0 +1,46 @@
+package biocid
+
+import (
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// ToIPFSCIDv1 returns the IPFS CIDv1 (raw codec, sha2-256) of the BioCID's content
+// This is the CID IPFS assigns to the content bytes when added as a single raw block
+func (b *BioCID) ToIPFSCIDv1() (cid.Cid, error) {
+	digest, err := ParseContentHash(b.ContentHash)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %w", ErrInvalidBioCID, err)
+	}
+
+	mh, err := multihash.Encode(digest.Bytes(), multihash.SHA2_256)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("failed to create multihash: %w", err)
+	}
+
+	return cid.NewCidV1(cid.Raw, mh), nil
+}
+
+// FromIPFSCID extracts the sha2-256 content digest from an IPFS CID into a BioCID skeleton
+// Only Version and ContentHash are set; the NFT fields and consent signature must be filled in
+func FromIPFSCID(c cid.Cid) (*BioCID, error) {
+	if !c.Defined() {
+		return nil, fmt.Errorf("cid is undefined")
+	}
+
+	decoded, err := multihash.Decode(c.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode multihash: %w", err)
+	}
+
+	if decoded.Code != multihash.SHA2_256 || len(decoded.Digest) != 32 {
+		return nil, fmt.Errorf("unsupported cid hash: expected sha2-256, got code 0x%x", decoded.Code)
+	}
+
+	return &BioCID{
+		Version:     "v1",
+		ContentHash: ContentHash(decoded.Digest).Hex(),
+	}, nil
+}
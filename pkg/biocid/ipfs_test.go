@@ -0,0 +1,90 @@
+package biocid
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// emptyHash is the SHA256 of no bytes
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestToIPFSCIDv1(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentHash string
+		want        string
+		wantErr     bool
+	}{
+		{name: "known digest", contentHash: emptyHash, want: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+		{name: "0x prefix", contentHash: "0x" + emptyHash, wantErr: true},
+		{name: "short digest", contentHash: emptyHash[:62], wantErr: true},
+		{name: "not hex", contentHash: "xyz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &BioCID{Version: "v1", ContentHash: tt.contentHash}
+			c, err := b.ToIPFSCIDv1()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", c)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToIPFSCIDv1 failed: %v", err)
+			}
+			if c.String() != tt.want || c.Prefix().Codec != cid.Raw {
+				t.Errorf("got %s (codec 0x%x), want raw %s", c, c.Prefix().Codec, tt.want)
+			}
+
+			back, err := FromIPFSCID(c)
+			if err != nil {
+				t.Fatalf("FromIPFSCID failed: %v", err)
+			}
+			if back.ContentHash != emptyHash {
+				t.Errorf("round trip gave %s, want %s", back.ContentHash, emptyHash)
+			}
+		})
+	}
+}
+
+func TestFromIPFSCID(t *testing.T) {
+	mustHash := func(data []byte, code uint64) multihash.Multihash {
+		mh, err := multihash.Sum(data, code, -1)
+		if err != nil {
+			t.Fatalf("multihash.Sum failed: %v", err)
+		}
+		return mh
+	}
+
+	tests := []struct {
+		name    string
+		cid     cid.Cid
+		want    string
+		wantErr bool
+	}{
+		{name: "CIDv0", cid: cid.NewCidV0(mustHash(nil, multihash.SHA2_256)), want: emptyHash},
+		{name: "dag-pb CIDv1", cid: cid.NewCidV1(cid.DagProtobuf, mustHash(nil, multihash.SHA2_256)), want: emptyHash},
+		{name: "sha2-512", cid: cid.NewCidV1(cid.Raw, mustHash(nil, multihash.SHA2_512)), wantErr: true},
+		{name: "undefined", cid: cid.Undef, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromIPFSCID(tt.cid)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromIPFSCID failed: %v", err)
+			}
+			if got.ContentHash != tt.want || got.Version != "v1" || got.TokenID != "" {
+				t.Errorf("got %+v, want a skeleton holding %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package biocid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON encodes the BioCID as its canonical biocid:// string
+// DerivativeInfo and LineageMetadata embed BioCIDs through this encoding
+func (b *BioCID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// UnmarshalJSON decodes a canonical biocid:// string and validates it
+func (b *BioCID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("biocid must be a JSON string: %w", err)
+	}
+
+	parsed, err := ParseBioCID(s)
+	if err != nil {
+		return err
+	}
+
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+
+	*b = *parsed
+	return nil
+}
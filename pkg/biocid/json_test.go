@@ -0,0 +1,82 @@
+package biocid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBioCIDJSON(t *testing.T) {
+	b := validBioCID()
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := `"` + b.String() + `"`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "canonical string", data: string(data)},
+		{name: "not a string", data: `{"chain":"story"}`, wantErr: true},
+		{name: "not a biocid", data: `"https://example.com"`, wantErr: true},
+		{name: "fails validation", data: `"biocid://v2/story/` + testCollection + `/1/` + testHash + `/0x00"`, wantErr: true},
+		{name: "unsigned", data: `"biocid://v1/story/` + testCollection + `/1/` + testHash + `/"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got BioCID
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", &got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if !got.Equal(b) {
+				t.Errorf("got %s, want %s", &got, b)
+			}
+		})
+	}
+}
+
+func TestLineageMetadataJSON(t *testing.T) {
+	self := validBioCID()
+	parent := validBioCID().WithTokenID("2")
+	root := validBioCID().WithTokenID("3")
+	child := validBioCID().WithTokenID("4")
+	for _, b := range []*BioCID{parent, root, child} {
+		b.ConsentSig = self.ConsentSig
+	}
+
+	want := &LineageMetadata{
+		Self:        self,
+		Ancestors:   []*BioCID{parent, root},
+		Descendants: []*BioCID{child},
+		Generation:  2,
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got LineageMetadata
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.Generation != want.Generation || !got.Self.Equal(want.Self) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(got.Ancestors) != 2 || !got.GetParent().Equal(parent) || !got.GetRoot().Equal(root) {
+		t.Errorf("ancestors: got %v", got.Ancestors)
+	}
+	if got.GetDescendantCount() != 1 || !got.Descendants[0].Equal(child) {
+		t.Errorf("descendants: got %v", got.Descendants)
+	}
+}
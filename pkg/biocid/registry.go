@@ -0,0 +1,72 @@
+package biocid
+
+import (
+	"fmt"
+	"sync"
+)
+
+// handleRegistry maps base58 handles (see ToBase58) back to their BioCIDs
+// The base58 form is a one-way fingerprint, so only registered BioCIDs resolve
+var handleRegistry = struct {
+	sync.RWMutex
+	cids map[string]*BioCID
+}{
+	cids: make(map[string]*BioCID),
+}
+
+// NewBioCIDWithDigest creates a new BioCID from a precomputed SHA256 digest
+// Use this when the content hash is already known and the content itself is not available
+func NewBioCIDWithDigest(chain Chain, collection, tokenID string, digest []byte, consentSig string) (*BioCID, error) {
+	if chain == "" || collection == "" || tokenID == "" {
+		return nil, fmt.Errorf("chain, collection, and tokenID are required")
+	}
+
+	if len(digest) != len(ContentHash{}) {
+		return nil, fmt.Errorf("invalid digest length: expected 32, got %d", len(digest))
+	}
+
+	return &BioCID{
+		Version:     "v1",
+		Chain:       normalizeChain(chain),
+		Collection:  normalizeCollection(collection),
+		TokenID:     tokenID,
+		ContentHash: ContentHash(digest).Hex(),
+		ConsentSig:  consentSig,
+	}, nil
+}
+
+// RegisterBioCID records a BioCID so it can later be resolved from its base58 handle
+// Returns the base58 handle under which the BioCID was registered
+func RegisterBioCID(b *BioCID) (string, error) {
+	if b == nil {
+		return "", fmt.Errorf("biocid is required")
+	}
+
+	handle, err := b.ToBase58()
+	if err != nil {
+		return "", err
+	}
+
+	cp := *b
+
+	handleRegistry.Lock()
+	handleRegistry.cids[handle] = &cp
+	handleRegistry.Unlock()
+
+	return handle, nil
+}
+
+// LookupByBase58 resolves a base58 handle to a BioCID
+// Lookup only succeeds for BioCIDs previously registered with RegisterBioCID
+func LookupByBase58(s string) (*BioCID, error) {
+	handleRegistry.RLock()
+	b, ok := handleRegistry.cids[s]
+	handleRegistry.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, s)
+	}
+
+	cp := *b
+	return &cp, nil
+}
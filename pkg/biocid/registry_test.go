@@ -0,0 +1,80 @@
+package biocid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookupByBase58(t *testing.T) {
+	b, err := NewBioCID("story", testCollection, "7", []byte("registered"), "")
+	if err != nil {
+		t.Fatalf("NewBioCID failed: %v", err)
+	}
+	handle, err := RegisterBioCID(b)
+	if err != nil {
+		t.Fatalf("RegisterBioCID failed: %v", err)
+	}
+
+	unregistered, err := NewBioCID("story", testCollection, "8", []byte("unregistered"), "")
+	if err != nil {
+		t.Fatalf("NewBioCID failed: %v", err)
+	}
+	unknown, err := unregistered.ToBase58()
+	if err != nil {
+		t.Fatalf("ToBase58 failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		handle  string
+		want    *BioCID
+		wantErr error
+	}{
+		{name: "registered", handle: handle, want: b},
+		{name: "unregistered", handle: unknown, wantErr: ErrNotRegistered},
+		{name: "garbage", handle: "not-a-handle", wantErr: ErrNotRegistered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LookupByBase58(tt.handle)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupByBase58 failed: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupByBase58ReturnsCopy(t *testing.T) {
+	b, err := NewBioCID("story", testCollection, "9", []byte("copy"), "")
+	if err != nil {
+		t.Fatalf("NewBioCID failed: %v", err)
+	}
+	handle, err := RegisterBioCID(b)
+	if err != nil {
+		t.Fatalf("RegisterBioCID failed: %v", err)
+	}
+
+	b.TokenID = "10"
+	got, err := LookupByBase58(handle)
+	if err != nil {
+		t.Fatalf("LookupByBase58 failed: %v", err)
+	}
+	got.Chain = "ethereum"
+
+	again, err := LookupByBase58(handle)
+	if err != nil {
+		t.Fatalf("LookupByBase58 failed: %v", err)
+	}
+	if again.TokenID != "9" || again.Chain != "story" {
+		t.Errorf("registry entry was mutated: %s", again)
+	}
+}
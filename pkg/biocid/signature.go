@@ -0,0 +1,226 @@
+package biocid
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Consent message formats; older formats remain verifiable
+const (
+	// ConsentFormatV1 is the original plain-text consent message
+	ConsentFormatV1 uint8 = 1
+
+	// ConsentFormatV2 prefixes the message with a printable format-version line
+	ConsentFormatV2 uint8 = 2
+
+	// ConsentFormatCurrent is the format used by SignConsent
+	ConsentFormatCurrent = ConsentFormatV2
+)
+
+// consentV2Prefix is the first line of a ConsentFormatV2 message
+// It is printable so wallets show the message as text rather than as hex
+const consentV2Prefix = "biocid-consent-v2\n"
+
+// consentFormats lists the supported formats, newest first
+var consentFormats = []uint8{ConsentFormatV2, ConsentFormatV1}
+
+// ConsentMessage returns the canonical message signed to produce a ConsentSig in the current format
+// Fields are normalized first, so equivalent BioCIDs produce the same message
+func ConsentMessage(chain Chain, collection, tokenID, contentHash string) string {
+	msg, _ := ConsentMessageFormat(ConsentFormatCurrent, chain, collection, tokenID, contentHash)
+	return string(msg)
+}
+
+// ConsentMessageFormat returns the canonical consent message in the given format
+func ConsentMessageFormat(format uint8, chain Chain, collection, tokenID, contentHash string) ([]byte, error) {
+	text := fmt.Sprintf("BioFS consent\nchain: %s\ncollection: %s\ntokenId: %s\ncontentHash: %s",
+		normalizeChain(chain),
+		normalizeCollection(collection),
+		tokenID,
+		strings.ToLower(contentHash),
+	)
+
+	switch format {
+	case ConsentFormatV1:
+		return []byte(text), nil
+	case ConsentFormatV2:
+		return []byte(consentV2Prefix + text), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported consent format %d", ErrInvalidSignature, format)
+	}
+}
+
+// consentDigest returns the EIP-191 personal-sign digest of the consent message in the given format
+func consentDigest(format uint8, chain Chain, collection, tokenID, contentHash string) ([]byte, error) {
+	msg, err := ConsentMessageFormat(format, chain, collection, tokenID, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	return accounts.TextHash(msg), nil
+}
+
+// VerifyConsentSig reports whether b's ConsentSig was produced by expectedSigner
+// Both 65-byte (r||s||v, v in {0,1,27,28}) and 64-byte EIP-2098 compact signatures are accepted
+func VerifyConsentSig(b *BioCID, expectedSigner common.Address) (bool, error) {
+	format, err := VerifyConsentSigFormat(b, expectedSigner)
+	if err != nil {
+		return false, err
+	}
+	return format != 0, nil
+}
+
+// VerifyConsentSigFormat returns the consent message format under which expectedSigner produced b's ConsentSig
+// If b.ConsentFormat is set only that format is tried, otherwise every supported format is, newest first
+// Returns 0 if the signature does not match expectedSigner under any tried format
+func VerifyConsentSigFormat(b *BioCID, expectedSigner common.Address) (uint8, error) {
+	if b == nil {
+		return 0, fmt.Errorf("biocid is required")
+	}
+
+	formats := consentFormats
+	if b.ConsentFormat != 0 {
+		formats = []uint8{b.ConsentFormat}
+	}
+
+	for _, format := range formats {
+		signer, err := RecoverConsentSignerFormat(b, format)
+		if err != nil {
+			return 0, err
+		}
+		if signer == expectedSigner {
+			return format, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// RecoverConsentSigner returns the address that produced b's ConsentSig
+// The message format is taken from b.ConsentFormat, defaulting to the current format
+func RecoverConsentSigner(b *BioCID) (common.Address, error) {
+	format := b.ConsentFormat
+	if format == 0 {
+		format = ConsentFormatCurrent
+	}
+	return RecoverConsentSignerFormat(b, format)
+}
+
+// RecoverConsentSignerFormat returns the address that produced b's ConsentSig over the given message format
+func RecoverConsentSignerFormat(b *BioCID, format uint8) (common.Address, error) {
+	sig, err := hexutil.Decode(b.ConsentSig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	sig, err = normalizeSignature(sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	digest, err := consentDigest(format, b.Chain, b.Collection, b.TokenID, b.ContentHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// secp256k1N is the order of the secp256k1 curve
+var secp256k1N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+// secp256k1HalfN is the largest s value of a canonical (low-s) signature
+var secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+
+// CanonicalizeSig returns sig as a 65-byte 0x-hex signature in low-s form with v in {27,28}
+// 65-byte signatures with v in {0,1,27,28} and 64-byte EIP-2098 compact signatures are accepted;
+// a high-s signature is replaced by its low-s twin, which recovers the same signer
+func CanonicalizeSig(sig string) (string, error) {
+	raw, err := hexutil.Decode(sig)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	raw, err = normalizeSignature(raw)
+	if err != nil {
+		return "", err
+	}
+
+	r := new(big.Int).SetBytes(raw[:32])
+	sv := new(big.Int).SetBytes(raw[32:64])
+	if r.Sign() == 0 || r.Cmp(secp256k1N) >= 0 || sv.Sign() == 0 || sv.Cmp(secp256k1N) >= 0 {
+		return "", fmt.Errorf("%w: r or s out of range", ErrInvalidSignature)
+	}
+
+	if sv.Cmp(secp256k1HalfN) > 0 {
+		sv.Sub(secp256k1N, sv)
+		sv.FillBytes(raw[32:64])
+		raw[64] ^= 1
+	}
+
+	raw[64] += 27
+	return hexutil.Encode(raw), nil
+}
+
+// normalizeSignature converts a signature to the 65-byte r||s||v form with v in {0,1}
+func normalizeSignature(sig []byte) ([]byte, error) {
+	out := make([]byte, 65)
+
+	switch len(sig) {
+	case 65:
+		copy(out, sig)
+		if out[64] >= 27 {
+			out[64] -= 27
+		}
+	case 64:
+		// EIP-2098: the top bit of s carries the y parity
+		copy(out, sig)
+		out[64] = out[32] >> 7
+		out[32] &= 0x7f
+	default:
+		return nil, fmt.Errorf("%w: expected 64 or 65 bytes, got %d", ErrInvalidSignature, len(sig))
+	}
+
+	if out[64] > 1 {
+		return nil, fmt.Errorf("%w: invalid recovery id %d", ErrInvalidSignature, out[64])
+	}
+
+	return out, nil
+}
+
+// SignConsent signs the canonical consent message in the current format with key
+// Returns the 0x-prefixed 65-byte signature with v in {27,28}, as wallets produce it
+func SignConsent(chain Chain, collection, tokenID, contentHash string, key *ecdsa.PrivateKey) (string, error) {
+	return SignConsentFormat(ConsentFormatCurrent, chain, collection, tokenID, contentHash, key)
+}
+
+// SignConsentFormat signs the consent message in the given format with key
+func SignConsentFormat(format uint8, chain Chain, collection, tokenID, contentHash string, key *ecdsa.PrivateKey) (string, error) {
+	if key == nil {
+		return "", fmt.Errorf("signing key is required")
+	}
+
+	digest, err := consentDigest(format, chain, collection, tokenID, contentHash)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := crypto.Sign(digest, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign consent: %w", err)
+	}
+
+	sig[64] += 27
+	return hexutil.Encode(sig), nil
+}
@@ -0,0 +1,343 @@
+package biocid
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testKey is a fixed signing key so signatures are reproducible
+func testKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("invalid test key: %v", err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// personalSign signs msg the way wallets do for personal_sign, returning r||s||v with v in {0,1}
+func personalSign(t *testing.T, key *ecdsa.PrivateKey, msg []byte) []byte {
+	t.Helper()
+
+	sig, err := crypto.Sign(accounts.TextHash(msg), key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return sig
+}
+
+func TestVerifyConsentSig(t *testing.T) {
+	key, signer := testKey(t)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000B2")
+
+	msg, err := ConsentMessageFormat(ConsentFormatV1, "story", testCollection, "1", testHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := personalSign(t, key, msg)
+
+	withV27 := append([]byte(nil), raw...)
+	withV27[64] += 27
+
+	// EIP-2098 folds the recovery id into the top bit of s
+	compact := append([]byte(nil), raw[:64]...)
+	compact[32] |= raw[64] << 7
+
+	tests := []struct {
+		name    string
+		sig     string
+		modify  func(b *BioCID)
+		signer  common.Address
+		want    bool
+		wantErr error
+	}{
+		{name: "v 0/1", sig: hexutil.Encode(raw), signer: signer, want: true},
+		{name: "v 27/28", sig: hexutil.Encode(withV27), signer: signer, want: true},
+		{name: "compact", sig: hexutil.Encode(compact), signer: signer, want: true},
+		{name: "checksum-insensitive collection", sig: hexutil.Encode(withV27), modify: func(b *BioCID) { b.Collection = "0xc91940118822d247b46d1eba6b7ed2a16f3adc36" }, signer: signer, want: true},
+		{name: "other signer", sig: hexutil.Encode(withV27), signer: other, want: false},
+		{name: "other token", sig: hexutil.Encode(withV27), modify: func(b *BioCID) { b.TokenID = "2" }, signer: signer, want: false},
+		{name: "other content", sig: hexutil.Encode(withV27), modify: func(b *BioCID) { b.ContentHash = testHash[:63] + "9" }, signer: signer, want: false},
+		{name: "not hex", sig: "0xzz", signer: signer, wantErr: ErrInvalidSignature},
+		{name: "wrong length", sig: hexutil.Encode(raw[:40]), signer: signer, wantErr: ErrInvalidSignature},
+		{name: "bad recovery id", sig: hexutil.Encode(append(raw[:64:64], 5)), signer: signer, wantErr: ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.ConsentSig = tt.sig
+			if tt.modify != nil {
+				tt.modify(b)
+			}
+
+			got, err := VerifyConsentSig(b, tt.signer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyConsentSig failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := VerifyConsentSig(nil, signer); err == nil {
+		t.Error("expected an error for a nil BioCID")
+	}
+}
+
+func TestSignConsent(t *testing.T) {
+	key, signer := testKey(t)
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		b    *BioCID
+	}{
+		{name: "valid", b: validBioCID()},
+		{name: "lowercase collection", b: &BioCID{Version: "v1", Chain: "Story", Collection: "0xc91940118822d247b46d1eba6b7ed2a16f3adc36", TokenID: "1", ContentHash: testHash}},
+		{name: "large token ID", b: validBioCID().WithTokenID("115792089237316195423570985008687907853269984665640564039457584007913129639935")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := SignConsent(tt.b.Chain, tt.b.Collection, tt.b.TokenID, tt.b.ContentHash, key)
+			if err != nil {
+				t.Fatalf("SignConsent failed: %v", err)
+			}
+			raw, err := hexutil.Decode(sig)
+			if err != nil || len(raw) != 65 || (raw[64] != 27 && raw[64] != 28) {
+				t.Fatalf("got %s, want a 0x-prefixed 65-byte signature with v in {27,28}", sig)
+			}
+
+			tt.b.ConsentSig = sig
+			if ok, err := VerifyConsentSig(tt.b, signer); err != nil || !ok {
+				t.Errorf("signature not verified: %v, %v", ok, err)
+			}
+			if ok, _ := VerifyConsentSig(tt.b, crypto.PubkeyToAddress(other.PublicKey)); ok {
+				t.Error("signature verified for another wallet")
+			}
+
+			// Deterministic (RFC 6979) signing makes the signature reproducible
+			again, _ := SignConsent(tt.b.Chain, tt.b.Collection, tt.b.TokenID, tt.b.ContentHash, key)
+			if again != sig {
+				t.Errorf("signature changed between runs: %s, %s", sig, again)
+			}
+		})
+	}
+
+	// Chain and collection are normalized, so equivalent spellings sign the same message
+	sig, err := SignConsent("Story", "0xc91940118822d247b46d1eba6b7ed2a16f3adc36", "1", testHash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := validBioCID()
+	b.ConsentSig = sig
+	if ok, err := VerifyConsentSig(b, signer); err != nil || !ok {
+		t.Errorf("normalized signature not verified: %v, %v", ok, err)
+	}
+
+	if _, err := SignConsent("story", testCollection, "1", testHash, nil); err == nil {
+		t.Error("expected an error without a key")
+	}
+}
+
+func TestConsentMessageFormat(t *testing.T) {
+	v1, err := ConsentMessageFormat(ConsentFormatV1, "story", testCollection, "1", testHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := ConsentMessageFormat(ConsentFormatV2, "story", testCollection, "1", testHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "biocid-consent-v2\n" + string(v1); string(v2) != want {
+		t.Errorf("v2 message = %q, want %q", v2, want)
+	}
+	for _, c := range string(v2) {
+		if c != '\n' && !unicode.IsPrint(c) {
+			t.Errorf("v2 message has unprintable %q", c)
+		}
+	}
+	if ConsentMessage("story", testCollection, "1", testHash) != string(v2) {
+		t.Error("ConsentMessage does not use the current format")
+	}
+	if _, err := ConsentMessageFormat(9, "story", testCollection, "1", testHash); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("got %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifyConsentSigFormat(t *testing.T) {
+	key, signer := testKey(t)
+
+	v1msg, err := ConsentMessageFormat(ConsentFormatV1, "story", testCollection, "1", testHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1sig := hexutil.Encode(personalSign(t, key, v1msg))
+	v2sig, err := SignConsent("story", testCollection, "1", testHash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		sig    string
+		pinned uint8 // BioCID.ConsentFormat; zero tries every format
+		want   uint8
+	}{
+		{name: "v1 signature after v2", sig: v1sig, want: ConsentFormatV1},
+		{name: "v2 signature", sig: v2sig, want: ConsentFormatV2},
+		{name: "v1 pinned", sig: v1sig, pinned: ConsentFormatV1, want: ConsentFormatV1},
+		{name: "v1 signature pinned to v2", sig: v1sig, pinned: ConsentFormatV2, want: 0},
+		{name: "v2 signature pinned to v1", sig: v2sig, pinned: ConsentFormatV1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := validBioCID()
+			b.ConsentSig = tt.sig
+			b.ConsentFormat = tt.pinned
+
+			got, err := VerifyConsentSigFormat(b, signer)
+			if err != nil {
+				t.Fatalf("VerifyConsentSigFormat failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("format = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsentFormatInMemoryOnly(t *testing.T) {
+	key, signer := testKey(t)
+	v1msg, err := ConsentMessageFormat(ConsentFormatV1, "story", testCollection, "1", testHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pinned to the wrong format, b does not verify until the pin is dropped
+	b := validBioCID()
+	b.ConsentSig = hexutil.Encode(personalSign(t, key, v1msg))
+	b.ConsentFormat = ConsentFormatV2
+
+	parsed, err := ParseBioCID(b.String())
+	if err != nil {
+		t.Fatalf("ParseBioCID failed: %v", err)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded BioCID
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for name, got := range map[string]*BioCID{"parsed": parsed, "decoded": &decoded} {
+		if got.ConsentFormat != 0 {
+			t.Errorf("%s: format = %d, want 0", name, got.ConsentFormat)
+		}
+		if !got.Equal(b) {
+			t.Errorf("%s: %+v not equal to %+v", name, got, b)
+		}
+
+		format, err := VerifyConsentSigFormat(got, signer)
+		if err != nil {
+			t.Fatalf("%s: VerifyConsentSigFormat failed: %v", name, err)
+		}
+		if format != ConsentFormatV1 {
+			t.Errorf("%s: format = %d, want %d", name, format, ConsentFormatV1)
+		}
+	}
+}
+
+// highS returns the high-s twin of a 65-byte signature with v in {27,28}
+// It recovers the same signer but is not in canonical form
+func highS(t *testing.T, sig string) string {
+	t.Helper()
+
+	raw, err := hexutil.Decode(sig)
+	if err != nil || len(raw) != 65 {
+		t.Fatalf("not a 65-byte signature: %s", sig)
+	}
+	s := new(big.Int).SetBytes(raw[32:64])
+	new(big.Int).Sub(secp256k1N, s).FillBytes(raw[32:64])
+	raw[64] = 27 + ((raw[64] - 27) ^ 1)
+	return hexutil.Encode(raw)
+}
+
+func TestCanonicalizeSig(t *testing.T) {
+	key, signer := testKey(t)
+
+	canonical, err := SignConsent("story", testCollection, "1", testHash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := hexutil.Decode(canonical)
+
+	withV01 := append([]byte(nil), raw...)
+	withV01[64] -= 27
+
+	compact := append([]byte(nil), raw[:64]...)
+	compact[32] |= withV01[64] << 7
+
+	zeroR := append([]byte(nil), raw...)
+	copy(zeroR[:32], make([]byte, 32))
+
+	tests := []struct {
+		name    string
+		sig     string
+		wantErr bool
+	}{
+		{name: "canonical", sig: canonical},
+		{name: "high s", sig: highS(t, canonical)},
+		{name: "v 0/1", sig: hexutil.Encode(withV01)},
+		{name: "compact", sig: hexutil.Encode(compact)},
+		{name: "63 bytes", sig: hexutil.Encode(raw[:63]), wantErr: true},
+		{name: "zero r", sig: hexutil.Encode(zeroR), wantErr: true},
+		{name: "bad recovery id", sig: hexutil.Encode(append(raw[:64:64], 5)), wantErr: true},
+		{name: "not hex", sig: "abc/def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeSig(tt.sig)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidSignature)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalizeSig failed: %v", err)
+			}
+			if got != canonical {
+				t.Errorf("got %s, want %s", got, canonical)
+			}
+
+			b := validBioCID()
+			b.ConsentSig = got
+			if ok, err := VerifyConsentSig(b, signer); err != nil || !ok {
+				t.Errorf("canonical signature not verified: %v, %v", ok, err)
+			}
+		})
+	}
+}
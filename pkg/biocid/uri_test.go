@@ -0,0 +1,160 @@
+package biocid
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseBiofsURL(t *testing.T) {
+	base := "biofs://story/" + testCollection + "/5"
+
+	tests := []struct {
+		name         string
+		uri          string
+		wantPath     string
+		wantQuery    url.Values
+		wantFragment string
+		wantErr      bool
+	}{
+		{name: "path only", uri: base + "/dir/file.vcf", wantPath: "/dir/file.vcf", wantQuery: url.Values{}},
+		{name: "no path", uri: base, wantPath: "/", wantQuery: url.Values{}},
+		{
+			name:         "query and fragment",
+			uri:          base + "/dir/file.vcf?region=chr1:100-200#line3",
+			wantPath:     "/dir/file.vcf",
+			wantQuery:    url.Values{"region": {"chr1:100-200"}},
+			wantFragment: "line3",
+		},
+		{
+			name:      "repeated query keys",
+			uri:       base + "/a.bam?range=0-1023&range=4096-8191",
+			wantPath:  "/a.bam",
+			wantQuery: url.Values{"range": {"0-1023", "4096-8191"}},
+		},
+		{
+			name:         "encoded characters",
+			uri:          base + "/my%20dir/file%23%3F.vcf?note=a%26b#frag%20ment",
+			wantPath:     "/my dir/file#?.vcf",
+			wantQuery:    url.Values{"note": {"a&b"}},
+			wantFragment: "frag ment",
+		},
+		{name: "fragment on token", uri: base + "#top", wantPath: "/", wantQuery: url.Values{}, wantFragment: "top"},
+		{name: "bad query escape", uri: base + "/a?x=%zz", wantErr: true},
+		{name: "missing token", uri: "biofs://story/" + testCollection, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBiofsURL(tt.uri)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBiofsURL failed: %v", err)
+			}
+
+			if got.Chain != "story" || got.Collection != testCollection || got.TokenID != "5" {
+				t.Errorf("got reference %s", got.NFTReference)
+			}
+			if got.Path != tt.wantPath {
+				t.Errorf("path = %q, want %q", got.Path, tt.wantPath)
+			}
+			if !reflect.DeepEqual(got.Query, tt.wantQuery) {
+				t.Errorf("query = %v, want %v", got.Query, tt.wantQuery)
+			}
+			if got.Fragment != tt.wantFragment {
+				t.Errorf("fragment = %q, want %q", got.Fragment, tt.wantFragment)
+			}
+
+			// An unmodified URL encodes back to its input
+			if s := got.String(); s != tt.uri {
+				t.Errorf("String() = %q, want %q", s, tt.uri)
+			}
+
+			// ParseBiofsURI keeps only the reference and path
+			ref, path, err := ParseBiofsURI(tt.uri)
+			if err != nil || ref != got.NFTReference || path != got.Path {
+				t.Errorf("ParseBiofsURI = %s, %q, %v", ref, path, err)
+			}
+		})
+	}
+}
+
+func TestToBiofsURIWithQuery(t *testing.T) {
+	b := validBioCID()
+
+	tests := []struct {
+		name     string
+		path     string
+		query    url.Values
+		fragment string
+		want     string
+	}{
+		{name: "plain", path: "/a.vcf", want: "biofs://story/" + testCollection + "/1/a.vcf"},
+		{name: "relative path", path: "a.vcf", want: "biofs://story/" + testCollection + "/1/a.vcf"},
+		{name: "query", path: "/a.vcf", query: url.Values{"region": {"chr1:100-200"}}, want: "biofs://story/" + testCollection + "/1/a.vcf?region=chr1%3A100-200"},
+		{name: "escaped", path: "/my dir/x#1.vcf", query: url.Values{"q": {"a&b"}}, fragment: "line 3", want: "biofs://story/" + testCollection + "/1/my%20dir/x%231.vcf?q=a%26b#line%203"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.ToBiofsURIWithQuery(tt.path, tt.query, tt.fragment)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+
+			parsed, err := ParseBiofsURL(got)
+			if err != nil {
+				t.Fatalf("ParseBiofsURL failed: %v", err)
+			}
+			wantPath := tt.path
+			if wantPath[0] != '/' {
+				wantPath = "/" + wantPath
+			}
+			if parsed.Path != wantPath || parsed.Fragment != tt.fragment {
+				t.Errorf("round trip gave %q#%q", parsed.Path, parsed.Fragment)
+			}
+			for key, values := range tt.query {
+				if !reflect.DeepEqual(parsed.Query[key], values) {
+					t.Errorf("query %s = %v, want %v", key, parsed.Query[key], values)
+				}
+			}
+		})
+	}
+
+	if got, want := b.ToBiofsURI("/a.vcf"), b.ToBiofsURIWithQuery("/a.vcf", nil, ""); got != want {
+		t.Errorf("ToBiofsURI = %q, want %q", got, want)
+	}
+}
+
+func TestBiofsURLStringModified(t *testing.T) {
+	base := "biofs://story/" + testCollection + "/5"
+
+	tests := []struct {
+		name   string
+		uri    string
+		modify func(u *BiofsURL)
+		want   string
+	}{
+		{name: "new path", uri: base + "/a%20b.vcf", modify: func(u *BiofsURL) { u.Path = "/c d.vcf" }, want: base + "/c%20d.vcf"},
+		{name: "path added", uri: base, modify: func(u *BiofsURL) { u.Path = "x.vcf" }, want: base + "/x.vcf"},
+		{name: "query changed", uri: base + "/a?z=1&a=2", modify: func(u *BiofsURL) { u.Query.Set("a", "3") }, want: base + "/a?a=3&z=1"},
+		{name: "query kept in order", uri: base + "/a?z=1&a=2", modify: func(u *BiofsURL) {}, want: base + "/a?z=1&a=2"},
+		{name: "fragment changed", uri: base + "/a#x%20y", modify: func(u *BiofsURL) { u.Fragment = "z" }, want: base + "/a#z"},
+		{name: "other token", uri: base + "/a", modify: func(u *BiofsURL) { u.TokenID = "6" }, want: "biofs://story/" + testCollection + "/6/a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := ParseBiofsURL(tt.uri)
+			if err != nil {
+				t.Fatalf("ParseBiofsURL failed: %v", err)
+			}
+			tt.modify(u)
+			if got := u.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchGetBioIP fetches several BioIP assets in a single JSON-RPC batch request
+// The result is keyed by TokenID.String(); tokens that do not exist are omitted
+func (m *BioIPManager) BatchGetBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	tokenIDs []*big.Int,
+) (map[string]*BioIPAsset, error) {
+	assets, failed, err := m.batchGetBioIP(ctx, chain, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tokenID := range tokenIDs {
+		if err := failed[tokenID.String()]; err != nil {
+			return nil, err
+		}
+	}
+
+	return assets, nil
+}
+
+// batchGetBioIP is BatchGetBioIP reporting tokens whose call or decoding failed in failed
+// rather than failing the whole batch; err is set only when the batch itself could not be sent
+func (m *BioIPManager) batchGetBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	tokenIDs []*big.Int,
+) (assets map[string]*BioIPAsset, failed map[string]error, err error) {
+	assets = make(map[string]*BioIPAsset, len(tokenIDs))
+	failed = make(map[string]error)
+	if len(tokenIDs) == 0 {
+		return assets, failed, nil
+	}
+
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	calldata := make([][]byte, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		data, err := registryABI.Pack("getBioIP", tokenID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode getBioIP(%s): %w", tokenID, err)
+		}
+		calldata[i] = data
+	}
+
+	callCtx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	var results [][]byte
+	var errs []error
+	start := time.Now()
+	err = m.retryFor("getBioIP batch").Do(callCtx, func() error {
+		results, errs, err = chainrpc.BatchCallContract(callCtx, client, addr, calldata)
+		return err
+	})
+	chainrpc.ObserveCall(m.logger, m.metrics, "getBioIP batch", start, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to batch getBioIP: %w", err)
+	}
+
+	for i := range tokenIDs {
+		if errs[i] != nil {
+			failed[tokenIDs[i].String()] = fmt.Errorf("failed to call getBioIP(%s): %w", tokenIDs[i], errs[i])
+			continue
+		}
+
+		raw, err := decodeBioIP(results[i], m.maxChildren)
+		if err != nil {
+			failed[tokenIDs[i].String()] = fmt.Errorf("token %s: %w", tokenIDs[i], err)
+			continue
+		}
+
+		if raw.Owner == (common.Address{}) && raw.CreatedAt.Sign() == 0 {
+			continue
+		}
+
+		assets[tokenIDs[i].String()] = raw.toAsset()
+	}
+
+	return assets, failed, nil
+}
+
+// prefetchLineage batch-fetches a lineage tree one generation at a time,
+// down to maxDepth generations and at most maxNodes tokens (unlimited if non-positive)
+// Tokens that fail to load are returned in failed, as by fetchLineageConcurrent;
+// a batch that cannot be sent at all fails its whole generation and ends the walk
+func (m *BioIPManager) prefetchLineage(
+	ctx context.Context,
+	chain biocid.Chain,
+	rootTokenID *big.Int,
+	maxDepth int,
+	maxNodes int,
+) (assets map[string]*BioIPAsset, failed map[string]error) {
+	assets = make(map[string]*BioIPAsset)
+	failed = make(map[string]error)
+	seen := map[string]bool{rootTokenID.String(): true}
+	level := []*big.Int{rootTokenID}
+	requested := 0
+
+	for depth := 0; depth <= maxDepth && len(level) > 0 && ctx.Err() == nil; depth++ {
+		if maxNodes > 0 && requested+len(level) > maxNodes {
+			level = level[:maxNodes-requested]
+			if len(level) == 0 {
+				break
+			}
+		}
+		requested += len(level)
+
+		fetched, levelFailed, err := m.batchGetBioIP(ctx, chain, level)
+		if err != nil {
+			for _, tokenID := range level {
+				failed[tokenID.String()] = err
+			}
+			break
+		}
+
+		var next []*big.Int
+		for _, tokenID := range level {
+			if err, ok := levelFailed[tokenID.String()]; ok {
+				failed[tokenID.String()] = err
+				continue
+			}
+
+			asset, ok := fetched[tokenID.String()]
+			if !ok {
+				continue
+			}
+			assets[tokenID.String()] = asset
+
+			for _, childID := range asset.ChildTokenIDs {
+				if !seen[childID.String()] {
+					seen[childID.String()] = true
+					next = append(next, childID)
+				}
+			}
+		}
+		level = next
+	}
+
+	return assets, failed
+}
+
+// fetchLineageConcurrent fetches a lineage tree one generation at a time with up to
+// m.concurrency concurrent GetBioIP calls, down to maxDepth generations and at most
+// maxNodes tokens (unlimited if non-positive)
+// Tokens that fail to load are returned in failed; children are queued in on-chain order
+func (m *BioIPManager) fetchLineageConcurrent(
+	ctx context.Context,
+	chain biocid.Chain,
+	rootTokenID *big.Int,
+	maxDepth int,
+	maxNodes int,
+) (assets map[string]*BioIPAsset, failed map[string]error) {
+	assets = make(map[string]*BioIPAsset)
+	failed = make(map[string]error)
+	seen := map[string]bool{rootTokenID.String(): true}
+	level := []*big.Int{rootTokenID}
+	requested := 0
+
+	for depth := 0; depth <= maxDepth && len(level) > 0 && ctx.Err() == nil; depth++ {
+		if maxNodes > 0 && requested+len(level) > maxNodes {
+			level = level[:maxNodes-requested]
+			if len(level) == 0 {
+				break
+			}
+		}
+		requested += len(level)
+
+		results := make([]*BioIPAsset, len(level))
+		errs := make([]error, len(level))
+		sem := make(chan struct{}, m.concurrency)
+
+		var wg sync.WaitGroup
+		for i, tokenID := range level {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, tokenID *big.Int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				results[i], errs[i] = m.GetBioIP(ctx, chain, tokenID)
+			}(i, tokenID)
+		}
+		wg.Wait()
+
+		var next []*big.Int
+		for i, tokenID := range level {
+			if errs[i] != nil {
+				failed[tokenID.String()] = errs[i]
+				continue
+			}
+			assets[tokenID.String()] = results[i]
+
+			for _, childID := range results[i].ChildTokenIDs {
+				if !seen[childID.String()] {
+					seen[childID.String()] = true
+					next = append(next, childID)
+				}
+			}
+		}
+		level = next
+	}
+
+	return assets, failed
+}
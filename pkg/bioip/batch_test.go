@@ -0,0 +1,66 @@
+package bioip
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+func TestBatchGetBioIP(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0, 2), testAsset(2, 1), testAsset(3, 0))
+	reg.On("getBioIP", big.NewInt(7)).Reverts()
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name    string
+		ids     []int64
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", ids: nil, want: []string{}},
+		{name: "all minted", ids: []int64{1, 2, 3}, want: []string{"1", "2", "3"}},
+		{name: "unminted omitted", ids: []int64{1, 99, 3}, want: []string{"1", "3"}},
+		{name: "duplicates", ids: []int64{2, 2}, want: []string{"2"}},
+		{name: "call fails", ids: []int64{1, 7}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []*big.Int
+			for _, id := range tt.ids {
+				ids = append(ids, big.NewInt(id))
+			}
+
+			assets, err := m.BatchGetBioIP(context.Background(), simChain, ids)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BatchGetBioIP failed: %v", err)
+			}
+
+			got := []string{}
+			for key, asset := range assets {
+				if asset.TokenID.String() != key {
+					t.Errorf("asset %s stored under key %s", asset.TokenID, key)
+				}
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
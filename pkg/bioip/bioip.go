@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/consent"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -18,7 +23,7 @@ type BioIPAsset struct {
 	ConsentState   uint8
 	CreatedAt      *big.Int
 	RevokedAt      *big.Int
-	ContentHash    [32]byte
+	ContentHash    biocid.ContentHash
 	DataType       string
 	DataSize       *big.Int
 	BioCID         [32]byte
@@ -41,280 +46,542 @@ type LicenseToken struct {
 	ConsumedBy    *big.Int
 }
 
+// defaultMaxDepth bounds lineage walks over malformed on-chain graphs
+const defaultMaxDepth = 256
+
+// defaultMaxChildren bounds the ChildTokenIDs array decoded for a single token
+const defaultMaxChildren = 10000
+
 // BioIPManager handles interactions with BioIPRegistry contract
 type BioIPManager struct {
-	client          *ethclient.Client
-	registryAddress common.Address
-	chainRPC        map[string]string
+	mu           sync.Mutex
+	clients      map[biocid.Chain]*ethclient.Client // chain name => cached client
+	backends     map[biocid.Chain]chainrpc.Backend  // chain name => injected backend
+	registries   map[biocid.Chain]common.Address    // chain name => BioIPRegistry address
+	ipRegistries map[biocid.Chain]common.Address    // chain name => Story IPAssetRegistry address
+	startBlocks  map[biocid.Chain]uint64            // chain name => first block of BioIPRegistry history
+	chains       *biocid.ChainRegistry
+	retry        chainrpc.RetryPolicy
+	timeout      time.Duration // Deadline applied to RPC calls without one
+	maxDepth     int           // Maximum number of generations walked in lineage queries
+	maxChildren  int           // Maximum ChildTokenIDs decoded per token
+	batchLineage bool          // Prefetch lineage trees with batched RPC requests
+	waitReceipts bool          // Block mint calls until mined and report minted token IDs
+	reconcile    uint64        // Blocks of LicenseTokenConsumed events checked by GetLicenseToken
+	fees         chainrpc.FeeStrategy
+	chainFees    map[biocid.Chain]chainrpc.FeeStrategy  // chain name => fee strategy override
+	nonces       NonceMode                              // How CreateDerivativeFlow sequences its transactions
+	customTypes  bool                                   // Accept data types without a canonical form
+	limiters     map[biocid.Chain]*chainrpc.RateLimiter // chain name => request rate limiter
+	failovers    map[biocid.Chain]*chainrpc.Failover    // chain name => configured RPC endpoints
+	concurrency  int                                    // Concurrent GetBioIP calls per lineage generation
+	resolver     *resolverCache                         // Optional BioCIDToBioIP result cache
+	urlErrs      map[biocid.Chain]error                 // chain name => rejected WithRPCEndpoints URLs, reported on use
+	logger       chainrpc.Logger
+	metrics      chainrpc.MetricsSink
 }
 
 // NewBioIPManager creates a new BioIP manager
-func NewBioIPManager() *BioIPManager {
-	return &BioIPManager{
-		chainRPC: map[string]string{
-			"story":     "https://rpc.story.foundation",
-			"avalanche": "https://api.avax.network/ext/bc/C/rpc",
-			"ethereum":  "https://eth.llamarpc.com",
-		},
+func NewBioIPManager(opts ...Option) *BioIPManager {
+	m := &BioIPManager{
+		clients:      make(map[biocid.Chain]*ethclient.Client),
+		backends:     make(map[biocid.Chain]chainrpc.Backend),
+		registries:   make(map[biocid.Chain]common.Address),
+		ipRegistries: make(map[biocid.Chain]common.Address),
+		startBlocks:  make(map[biocid.Chain]uint64),
+		chainFees:    make(map[biocid.Chain]chainrpc.FeeStrategy),
+		limiters:     make(map[biocid.Chain]*chainrpc.RateLimiter),
+		failovers:    make(map[biocid.Chain]*chainrpc.Failover),
+		urlErrs:      make(map[biocid.Chain]error),
+		chains:       biocid.DefaultChains,
+		retry:        chainrpc.DefaultRetryPolicy(),
+		timeout:      chainrpc.DefaultTimeout,
+		maxDepth:     defaultMaxDepth,
+		maxChildren:  defaultMaxChildren,
+		logger:       chainrpc.NopLogger(),
+		metrics:      chainrpc.NopMetrics(),
 	}
+
+	for chain, addr := range defaultIPAssetRegistries {
+		m.ipRegistries[chain] = addr
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 // MintRootBioIP creates a new root BioIP with license terms
+// The minted token ID is only populated when the manager waits for receipts
 func (m *BioIPManager) MintRootBioIP(
 	ctx context.Context,
-	chain string,
-	contentHash [32]byte,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
 	dataType string,
 	dataSize uint64,
 	bioCID [32]byte,
 	ipAssetID common.Address,
 	licenseTermsID *big.Int,
 	signer *bind.TransactOpts,
-) (*big.Int, error) {
-	client, err := m.getClient(chain)
+) (*MintResult, error) {
+	dataType, err := m.normalizeDataType(dataType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return nil, err
 	}
 
-	// TODO: Load contract ABI and call mintRootBioIP
-	// For now, return placeholder
-
-	_ = client
-	_ = signer
-
-	return big.NewInt(1), nil
+	return m.submit(ctx, chain, signer, m.waitReceipts, "BioIPMinted", "mintRootBioIP",
+		[32]byte(contentHash),
+		dataType,
+		new(big.Int).SetUint64(dataSize),
+		bioCID,
+		ipAssetID,
+		licenseTermsID,
+	)
 }
 
 // MintLicenseTokens mints license tokens for creating derivatives
 // MUST be called BEFORE creating the derivative
 func (m *BioIPManager) MintLicenseTokens(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	parentTokenID *big.Int,
 	receiver common.Address,
 	amount *big.Int,
 	signer *bind.TransactOpts,
-) ([]*big.Int, error) {
-	client, err := m.getClient(chain)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
-	}
-
-	// TODO: Load contract ABI and call mintLicenseTokens
-
-	_ = client
-	_ = parentTokenID
-	_ = receiver
-	_ = amount
-	_ = signer
+) (*MintResult, error) {
+	return m.mintLicenseTokens(ctx, chain, parentTokenID, receiver, amount, signer, m.waitReceipts)
+}
 
-	// Return placeholder license token IDs
-	return []*big.Int{big.NewInt(1)}, nil
+func (m *BioIPManager) mintLicenseTokens(
+	ctx context.Context,
+	chain biocid.Chain,
+	parentTokenID *big.Int,
+	receiver common.Address,
+	amount *big.Int,
+	signer *bind.TransactOpts,
+	wait bool,
+) (*MintResult, error) {
+	return m.submit(ctx, chain, signer, wait, "LicenseTokenMinted", "mintLicenseTokens",
+		parentTokenID,
+		receiver,
+		amount,
+	)
 }
 
 // MintDerivativeBioIP creates a child BioIP WITHOUT license terms
 func (m *BioIPManager) MintDerivativeBioIP(
 	ctx context.Context,
-	chain string,
-	contentHash [32]byte,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
 	dataType string,
 	dataSize uint64,
 	bioCID [32]byte,
 	ipAssetID common.Address,
 	signer *bind.TransactOpts,
-) (*big.Int, error) {
-	client, err := m.getClient(chain)
+) (*MintResult, error) {
+	return m.mintDerivativeBioIP(ctx, chain, contentHash, dataType, dataSize, bioCID, ipAssetID, signer, m.waitReceipts)
+}
+
+func (m *BioIPManager) mintDerivativeBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
+	dataType string,
+	dataSize uint64,
+	bioCID [32]byte,
+	ipAssetID common.Address,
+	signer *bind.TransactOpts,
+	wait bool,
+) (*MintResult, error) {
+	dataType, err := m.normalizeDataType(dataType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return nil, err
 	}
 
-	// TODO: Load contract ABI and call mintDerivativeBioIP
-
-	_ = client
-	_ = signer
-
-	return big.NewInt(2), nil
+	return m.submit(ctx, chain, signer, wait, "BioIPMinted", "mintDerivativeBioIP",
+		[32]byte(contentHash),
+		dataType,
+		new(big.Int).SetUint64(dataSize),
+		bioCID,
+		ipAssetID,
+	)
 }
 
 // RegisterDerivative links child as derivative using license token
-// This consumes the license token (one-time use)
+// This consumes the license token (one-time use), so the token is checked
+// to be unconsumed and minted by parentTokenID before the transaction is sent
 func (m *BioIPManager) RegisterDerivative(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
+	childTokenID *big.Int,
+	parentTokenID *big.Int,
+	licenseTokenID *big.Int,
+	signer *bind.TransactOpts,
+) error {
+	return m.registerDerivative(ctx, chain, childTokenID, parentTokenID, licenseTokenID, signer, m.waitReceipts)
+}
+
+func (m *BioIPManager) registerDerivative(
+	ctx context.Context,
+	chain biocid.Chain,
 	childTokenID *big.Int,
+	parentTokenID *big.Int,
 	licenseTokenID *big.Int,
 	signer *bind.TransactOpts,
+	wait bool,
 ) error {
-	client, err := m.getClient(chain)
+	if signer == nil {
+		return fmt.Errorf("signer is required")
+	}
+
+	license, err := m.GetLicenseToken(ctx, chain, licenseTokenID)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return fmt.Errorf("failed to load license token %s: %w", licenseTokenID, err)
 	}
 
-	// TODO: Load contract ABI and call registerDerivative
+	if license.Consumed {
+		return fmt.Errorf("%w: license token %s was used by token %s", ErrLicenseConsumed, licenseTokenID, license.ConsumedBy)
+	}
 
-	_ = client
-	_ = childTokenID
-	_ = licenseTokenID
-	_ = signer
+	if license.ParentTokenID == nil || license.ParentTokenID.Cmp(parentTokenID) != 0 {
+		return fmt.Errorf("%w: license token %s was minted by %s, not %s", ErrLicenseParentMismatch, licenseTokenID, license.ParentTokenID, parentTokenID)
+	}
 
-	return nil
+	if license.MintedFor != signer.From {
+		return fmt.Errorf("%w: license token %s was minted for %s", ErrLicenseNotForSigner, licenseTokenID, license.MintedFor.Hex())
+	}
+
+	_, err = m.submit(ctx, chain, signer, wait, "", "registerDerivative", childTokenID, licenseTokenID)
+	return err
 }
 
 // GetLineage returns all ancestors of a BioIP
+// Ancestors are ordered from the immediate parent up to the root
 func (m *BioIPManager) GetLineage(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	tokenID *big.Int,
 ) ([]*big.Int, error) {
-	client, err := m.getClient(chain)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
-	}
+	ancestors := []*big.Int{}
+	visited := map[string]bool{tokenID.String(): true}
 
-	// TODO: Load contract ABI and call getLineage
+	current := tokenID
+	for {
+		bioip, err := m.GetBioIP(ctx, chain, current)
+		if err != nil {
+			return nil, err
+		}
 
-	_ = client
-	_ = tokenID
+		parent := bioip.ParentTokenID
+		if parent == nil || parent.Sign() == 0 {
+			return ancestors, nil
+		}
+
+		if visited[parent.String()] {
+			return nil, fmt.Errorf("%w: token %s revisited from %s", ErrLineageCycle, parent, current)
+		}
+
+		if len(ancestors) >= m.maxDepth {
+			return nil, fmt.Errorf("%w: more than %d ancestors", ErrLineageTooDeep, m.maxDepth)
+		}
 
-	return []*big.Int{}, nil
+		visited[parent.String()] = true
+		ancestors = append(ancestors, parent)
+		current = parent
+	}
 }
 
-// GetDescendants returns all descendants (children, grandchildren, etc)
-func (m *BioIPManager) GetDescendants(
+// VerifyGeneration checks that every node from tokenID up to its root has a Generation
+// equal to its distance from the root
+// An inconsistency returns false and an error wrapping ErrGenerationMismatch that names
+// the first mislabeled token, walking down from the root
+func (m *BioIPManager) VerifyGeneration(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	tokenID *big.Int,
-) ([]*big.Int, error) {
-	client, err := m.getClient(chain)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
-	}
+) (bool, error) {
+	path := []*BioIPAsset{}
+	visited := map[string]bool{tokenID.String(): true}
+
+	current := tokenID
+	for {
+		bioip, err := m.GetBioIP(ctx, chain, current)
+		if err != nil {
+			return false, err
+		}
+		path = append(path, bioip)
+
+		parent := bioip.ParentTokenID
+		if parent == nil || parent.Sign() == 0 {
+			break
+		}
 
-	// TODO: Load contract ABI and call getDescendants
+		if visited[parent.String()] {
+			return false, fmt.Errorf("%w: token %s revisited from %s", ErrLineageCycle, parent, current)
+		}
 
-	_ = client
-	_ = tokenID
+		if len(path) > m.maxDepth {
+			return false, fmt.Errorf("%w: more than %d ancestors", ErrLineageTooDeep, m.maxDepth)
+		}
+
+		visited[parent.String()] = true
+		current = parent
+	}
 
-	return []*big.Int{}, nil
+	for i := len(path) - 1; i >= 0; i-- {
+		expected := int64(len(path) - 1 - i)
+		if path[i].Generation == nil || path[i].Generation.Cmp(big.NewInt(expected)) != 0 {
+			return false, fmt.Errorf("%w: token %s has generation %s, expected %d",
+				ErrGenerationMismatch, path[i].TokenID, path[i].Generation, expected)
+		}
+	}
+
+	return true, nil
 }
 
-// GetAvailableLicenseTokens returns unused license tokens for a parent
-func (m *BioIPManager) GetAvailableLicenseTokens(
+// GetDescendants returns all descendants (children, grandchildren, etc)
+// Descendants are collected breadth-first, ordered by generation, and de-duplicated
+// maxGenerations limits the depth walked; zero or negative uses the manager's max depth
+func (m *BioIPManager) GetDescendants(
 	ctx context.Context,
-	chain string,
-	parentTokenID *big.Int,
+	chain biocid.Chain,
+	tokenID *big.Int,
+	maxGenerations int,
 ) ([]*big.Int, error) {
-	client, err := m.getClient(chain)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	if maxGenerations <= 0 || maxGenerations > m.maxDepth {
+		maxGenerations = m.maxDepth
 	}
 
-	// TODO: Load contract ABI and call getAvailableLicenseTokens
+	descendants := []*big.Int{}
+	visited := map[string]bool{tokenID.String(): true}
+	level := []*big.Int{tokenID}
+
+	for generation := 0; generation < maxGenerations && len(level) > 0; generation++ {
+		var next []*big.Int
+		for _, id := range level {
+			bioip, err := m.GetBioIP(ctx, chain, id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, childID := range bioip.ChildTokenIDs {
+				if visited[childID.String()] {
+					continue
+				}
+				visited[childID.String()] = true
+				next = append(next, childID)
+			}
+		}
 
-	_ = client
-	_ = parentTokenID
+		descendants = append(descendants, next...)
+		level = next
+	}
 
-	return []*big.Int{}, nil
+	return descendants, nil
 }
 
-// CheckConsent verifies if a wallet has active consent
+// CheckConsent verifies if a wallet has active consent, as reported by the registry's checkConsent
 func (m *BioIPManager) CheckConsent(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	tokenID *big.Int,
 	wallet common.Address,
 ) (bool, error) {
-	client, err := m.getClient(chain)
+	contract, err := m.registry(ctx, chain)
 	if err != nil {
-		return false, fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return false, err
 	}
 
-	// TODO: Load contract ABI and call checkConsent
-
-	_ = client
-	_ = tokenID
-	_ = wallet
+	out, err := m.call(ctx, contract, "checkConsent", tokenID, wallet)
+	if err != nil {
+		return false, fmt.Errorf("failed to check consent for token %s: %w", tokenID, err)
+	}
 
-	return true, nil
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
 }
 
 // GetBioIP retrieves BioIP asset data
 func (m *BioIPManager) GetBioIP(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
+	tokenID *big.Int,
+) (*BioIPAsset, error) {
+	return m.getBioIPAt(ctx, chain, tokenID, nil)
+}
+
+// GetBioIPAt retrieves BioIP asset data as it stood at blockNumber
+// Reading at past blocks needs an archive node; blocks beyond the chain head are rejected
+func (m *BioIPManager) GetBioIPAt(
+	ctx context.Context,
+	chain biocid.Chain,
 	tokenID *big.Int,
+	blockNumber *big.Int,
 ) (*BioIPAsset, error) {
-	client, err := m.getClient(chain)
+	if blockNumber == nil || blockNumber.Sign() < 0 {
+		return nil, fmt.Errorf("invalid block number: %v", blockNumber)
+	}
+
+	client, err := m.getClient(ctx, chain)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
 	}
 
-	// TODO: Load contract ABI and call getBioIP
+	var head uint64
+	err = m.withRetry(ctx, "eth_blockNumber", func(ctx context.Context) error {
+		head, err = chainrpc.BlockNumber(ctx, client)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	if !blockNumber.IsUint64() || blockNumber.Uint64() > head {
+		return nil, fmt.Errorf("block %s is beyond the chain head %d", blockNumber, head)
+	}
+
+	return m.getBioIPAt(ctx, chain, tokenID, blockNumber)
+}
+
+// getBioIPAt reads a token at blockNumber, or at the latest block if it is nil
+func (m *BioIPManager) getBioIPAt(ctx context.Context, chain biocid.Chain, tokenID *big.Int, blockNumber *big.Int) (*BioIPAsset, error) {
+	data, err := m.callRawAt(ctx, chain, blockNumber, "getBioIP", tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := decodeBioIP(data, m.maxChildren)
+	if err != nil {
+		return nil, fmt.Errorf("token %s: %w", tokenID, err)
+	}
 
-	_ = client
-	_ = tokenID
+	// Unminted tokens come back as a zero-valued struct
+	if raw.Owner == (common.Address{}) && raw.CreatedAt.Sign() == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, tokenID)
+	}
 
-	return &BioIPAsset{
-		TokenID:    tokenID,
-		Generation: big.NewInt(0),
-	}, nil
+	return raw.toAsset(), nil
 }
 
 // GetLicenseToken retrieves license token data
 func (m *BioIPManager) GetLicenseToken(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	licenseTokenID *big.Int,
 ) (*LicenseToken, error) {
-	client, err := m.getClient(chain)
+	contract, err := m.registry(ctx, chain)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return nil, err
+	}
+
+	out, err := m.call(ctx, contract, "getLicenseToken", licenseTokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *abi.ConvertType(out[0], new(registryLicenseToken)).(*registryLicenseToken)
+
+	// Unminted license tokens come back as a zero-valued struct
+	if raw.TokenId.Sign() == 0 {
+		return nil, fmt.Errorf("%w: license token %s", ErrTokenNotFound, licenseTokenID)
+	}
+
+	token := raw.toLicenseToken()
+	if m.reconcile == 0 || token.Consumed {
+		return token, nil
+	}
+
+	if err := m.reconcileLicenseConsumption(ctx, chain, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// PreflightDerivative checks that a parent can accept derivatives before any transaction is sent
+// The parent must exist, carry PIL license terms, and have active consent
+func (m *BioIPManager) PreflightDerivative(
+	ctx context.Context,
+	chain biocid.Chain,
+	parentTokenID *big.Int,
+) error {
+	parent, err := m.GetBioIP(ctx, chain, parentTokenID)
+	if err != nil {
+		return fmt.Errorf("failed to load parent %s: %w", parentTokenID, err)
 	}
 
-	// TODO: Load contract ABI and call getLicenseToken
+	if !parent.HasLicense {
+		return fmt.Errorf("%w: parent %s has no license terms", ErrParentNotLicensed, parentTokenID)
+	}
 
-	_ = client
-	_ = licenseTokenID
+	if parent.LicenseTermsID == nil || parent.LicenseTermsID.Sign() == 0 {
+		return fmt.Errorf("%w: parent %s has no license terms ID", ErrParentNotLicensed, parentTokenID)
+	}
+
+	switch consent.ConsentState(parent.ConsentState) {
+	case consent.ConsentActive:
+	case consent.ConsentRevoked:
+		return fmt.Errorf("%w: parent %s: %w", ErrParentConsentInactive, parentTokenID, consent.ErrConsentRevoked)
+	case consent.ConsentDeleted:
+		return fmt.Errorf("%w: parent %s: %w", ErrParentConsentInactive, parentTokenID, consent.ErrConsentDeleted)
+	default:
+		return fmt.Errorf("%w: parent %s consent state is %d", ErrParentConsentInactive, parentTokenID, parent.ConsentState)
+	}
 
-	return &LicenseToken{
-		TokenID: licenseTokenID,
-	}, nil
+	return nil
 }
 
 // CreateDerivativeFlow executes the complete derivative creation flow
 // This is the recommended way to create derivatives
 func (m *BioIPManager) CreateDerivativeFlow(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
 	parentTokenID *big.Int,
-	childContentHash [32]byte,
+	childContentHash biocid.ContentHash,
 	childDataType string,
 	childDataSize uint64,
 	childBioCID [32]byte,
 	childIPAssetID common.Address,
 	signer *bind.TransactOpts,
 ) (*big.Int, error) {
+	// Step 0: Verify the parent accepts derivatives before spending gas
+	if err := m.PreflightDerivative(ctx, chain, parentTokenID); err != nil {
+		return nil, fmt.Errorf("derivative preflight failed: %w", err)
+	}
+
+	// With NonceLocal the two mints are sent back to back under consecutive nonces,
+	// otherwise each transaction is mined before the next is sent
+	nextSigner := func() *bind.TransactOpts { return signer }
+	wait := true
+	if m.nonces == NonceLocal {
+		nonce, err := m.pendingNonce(ctx, chain, signer)
+		if err != nil {
+			return nil, err
+		}
+		wait = false
+		nextSigner = func() *bind.TransactOpts {
+			opts := *signer
+			opts.Nonce = new(big.Int).SetUint64(nonce)
+			nonce++
+			return &opts
+		}
+	}
+
 	// Step 1: Mint license token from parent
-	licenseTokens, err := m.MintLicenseTokens(
+	licenseTokens, err := m.mintLicenseTokens(
 		ctx,
 		chain,
 		parentTokenID,
 		signer.From,
 		big.NewInt(1),
-		signer,
+		nextSigner(),
+		wait,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to mint license token: %w", err)
 	}
 
-	if len(licenseTokens) == 0 {
-		return nil, fmt.Errorf("no license tokens minted")
-	}
-
-	licenseTokenID := licenseTokens[0]
-
 	// Step 2: Mint child WITHOUT license terms
-	childTokenID, err := m.MintDerivativeBioIP(
+	child, err := m.mintDerivativeBioIP(
 		ctx,
 		chain,
 		childContentHash,
@@ -322,19 +589,35 @@ func (m *BioIPManager) CreateDerivativeFlow(
 		childDataSize,
 		childBioCID,
 		childIPAssetID,
-		signer,
+		nextSigner(),
+		wait,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to mint derivative: %w", err)
 	}
 
+	// Registration needs the minted IDs, so both mints must be mined first
+	if !wait {
+		if licenseTokens, err = m.await(ctx, chain, licenseTokens, "LicenseTokenMinted"); err != nil {
+			return nil, fmt.Errorf("failed to mint license token: %w", err)
+		}
+		if child, err = m.await(ctx, chain, child, "BioIPMinted"); err != nil {
+			return nil, fmt.Errorf("failed to mint derivative: %w", err)
+		}
+	}
+
+	licenseTokenID := licenseTokens.TokenID()
+	childTokenID := child.TokenID()
+
 	// Step 3: Register as derivative using license token
-	err = m.RegisterDerivative(
+	err = m.registerDerivative(
 		ctx,
 		chain,
 		childTokenID,
+		parentTokenID,
 		licenseTokenID,
-		signer,
+		nextSigner(),
+		true,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register derivative: %w", err)
@@ -343,25 +626,142 @@ func (m *BioIPManager) CreateDerivativeFlow(
 	return childTokenID, nil
 }
 
-// GetLineageTree returns a structured tree of the full lineage
+// LineageNode is a BioIP and its derivatives in a lineage tree
 type LineageNode struct {
-	TokenID    *big.Int
-	BioCID     [32]byte
-	DataType   string
-	Generation *big.Int
-	Children   []*LineageNode
+	TokenID       *big.Int
+	BioCID        [32]byte
+	DataType      string
+	Generation    *big.Int
+	Children      []*LineageNode
+	CycleDetected bool            // Set on the root when a token listed as its own descendant was skipped
+	Truncated     bool            // Set on the root when the depth or node limit left children out
+	Errors        []*LineageError // Set on the root for derivatives that failed to load and were left out
+
+	// AlreadyExpanded marks a stub for a token reached by more than one path
+	// The stub has no children; they are listed under the token's first occurrence
+	AlreadyExpanded bool
+}
+
+// LineageError records a derivative that could not be loaded into a lineage tree
+type LineageError struct {
+	TokenID       *big.Int
+	ParentTokenID *big.Int
+	Err           error
 }
 
+// Error implements the error interface
+func (e *LineageError) Error() string {
+	return fmt.Sprintf("token %s (child of %s): %v", e.TokenID, e.ParentTokenID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *LineageError) Unwrap() error {
+	return e.Err
+}
+
+// GetLineageTree returns a structured tree of the full lineage, bounded by WithMaxDepth
+// See GetLineageTreeLimited for how partial trees are reported
 func (m *BioIPManager) GetLineageTree(
 	ctx context.Context,
-	chain string,
+	chain biocid.Chain,
+	rootTokenID *big.Int,
+) (*LineageNode, error) {
+	return m.GetLineageTreeLimited(ctx, chain, rootTokenID, m.maxDepth, 0)
+}
+
+// GetLineageTreeLimited returns the lineage tree below rootTokenID, descending at most maxDepth
+// generations and fetching at most maxNodes tokens (unlimited if non-positive)
+// Only a failure to load the root is returned as an error; the returned root reports partial trees:
+// Truncated when a limit left children out, Errors for derivatives that failed to load,
+// and CycleDetected when a token listed as a child of its own descendant was skipped
+// A token reached again by another path (a diamond) appears as an AlreadyExpanded stub
+func (m *BioIPManager) GetLineageTreeLimited(
+	ctx context.Context,
+	chain biocid.Chain,
 	rootTokenID *big.Int,
+	maxDepth int,
+	maxNodes int,
 ) (*LineageNode, error) {
-	bioip, err := m.GetBioIP(ctx, chain, rootTokenID)
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+
+	w := &lineageWalk{
+		chain:      chain,
+		maxDepth:   maxDepth,
+		maxNodes:   maxNodes,
+		visited:    make(map[string]bool),
+		ancestors:  make(map[string]bool),
+		expanded:   make(map[string]*LineageNode),
+		prefetched: map[string]*BioIPAsset{},
+	}
+
+	// Prefetched assets are consulted before falling back to GetBioIP
+	switch {
+	case m.batchLineage:
+		w.prefetched, w.failed = m.prefetchLineage(ctx, chain, rootTokenID, maxDepth, maxNodes)
+	case m.concurrency > 1:
+		w.prefetched, w.failed = m.fetchLineageConcurrent(ctx, chain, rootTokenID, maxDepth, maxNodes)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	node, err := m.buildLineageNode(ctx, w, rootTokenID, 0)
 	if err != nil {
 		return nil, err
 	}
 
+	node.CycleDetected = w.cycle
+	node.Truncated = w.truncated
+	node.Errors = w.errs
+	return node, nil
+}
+
+// lineageWalk is the state shared across one GetLineageTreeLimited traversal
+type lineageWalk struct {
+	chain      biocid.Chain
+	maxDepth   int
+	maxNodes   int                     // Non-positive for no limit
+	fetched    int                     // Tokens loaded so far, including failed ones
+	visited    map[string]bool         // Tokens loaded or attempted
+	ancestors  map[string]bool         // Tokens on the path from the root to the current node
+	expanded   map[string]*LineageNode // Loaded tokens by ID, for already-expanded stubs
+	prefetched map[string]*BioIPAsset
+	failed     map[string]error // Tokens that already failed to prefetch
+	cycle      bool
+	truncated  bool
+	errs       []*LineageError
+}
+
+// buildLineageNode fetches a token and recursively its children
+// Children that fail to load are recorded in w.errs and left out
+func (m *BioIPManager) buildLineageNode(
+	ctx context.Context,
+	w *lineageWalk,
+	tokenID *big.Int,
+	depth int,
+) (*LineageNode, error) {
+	key := tokenID.String()
+	w.visited[key] = true
+	w.fetched++
+
+	w.ancestors[key] = true
+	defer delete(w.ancestors, key)
+
+	if err, ok := w.failed[tokenID.String()]; ok {
+		return nil, err
+	}
+
+	bioip, ok := w.prefetched[tokenID.String()]
+	if !ok {
+		var err error
+		bioip, err = m.GetBioIP(ctx, w.chain, tokenID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	node := &LineageNode{
 		TokenID:    bioip.TokenID,
 		BioCID:     bioip.BioCID,
@@ -369,12 +769,31 @@ func (m *BioIPManager) GetLineageTree(
 		Generation: bioip.Generation,
 		Children:   make([]*LineageNode, 0),
 	}
+	w.expanded[key] = node
 
-	// Recursively get children
 	for _, childID := range bioip.ChildTokenIDs {
-		childNode, err := m.GetLineageTree(ctx, chain, childID)
+		if w.ancestors[childID.String()] {
+			w.cycle = true
+			continue
+		}
+
+		if w.visited[childID.String()] {
+			// Tokens that failed to load were already recorded in w.errs
+			if first, ok := w.expanded[childID.String()]; ok {
+				node.Children = append(node.Children, first.expandedStub())
+			}
+			continue
+		}
+
+		if depth >= w.maxDepth || (w.maxNodes > 0 && w.fetched >= w.maxNodes) {
+			w.truncated = true
+			break
+		}
+
+		childNode, err := m.buildLineageNode(ctx, w, childID, depth+1)
 		if err != nil {
-			continue // Skip failed children
+			w.errs = append(w.errs, &LineageError{TokenID: childID, ParentTokenID: tokenID, Err: err})
+			continue
 		}
 		node.Children = append(node.Children, childNode)
 	}
@@ -382,35 +801,209 @@ func (m *BioIPManager) GetLineageTree(
 	return node, nil
 }
 
-// getClient returns an ethclient for the specified chain
-func (m *BioIPManager) getClient(chain string) (*ethclient.Client, error) {
-	rpcURL, ok := m.chainRPC[chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", chain)
+// expandedStub returns a childless copy of n marking a repeat occurrence of its token
+func (n *LineageNode) expandedStub() *LineageNode {
+	return &LineageNode{
+		TokenID:         n.TokenID,
+		BioCID:          n.BioCID,
+		DataType:        n.DataType,
+		Generation:      n.Generation,
+		Children:        make([]*LineageNode, 0),
+		AlreadyExpanded: true,
+	}
+}
+
+// getClient returns the backend for the specified chain
+// Backends injected with WithBackend take precedence over dialing the chain's RPC URL
+// Dialing is abandoned when ctx is done, and happens without holding m.mu so a slow
+// endpoint does not hold up calls to other chains
+func (m *BioIPManager) getClient(ctx context.Context, chain biocid.Chain) (chainrpc.Backend, error) {
+	backend, rpcURL, err := m.cachedClient(chain)
+	if backend != nil || err != nil {
+		return backend, err
 	}
 
-	if m.client != nil {
-		return m.client, nil
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", chainrpc.ErrRPCConnect, err)
 	}
 
-	client, err := ethclient.Dial(rpcURL)
+	client, err := ethclient.DialContext(ctx, rpcURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		return nil, fmt.Errorf("%w: %w", chainrpc.ErrRPCConnect, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A concurrent call may have dialed the chain first; keep its client
+	limiter := m.limiters[chain]
+	if existing, ok := m.clients[chain]; ok {
+		client.Close()
+		return chainrpc.RateLimit(existing, limiter), nil
+	}
+	m.clients[chain] = client
+	return chainrpc.RateLimit(client, limiter), nil
+}
+
+// cachedClient returns chain's backend if one is injected, configured or already dialed,
+// otherwise the RPC URL to dial
+func (m *BioIPManager) cachedClient(chain biocid.Chain) (chainrpc.Backend, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limiter := m.limiters[chain]
+
+	if backend, ok := m.backends[chain]; ok {
+		return chainrpc.RateLimit(backend, limiter), "", nil
+	}
+
+	if err, ok := m.urlErrs[chain]; ok {
+		return nil, "", err
+	}
+
+	if failover, ok := m.failovers[chain]; ok {
+		return chainrpc.RateLimit(failover, limiter), "", nil
 	}
 
-	m.client = client
-	return client, nil
+	if client, ok := m.clients[chain]; ok {
+		return chainrpc.RateLimit(client, limiter), "", nil
+	}
+
+	info, ok := m.chains.Lookup(chain.String())
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", biocid.ErrUnsupportedChain, chain)
+	}
+	return nil, info.RPCURL, nil
+}
+
+// Close closes all dialed RPC clients
+// Backends injected with WithBackend are owned by the caller and left open
+func (m *BioIPManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for chain, client := range m.clients {
+		client.Close()
+		delete(m.clients, chain)
+	}
+
+	// Failovers stay configured and redial on next use
+	for _, failover := range m.failovers {
+		failover.Close()
+	}
 }
 
 // BioCIDToBioIP converts a BioCID to its corresponding BioIP on-chain
+// The BioCID's Collection must be the chain's BioIPRegistry, which also holds the token's consent,
+// otherwise ErrCollectionMismatch is returned before anything is fetched
+// When the BioCID carries a content hash, the asset's must match it, otherwise
+// ErrContentHashMismatch is returned; references without one, such as resolved biofs:// URIs,
+// skip the check
 func (m *BioIPManager) BioCIDToBioIP(
 	ctx context.Context,
-	biocid *biocid.BioCID,
+	b *biocid.BioCID,
 ) (*BioIPAsset, error) {
-	nftRef := biocid.NFTRef()
+	nftRef := b.NFTRef()
+
+	var contentHash biocid.ContentHash
+	if b.ContentHash != "" {
+		hash, err := biocid.ParseContentHash(b.ContentHash)
+		if err != nil {
+			return nil, err
+		}
+		contentHash = hash
+	}
+
+	tokenIDBig, err := nftRef.TokenIDBig()
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := m.registryAddress(nftRef.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := common.HexToAddress(nftRef.Collection)
+	if collection != registry {
+		return nil, fmt.Errorf("%w: biocid collection %s is not the %s registry %s",
+			ErrCollectionMismatch, collection.Hex(), nftRef.Chain, registry.Hex())
+	}
+
+	asset, err := m.resolveBioIP(ctx, nftRef.Chain, tokenIDBig)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.ContentHash != "" && asset.ContentHash != contentHash {
+		return nil, fmt.Errorf("%w: biocid has %s, token %s has %s",
+			ErrContentHashMismatch, contentHash, tokenIDBig, asset.ContentHash)
+	}
 
-	tokenIDBig := new(big.Int)
-	tokenIDBig.SetString(nftRef.TokenID, 10)
+	return asset, nil
+}
+
+// resolveBioIP returns a token's asset for BioCIDToBioIP, going through the resolver cache when enabled
+func (m *BioIPManager) resolveBioIP(ctx context.Context, chain biocid.Chain, tokenID *big.Int) (*BioIPAsset, error) {
+	if m.resolver == nil {
+		return m.GetBioIP(ctx, chain, tokenID)
+	}
+
+	key := resolverCacheKey(chain, tokenID)
+	cached, fresh, ok := m.resolver.get(key)
+	if ok && fresh {
+		m.logger.Debug("resolver cache hit", "chain", chain, "token", tokenID.String())
+		return cached, nil
+	}
+
+	latest, err := m.GetBioIP(ctx, chain, tokenID)
+	if err != nil {
+		return nil, err
+	}
 
-	return m.GetBioIP(ctx, nftRef.Chain, tokenIDBig)
-}
\ No newline at end of file
+	if ok {
+		m.logger.Debug("resolver cache refresh", "chain", chain, "token", tokenID.String())
+		return m.resolver.refresh(key, latest), nil
+	}
+
+	m.logger.Debug("resolver cache miss", "chain", chain, "token", tokenID.String())
+	m.resolver.put(key, latest)
+	return latest, nil
+}
+
+// HealthCheck checks every configured chain's RPC concurrently
+// Each chain in the registry or injected with WithBackend maps to nil when it is reachable
+// and reports the registered chain ID, or to the error encountered
+func (m *BioIPManager) HealthCheck(ctx context.Context) map[string]error {
+	return chainrpc.HealthCheckAll(ctx, m.configuredChains(), func(ctx context.Context, chain string) error {
+		client, err := m.getClient(ctx, biocid.Chain(chain))
+		if err != nil {
+			return err
+		}
+
+		var expected *big.Int
+		if info, ok := m.chains.Lookup(chain); ok {
+			expected = info.ChainID
+		}
+
+		ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+		defer cancel()
+
+		return chainrpc.CheckHealth(ctx, client, expected)
+	})
+}
+
+// configuredChains returns the registry's chains plus any chains with injected backends
+func (m *BioIPManager) configuredChains() []string {
+	chains := m.chains.Names()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for chain := range m.backends {
+		if _, ok := m.chains.Lookup(chain.String()); !ok {
+			chains = append(chains, chain.String())
+		}
+	}
+	return chains
+}
@@ -0,0 +1,659 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetBioIPSimulated(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0, 2, 3))
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name    string
+		chain   biocid.Chain
+		tokenID int64
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "minted token", chain: simChain, tokenID: 1},
+		{name: "unminted token", chain: simChain, tokenID: 99, wantErr: true, wantIs: ErrTokenNotFound},
+		{name: "chain without a registry", chain: "avalanche", tokenID: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := m.GetBioIP(context.Background(), tt.chain, big.NewInt(tt.tokenID))
+			if tt.wantErr {
+				if err == nil || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Fatalf("got %v, want error %v", err, tt.wantIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBioIP failed: %v", err)
+			}
+
+			want := testAsset(tt.tokenID, 0, 2, 3)
+			if !asset.Equal(want.toAsset()) {
+				t.Errorf("got %s, want %s", asset, want.toAsset())
+			}
+		})
+	}
+}
+
+func TestGetBioIPParsedChain(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	// A name parsed from user input reaches the backend injected for the same chain
+	chain, err := biocid.ParseChain(" STORY ")
+	if err != nil {
+		t.Fatalf("ParseChain failed: %v", err)
+	}
+	if chain.String() != simChain {
+		t.Fatalf("got %q, want %q", chain, simChain)
+	}
+	if _, err := m.GetBioIP(context.Background(), chain, big.NewInt(1)); err != nil {
+		t.Fatalf("GetBioIP failed: %v", err)
+	}
+}
+
+func TestWithBackendSkipsDialing(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+
+	// An unroutable RPC URL proves the injected backend is used instead of dialing
+	chains := biocid.NewChainRegistry()
+	chains.Register(simChain, "http://127.0.0.1:1", simchain.ChainID)
+
+	m, _ := newSimManager(t, []*simchain.Contract{reg}, WithChainRegistry(chains))
+	if _, err := m.GetBioIP(context.Background(), simChain, big.NewInt(1)); err != nil {
+		t.Fatalf("GetBioIP failed: %v", err)
+	}
+}
+
+func BenchmarkGetBioIP(b *testing.B) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0, 2, 3))
+	m, _ := newSimManager(b, []*simchain.Contract{reg})
+
+	ctx := context.Background()
+	tokenID := big.NewInt(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.GetBioIP(ctx, simChain, tokenID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGetBioIPDecodesFields(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(5, 2, 8, 9))
+	reg.On("getBioIP", big.NewInt(6)).ReturnsRaw([]byte{0x01, 0x02})
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	ctx := context.Background()
+	asset, err := m.GetBioIP(ctx, simChain, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("GetBioIP failed: %v", err)
+	}
+
+	checks := []struct {
+		field string
+		ok    bool
+	}{
+		{"Owner", asset.Owner == common.HexToAddress("0x00000000000000000000000000000000000000A1")},
+		{"TokenID", asset.TokenID.Int64() == 5},
+		{"ConsentState", asset.ConsentState == 1},
+		{"ContentHash", asset.ContentHash == biocid.ContentHash{5}},
+		{"DataType", asset.DataType == "vcf"},
+		{"DataSize", asset.DataSize.Int64() == 1024},
+		{"BioCID", asset.BioCID == [32]byte{0xb1, 5}},
+		{"IPAssetID", asset.IPAssetID == common.BigToAddress(big.NewInt(0x1005))},
+		{"HasLicense", asset.HasLicense},
+		{"ParentTokenID", asset.ParentTokenID.Int64() == 2},
+		{"ChildTokenIDs", len(asset.ChildTokenIDs) == 2 && asset.ChildTokenIDs[0].Int64() == 8 && asset.ChildTokenIDs[1].Int64() == 9},
+		{"Generation", asset.Generation.Int64() == 1},
+	}
+	for _, c := range checks {
+		if !c.ok {
+			t.Errorf("%s decoded incorrectly: %s", c.field, asset)
+		}
+	}
+
+	if _, err := m.GetBioIP(ctx, simChain, big.NewInt(6)); err == nil || errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("malformed result: got %v, want a decode error", err)
+	}
+}
+
+func TestGetClientCachesPerChain(t *testing.T) {
+	chains := biocid.NewChainRegistry()
+	chains.Register("alpha", "http://127.0.0.1:1", big.NewInt(1))
+	chains.Register("beta", "http://127.0.0.1:2", big.NewInt(2))
+	m := NewBioIPManager(WithChainRegistry(chains))
+	ctx := context.Background()
+
+	alpha, err := m.getClient(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("getClient(alpha) failed: %v", err)
+	}
+	beta, err := m.getClient(ctx, "beta")
+	if err != nil {
+		t.Fatalf("getClient(beta) failed: %v", err)
+	}
+	if alpha == beta {
+		t.Error("chains share a client")
+	}
+	if again, _ := m.getClient(ctx, "alpha"); again != alpha {
+		t.Error("second getClient(alpha) dialed a new client")
+	}
+	if _, err := m.getClient(ctx, "gamma"); !errors.Is(err, biocid.ErrUnsupportedChain) {
+		t.Errorf("unknown chain: got %v, want %v", err, biocid.ErrUnsupportedChain)
+	}
+
+	m.Close()
+	if len(m.clients) != 0 {
+		t.Errorf("Close left %d clients cached", len(m.clients))
+	}
+	if again, _ := m.getClient(ctx, "alpha"); again == alpha {
+		t.Error("getClient after Close reused a closed client")
+	}
+	m.Close()
+}
+
+// stallingBackend blocks every contract call until its context is done
+type stallingBackend struct {
+	chainrpc.Backend
+}
+
+func (stallingBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	chain := simchain.New(t)
+	m := NewBioIPManager(
+		WithBackend(simChain, stallingBackend{chain.Backend}),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+		WithDefaultTimeout(20*time.Millisecond),
+	)
+	defer m.Close()
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name:    "default deadline",
+			ctx:     func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "caller deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "caller cancellation",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(5*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			_, err := m.GetBioIP(ctx, simChain, big.NewInt(1))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("call took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestGetClientErrors(t *testing.T) {
+	chains := biocid.NewChainRegistry()
+	chains.Register("broken", "unsupported-scheme://rpc", big.NewInt(1))
+	m := NewBioIPManager(WithChainRegistry(chains), WithRegistry("broken", simRegistry), WithRegistry("nowhere", simRegistry))
+	t.Cleanup(m.Close)
+
+	tests := []struct {
+		name  string
+		chain biocid.Chain
+		want  error
+	}{
+		{name: "unsupported chain", chain: "nowhere", want: biocid.ErrUnsupportedChain},
+		{name: "dial fails", chain: "broken", want: chainrpc.ErrRPCConnect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.GetBioIP(context.Background(), tt.chain, big.NewInt(1))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSink counts metrics by name
+type fakeSink struct {
+	mu         sync.Mutex
+	counters   map[string]int
+	histograms map[string]int
+	labels     map[string]map[string]string
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{
+		counters:   make(map[string]int),
+		histograms: make(map[string]int),
+		labels:     make(map[string]map[string]string),
+	}
+}
+
+func (s *fakeSink) IncCounter(name string, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[name]++
+	s.labels[name] = labels
+}
+
+func (s *fakeSink) ObserveHistogram(name string, value float64, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.histograms[name]++
+	s.labels[name] = labels
+}
+
+func TestGetBioIPMetrics(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+	reg.On("getBioIP", big.NewInt(2)).Reverts()
+
+	tests := []struct {
+		name           string
+		tokenID        int64
+		wantCounters   map[string]int
+		wantHistograms map[string]int
+	}{
+		{
+			name:           "success",
+			tokenID:        1,
+			wantCounters:   map[string]int{chainrpc.MetricRPCCalls: 1},
+			wantHistograms: map[string]int{chainrpc.MetricRPCDuration: 1},
+		},
+		{
+			name:           "rpc failure",
+			tokenID:        2,
+			wantCounters:   map[string]int{chainrpc.MetricRPCCalls: 1, chainrpc.MetricRPCErrors: 1},
+			wantHistograms: map[string]int{chainrpc.MetricRPCDuration: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := newFakeSink()
+			m, _ := newSimManager(t, []*simchain.Contract{reg}, WithMetrics(sink))
+			m.GetBioIP(context.Background(), simChain, big.NewInt(tt.tokenID))
+
+			if !reflect.DeepEqual(sink.counters, tt.wantCounters) {
+				t.Errorf("counters = %v, want %v", sink.counters, tt.wantCounters)
+			}
+			if !reflect.DeepEqual(sink.histograms, tt.wantHistograms) {
+				t.Errorf("histograms = %v, want %v", sink.histograms, tt.wantHistograms)
+			}
+			if method := sink.labels[chainrpc.MetricRPCCalls]["method"]; method != "getBioIP" {
+				t.Errorf("method label = %q, want getBioIP", method)
+			}
+		})
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	chain := simchain.New(t)
+	chains := biocid.NewChainRegistry()
+	chains.Register("sim", "http://unused", simchain.ChainID)
+	chains.Register("forked", "http://unused", big.NewInt(1514))
+	chains.Register("broken", "unsupported-scheme://rpc", big.NewInt(1))
+
+	m := NewBioIPManager(
+		WithChainRegistry(chains),
+		WithBackend("sim", &chainIDBackend{Backend: chain.Miner(), id: simchain.ChainID}),
+		WithBackend("forked", &chainIDBackend{Backend: chain.Miner(), id: big.NewInt(1)}),
+	)
+	t.Cleanup(m.Close)
+
+	got := m.HealthCheck(context.Background())
+	want := map[string]error{
+		"sim":    nil,
+		"forked": chainrpc.ErrChainIDMismatch,
+		"broken": chainrpc.ErrRPCConnect,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want a result per chain", got)
+	}
+	for chain, wantErr := range want {
+		if !errors.Is(got[chain], wantErr) {
+			t.Errorf("%s: got %v, want %v", chain, got[chain], wantErr)
+		}
+	}
+}
+
+func TestBioCIDToBioIP(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	asset := testAsset(1, 0)
+	hash := biocid.ContentHash(asset.ContentHash).Hex()
+
+	tests := []struct {
+		name       string
+		collection string
+		hash       string
+		wantErr    error
+	}{
+		{name: "registry", collection: simRegistry.Hex(), hash: hash},
+		{name: "lowercase registry", collection: strings.ToLower(simRegistry.Hex()), hash: hash},
+		{name: "IP account", collection: asset.IpAssetId.Hex(), hash: hash, wantErr: ErrCollectionMismatch},
+		{name: "foreign collection", collection: "0x00000000000000000000000000000000000000C3", hash: hash, wantErr: ErrCollectionMismatch},
+		{name: "other content", collection: simRegistry.Hex(), hash: biocid.ContentHash{0xFF}.Hex(), wantErr: ErrContentHashMismatch},
+		{name: "no content hash", collection: simRegistry.Hex()},
+		{name: "malformed content hash", collection: simRegistry.Hex(), hash: "abc", wantErr: biocid.ErrInvalidContentHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &biocid.BioCID{Chain: simChain, Collection: tt.collection, TokenID: "1", ContentHash: tt.hash}
+			got, err := m.BioCIDToBioIP(context.Background(), b)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BioCIDToBioIP failed: %v", err)
+			}
+			if !got.Equal(asset.toAsset()) {
+				t.Errorf("got %s, want %s", got, asset.toAsset())
+			}
+		})
+	}
+}
+
+func TestWithRPCEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		chain   biocid.Chain
+		wantErr bool
+	}{
+		{name: "valid", opts: []Option{WithRPCEndpoints("story", "https://a.example.org", "https://b.example.org")}, chain: "story"},
+		{name: "no URLs", opts: []Option{WithRPCEndpoints("story")}, chain: "story", wantErr: true},
+		{name: "unsupported scheme", opts: []Option{WithRPCEndpoints("story", "https://a.example.org", "ftp://b.example.org")}, chain: "story", wantErr: true},
+		{
+			name:    "invalid chain beside a valid one",
+			opts:    []Option{WithRPCEndpoints("story", "https://a.example.org"), WithRPCEndpoints("sepolia", "")},
+			chain:   "sepolia",
+			wantErr: true,
+		},
+		{
+			name:  "valid chain beside an invalid one",
+			opts:  []Option{WithRPCEndpoints("story", "https://a.example.org"), WithRPCEndpoints("sepolia", "")},
+			chain: "story",
+		},
+		{
+			name:  "invalid URLs replaced",
+			opts:  []Option{WithRPCEndpoints("story", ""), WithRPCEndpoints("story", "https://a.example.org")},
+			chain: "story",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewBioIPManager(tt.opts...)
+			defer m.Close()
+
+			// Invalid URLs surface on first use rather than from the constructor
+			_, err := m.getClient(context.Background(), tt.chain)
+			if tt.wantErr {
+				if !errors.Is(err, chainrpc.ErrInvalidEndpoint) {
+					t.Fatalf("got %v, want %v", err, chainrpc.ErrInvalidEndpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getClient failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckConsent(t *testing.T) {
+	owner := testAsset(1, 0).Owner
+	stranger := common.HexToAddress("0x00000000000000000000000000000000000000B2")
+
+	reg := newSimRegistry()
+	reg.On("checkConsent").Returns(false)
+	reg.On("checkConsent", big.NewInt(1), owner).Returns(true)
+	reg.On("checkConsent", big.NewInt(3), owner).Returns(false)
+	reg.On("checkConsent", big.NewInt(500), owner).Reverts()
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name    string
+		chain   biocid.Chain
+		tokenID int64
+		wallet  common.Address
+		want    bool
+		wantErr bool
+	}{
+		{name: "owner", chain: simChain, tokenID: 1, wallet: owner, want: true},
+		{name: "stranger", chain: simChain, tokenID: 1, wallet: stranger},
+		{name: "other token", chain: simChain, tokenID: 2, wallet: owner},
+		{name: "non-consented token", chain: simChain, tokenID: 3, wallet: owner},
+		{name: "reverted", chain: simChain, tokenID: 500, wallet: owner, wantErr: true},
+		{name: "unconfigured chain", chain: "nowhere", tokenID: 1, wallet: owner, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.CheckConsent(context.Background(), tt.chain, big.NewInt(tt.tokenID), tt.wallet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckConsent failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetClientContext(t *testing.T) {
+	// A websocket endpoint that accepts connections but never completes the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	chains := biocid.NewChainRegistry()
+	chains.Register("lazy", "http://127.0.0.1:1", big.NewInt(1))
+	chains.Register("stalled", "ws://"+ln.Addr().String(), big.NewInt(2))
+	m := NewBioIPManager(WithChainRegistry(chains))
+	defer m.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		chain   biocid.Chain
+		wantErr bool
+		wantIs  error // Checked when set; a dial cut short by ctx may report a plain i/o timeout
+	}{
+		{name: "canceled before dialing", ctx: canceled, chain: "lazy", wantErr: true, wantIs: context.Canceled},
+		{name: "deadline while dialing", ctx: short, chain: "stalled", wantErr: true},
+		{name: "live context", ctx: context.Background(), chain: "lazy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := m.getClient(tt.ctx, tt.chain)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("getClient failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, chainrpc.ErrRPCConnect) || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+				t.Fatalf("got %v, want %v wrapping %v", err, chainrpc.ErrRPCConnect, tt.wantIs)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("gave up after %s, past the context's deadline", elapsed)
+			}
+		})
+	}
+
+	// A stalled dial does not hold up calls to other chains
+	stalled, cancelStalled := context.WithTimeout(context.Background(), time.Second)
+	defer cancelStalled()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.getClient(stalled, "stalled")
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := m.getClient(context.Background(), "lazy"); err != nil {
+		t.Fatalf("getClient failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %s on another chain's dial", elapsed)
+	}
+	<-done
+}
+
+// blockRecorder forwards calls to the simulated chain's latest state, recording the block each asked for
+// The simulated backend cannot read past state, so the recorded block is what tests check
+type blockRecorder struct {
+	chainrpc.Backend
+	mu     sync.Mutex
+	blocks []*big.Int
+}
+
+func (b *blockRecorder) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	b.blocks = append(b.blocks, blockNumber)
+	b.mu.Unlock()
+	return b.Backend.CallContract(ctx, call, nil)
+}
+
+func TestGetBioIPAt(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+	chain := simchain.New(t, reg)
+	backend := &blockRecorder{Backend: chain.Miner()}
+	m := NewBioIPManager(
+		WithBackend(simChain, backend),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	)
+	defer m.Close()
+
+	head, err := chainrpc.BlockNumber(context.Background(), chain.Backend)
+	if err != nil {
+		t.Fatalf("BlockNumber failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		block   *big.Int
+		tokenID int64
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "head", block: new(big.Int).SetUint64(head), tokenID: 1},
+		{name: "genesis", block: new(big.Int), tokenID: 1},
+		{name: "unminted", block: new(big.Int).SetUint64(head), tokenID: 9, wantErr: true, wantIs: ErrTokenNotFound},
+		{name: "beyond the head", block: new(big.Int).SetUint64(head + 1), tokenID: 1, wantErr: true},
+		{name: "negative", block: big.NewInt(-1), tokenID: 1, wantErr: true},
+		{name: "no block", tokenID: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend.blocks = nil
+			got, err := m.GetBioIPAt(context.Background(), simChain, big.NewInt(tt.tokenID), tt.block)
+			if tt.wantErr {
+				if err == nil || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Fatalf("got %v, want an error matching %v", err, tt.wantIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBioIPAt failed: %v", err)
+			}
+			if got.TokenID.Int64() != tt.tokenID {
+				t.Errorf("got token %s, want %d", got.TokenID, tt.tokenID)
+			}
+			if len(backend.blocks) != 1 || backend.blocks[0] == nil || backend.blocks[0].Cmp(tt.block) != 0 {
+				t.Errorf("called at blocks %v, want [%s]", backend.blocks, tt.block)
+			}
+		})
+	}
+
+	// GetBioIP still reads the latest state
+	backend.blocks = nil
+	if _, err := m.GetBioIP(context.Background(), simChain, big.NewInt(1)); err != nil {
+		t.Fatalf("GetBioIP failed: %v", err)
+	}
+	if len(backend.blocks) != 1 || backend.blocks[0] != nil {
+		t.Errorf("GetBioIP called at blocks %v, want the latest", backend.blocks)
+	}
+}
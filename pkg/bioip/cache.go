@@ -0,0 +1,137 @@
+package bioip
+
+import (
+	"container/list"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+)
+
+// resolverCache is a bounded LRU cache of BioCIDToBioIP lookups
+// Immutable fields (content, data type, lineage) and volatile fields (owner, consent,
+// license, children) expire separately; a stale volatile half is refreshed on its own
+type resolverCache struct {
+	mu           sync.Mutex
+	immutableTTL time.Duration
+	volatileTTL  time.Duration
+	max          int
+	order        *list.List               // Front is most recently used
+	entries      map[string]*list.Element // token key => element holding *resolverEntry
+	now          func() time.Time
+}
+
+// resolverEntry is a cached asset with the expiry of each half
+type resolverEntry struct {
+	key              string
+	asset            *BioIPAsset
+	immutableExpires time.Time
+	volatileExpires  time.Time
+}
+
+// newResolverCache creates a cache; a non-positive maxEntries leaves it unbounded
+func newResolverCache(immutableTTL, volatileTTL time.Duration, maxEntries int) *resolverCache {
+	return &resolverCache{
+		immutableTTL: immutableTTL,
+		volatileTTL:  volatileTTL,
+		max:          maxEntries,
+		order:        list.New(),
+		entries:      make(map[string]*list.Element),
+		now:          time.Now,
+	}
+}
+
+// resolverCacheKey identifies a registry token
+func resolverCacheKey(chain biocid.Chain, tokenID *big.Int) string {
+	return strings.ToLower(chain.String()) + "/" + tokenID.String()
+}
+
+// get returns a copy of the cached asset if its immutable fields have not expired
+// fresh reports whether the volatile fields are still within their TTL
+func (rc *resolverCache) get(key string) (asset *BioIPAsset, fresh bool, ok bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+
+	entry := elem.Value.(*resolverEntry)
+	now := rc.now()
+	if !now.Before(entry.immutableExpires) {
+		rc.remove(elem)
+		return nil, false, false
+	}
+
+	rc.order.MoveToFront(elem)
+	return copyAsset(entry.asset), now.Before(entry.volatileExpires), true
+}
+
+// put stores a freshly fetched asset, evicting the least recently used entry when full
+func (rc *resolverCache) put(key string, asset *BioIPAsset) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := rc.now()
+	if elem, ok := rc.entries[key]; ok {
+		entry := elem.Value.(*resolverEntry)
+		entry.asset = copyAsset(asset)
+		entry.immutableExpires = now.Add(rc.immutableTTL)
+		entry.volatileExpires = now.Add(rc.volatileTTL)
+		rc.order.MoveToFront(elem)
+		return
+	}
+
+	entry := &resolverEntry{
+		key:              key,
+		asset:            copyAsset(asset),
+		immutableExpires: now.Add(rc.immutableTTL),
+		volatileExpires:  now.Add(rc.volatileTTL),
+	}
+	rc.entries[key] = rc.order.PushFront(entry)
+
+	for rc.max > 0 && rc.order.Len() > rc.max {
+		rc.remove(rc.order.Back())
+	}
+}
+
+// refresh replaces the volatile fields of a cached asset with those of latest
+// and returns the merged copy; the cached immutable fields are kept as they are
+// Returns latest unchanged if the entry was evicted in the meantime
+func (rc *resolverCache) refresh(key string, latest *BioIPAsset) *BioIPAsset {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return latest
+	}
+
+	entry := elem.Value.(*resolverEntry)
+	entry.asset.Owner = latest.Owner
+	entry.asset.ConsentState = latest.ConsentState
+	entry.asset.RevokedAt = latest.RevokedAt
+	entry.asset.HasLicense = latest.HasLicense
+	entry.asset.LicenseTokenID = latest.LicenseTokenID
+	entry.asset.ChildTokenIDs = append([]*big.Int(nil), latest.ChildTokenIDs...)
+	entry.volatileExpires = rc.now().Add(rc.volatileTTL)
+
+	return copyAsset(entry.asset)
+}
+
+// remove deletes an element; the caller holds rc.mu
+func (rc *resolverCache) remove(elem *list.Element) {
+	entry := elem.Value.(*resolverEntry)
+	rc.order.Remove(elem)
+	delete(rc.entries, entry.key)
+}
+
+// copyAsset returns a copy of asset that does not share its ChildTokenIDs slice
+func copyAsset(asset *BioIPAsset) *BioIPAsset {
+	out := *asset
+	out.ChildTokenIDs = append([]*big.Int(nil), asset.ChildTokenIDs...)
+	return &out
+}
@@ -0,0 +1,220 @@
+package bioip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestResolverCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	raw := testAsset(1, 0)
+	asset := raw.toAsset()
+	key := resolverCacheKey(simChain, asset.TokenID)
+
+	tests := []struct {
+		name      string
+		run       func(rc *resolverCache) (fresh, ok bool)
+		wantFresh bool
+		wantOK    bool
+	}{
+		{name: "miss", run: func(rc *resolverCache) (bool, bool) { return false, false }},
+		{
+			name: "fresh hit",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+			wantFresh: true,
+			wantOK:    true,
+		},
+		{
+			name: "chain case ignored",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(resolverCacheKey("Story", asset.TokenID), asset)
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+			wantFresh: true,
+			wantOK:    true,
+		},
+		{
+			name: "volatile fields stale",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				rc.now = func() time.Time { return now.Add(2 * time.Second) }
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+			wantOK: true,
+		},
+		{
+			name: "immutable fields expired",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				rc.now = func() time.Time { return now.Add(time.Minute) }
+				_, fresh, ok := rc.get(key)
+				return fresh, ok || rc.order.Len() != 0
+			},
+		},
+		{
+			name: "least recently used evicted",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				rc.put(resolverCacheKey(simChain, big.NewInt(2)), asset)
+				rc.put(resolverCacheKey(simChain, big.NewInt(3)), asset)
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+		},
+		{
+			name: "recent use survives eviction",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				rc.put(resolverCacheKey(simChain, big.NewInt(2)), asset)
+				rc.get(key)
+				rc.put(resolverCacheKey(simChain, big.NewInt(3)), asset)
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+			wantFresh: true,
+			wantOK:    true,
+		},
+		{
+			name: "refresh renews volatile fields",
+			run: func(rc *resolverCache) (bool, bool) {
+				rc.put(key, asset)
+				rc.now = func() time.Time { return now.Add(2 * time.Second) }
+				rc.refresh(key, asset)
+				_, fresh, ok := rc.get(key)
+				return fresh, ok
+			},
+			wantFresh: true,
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := newResolverCache(time.Minute, time.Second, 2)
+			rc.now = func() time.Time { return now }
+			fresh, ok := tt.run(rc)
+			if fresh != tt.wantFresh || ok != tt.wantOK {
+				t.Errorf("got fresh %v, ok %v; want fresh %v, ok %v", fresh, ok, tt.wantFresh, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolverCacheRefresh(t *testing.T) {
+	latest := testAsset(1, 0)
+	cached := latest.toAsset()
+	latest.Owner = common.HexToAddress("0x00000000000000000000000000000000000000A2")
+	latest.ConsentState = 2
+	latest.RevokedAt = big.NewInt(1700000100)
+	latest.HasLicense = false
+	latest.ChildTokenIds = []*big.Int{big.NewInt(4)}
+	latest.ContentHash = [32]byte{0xFF}
+	latest.DataType = "bam"
+	latest.Generation = big.NewInt(3)
+
+	rc := newResolverCache(time.Minute, time.Second, 0)
+	key := resolverCacheKey(simChain, cached.TokenID)
+	rc.put(key, cached)
+
+	got := rc.refresh(key, latest.toAsset())
+
+	want := *cached
+	want.Owner = latest.Owner
+	want.ConsentState = latest.ConsentState
+	want.RevokedAt = latest.RevokedAt
+	want.HasLicense = latest.HasLicense
+	want.ChildTokenIDs = latest.ChildTokenIds
+	if !got.Equal(&want) {
+		t.Errorf("got %s, want %s", got, &want)
+	}
+
+	// The returned copy does not alias the cache
+	got.ChildTokenIDs[0] = big.NewInt(9)
+	if again, _, _ := rc.get(key); again.ChildTokenIDs[0].Int64() != 4 {
+		t.Errorf("cached children changed to %v", again.ChildTokenIDs)
+	}
+
+	if evicted := rc.refresh(resolverCacheKey(simChain, big.NewInt(2)), latest.toAsset()); evicted.ContentHash != latest.ContentHash {
+		t.Error("refreshing an evicted entry should return the latest asset")
+	}
+}
+
+func TestBioCIDToBioIPResolverCache(t *testing.T) {
+	original := testAsset(1, 0)
+	reg := newSimRegistry()
+	scriptTree(reg, original)
+	chain := simchain.New(t, reg)
+	backend := &blockRecorder{Backend: chain.Miner()}
+	m := NewBioIPManager(
+		WithBackend(simChain, backend),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+		WithResolverCache(time.Hour, time.Minute, 0),
+	)
+	defer m.Close()
+
+	now := time.Unix(1700000000, 0)
+	m.resolver.now = func() time.Time { return now }
+
+	calls := func() int {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return len(backend.blocks)
+	}
+	b := &biocid.BioCID{Chain: simChain, Collection: simRegistry.Hex(), TokenID: "1"}
+	ctx := context.Background()
+	if _, err := m.BioCIDToBioIP(ctx, b); err != nil {
+		t.Fatalf("BioCIDToBioIP failed: %v", err)
+	}
+
+	// Both halves change on-chain; the cache decides which ones are seen
+	changed := testAsset(1, 0)
+	changed.ConsentState = 2
+	changed.ContentHash = [32]byte{0xFF}
+	changed.Generation = big.NewInt(5)
+	scriptTree(reg, changed)
+	chain.Apply(t, reg)
+
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		wantFetch   bool
+		wantConsent uint8
+		wantContent [32]byte
+	}{
+		{name: "within both TTLs", elapsed: time.Second, wantConsent: original.ConsentState, wantContent: original.ContentHash},
+		{name: "volatile TTL passed", elapsed: 2 * time.Minute, wantFetch: true, wantConsent: changed.ConsentState, wantContent: original.ContentHash},
+		{name: "refreshed volatile fields cached", elapsed: 2*time.Minute + time.Second, wantConsent: changed.ConsentState, wantContent: original.ContentHash},
+		{name: "immutable TTL passed", elapsed: 2 * time.Hour, wantFetch: true, wantConsent: changed.ConsentState, wantContent: changed.ContentHash},
+	}
+	for _, tt := range tests {
+		now = time.Unix(1700000000, 0).Add(tt.elapsed)
+		before := calls()
+
+		got, err := m.BioCIDToBioIP(ctx, b)
+		if err != nil {
+			t.Fatalf("%s: BioCIDToBioIP failed: %v", tt.name, err)
+		}
+		if fetched := calls() > before; fetched != tt.wantFetch {
+			t.Errorf("%s: fetched %v, want %v", tt.name, fetched, tt.wantFetch)
+		}
+		if got.ConsentState != tt.wantConsent {
+			t.Errorf("%s: consent state %d, want %d", tt.name, got.ConsentState, tt.wantConsent)
+		}
+		if got.ContentHash != tt.wantContent {
+			t.Errorf("%s: content hash %s, want %s", tt.name, got.ContentHash.Hex(), biocid.ContentHash(tt.wantContent).Hex())
+		}
+	}
+}
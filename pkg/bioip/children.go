@@ -0,0 +1,154 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// defaultChildPageSize is the number of children fetched per batch by ChildIter
+const defaultChildPageSize = 100
+
+// ChildIter iterates the direct children of a token, fetching them a page at a time
+// Use it instead of GetBioIP's ChildTokenIDs when only the first few children are needed
+type ChildIter struct {
+	ctx    context.Context
+	m      *BioIPManager
+	chain  biocid.Chain
+	parent *big.Int
+	count  uint64 // Number of children reported when the iterator was created
+	next   uint64 // Index of the next child to fetch
+	page   []*big.Int
+	cur    *big.Int
+	err    error
+}
+
+// ChildrenIterator returns an iterator over the direct children of tokenID
+// The child count is read once up front; children are fetched with childTokenIdAt as Next needs them
+func (m *BioIPManager) ChildrenIterator(ctx context.Context, chain biocid.Chain, tokenID *big.Int) (*ChildIter, error) {
+	contract, err := m.registry(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := m.call(ctx, contract, "childCount", tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	count := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	if !count.IsUint64() {
+		return nil, fmt.Errorf("child count out of range for token %s: %s", tokenID, count)
+	}
+
+	return &ChildIter{
+		ctx:    ctx,
+		m:      m,
+		chain:  chain,
+		parent: tokenID,
+		count:  count.Uint64(),
+	}, nil
+}
+
+// Next advances to the next child, fetching another page when the current one is used up
+// Returns false when the children are exhausted or a fetch fails; check Err afterwards
+func (it *ChildIter) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.next >= it.count {
+			it.cur = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.cur = nil
+			return false
+		}
+	}
+
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Value returns the child token ID at the current position
+func (it *ChildIter) Value() *big.Int {
+	return it.cur
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *ChildIter) Err() error {
+	return it.err
+}
+
+// fetch loads the next page of children in a single batch request
+func (it *ChildIter) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+
+	addr, err := it.m.registryAddress(it.chain)
+	if err != nil {
+		return err
+	}
+
+	client, err := it.m.getClient(it.ctx, it.chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", it.chain, err)
+	}
+
+	size := it.count - it.next
+	if size > defaultChildPageSize {
+		size = defaultChildPageSize
+	}
+
+	calldata := make([][]byte, size)
+	for i := range calldata {
+		index := new(big.Int).SetUint64(it.next + uint64(i))
+		data, err := registryABI.Pack("childTokenIdAt", it.parent, index)
+		if err != nil {
+			return fmt.Errorf("failed to encode childTokenIdAt(%s, %s): %w", it.parent, index, err)
+		}
+		calldata[i] = data
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(it.ctx, it.m.timeout)
+	defer cancel()
+
+	var results [][]byte
+	var errs []error
+	start := time.Now()
+	err = it.m.retryFor("childTokenIdAt batch").Do(ctx, func() error {
+		results, errs, err = chainrpc.BatchCallContract(ctx, client, addr, calldata)
+		return err
+	})
+	chainrpc.ObserveCall(it.m.logger, it.m.metrics, "childTokenIdAt batch", start, err)
+	if err != nil {
+		return fmt.Errorf("failed to batch childTokenIdAt: %w", err)
+	}
+
+	page := make([]*big.Int, 0, size)
+	for i := range results {
+		index := it.next + uint64(i)
+		if errs[i] != nil {
+			return fmt.Errorf("failed to call childTokenIdAt(%s, %d): %w", it.parent, index, errs[i])
+		}
+
+		out, err := registryABI.Unpack("childTokenIdAt", results[i])
+		if err != nil {
+			return fmt.Errorf("failed to decode childTokenIdAt(%s, %d): %w", it.parent, index, err)
+		}
+		page = append(page, *abi.ConvertType(out[0], new(*big.Int)).(**big.Int))
+	}
+
+	it.next += size
+	it.page = page
+	return nil
+}
@@ -0,0 +1,99 @@
+package bioip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+// scriptChildren scripts childCount and childTokenIdAt for a parent with count children numbered from first
+func scriptChildren(reg *simchain.Contract, parent int64, count int, first int64) {
+	reg.On("childCount", big.NewInt(parent)).Returns(big.NewInt(int64(count)))
+	for i := 0; i < count; i++ {
+		reg.On("childTokenIdAt", big.NewInt(parent), big.NewInt(int64(i))).Returns(big.NewInt(first + int64(i)))
+	}
+}
+
+func TestChildrenIterator(t *testing.T) {
+	reg := newSimRegistry()
+	scriptChildren(reg, 1, 250, 1000)
+	scriptChildren(reg, 2, 0, 0)
+	// Token 3 reports two children but the second cannot be read, failing the page holding both
+	reg.On("childCount", big.NewInt(3)).Returns(big.NewInt(2))
+	reg.On("childTokenIdAt", big.NewInt(3), big.NewInt(0)).Returns(big.NewInt(30))
+	reg.On("childTokenIdAt", big.NewInt(3), big.NewInt(1)).Reverts()
+	chain := simchain.New(t, reg)
+
+	tests := []struct {
+		name      string
+		parent    int64
+		limit     int // Stop after this many children; 0 iterates them all
+		wantFirst int64
+		wantCount int
+		wantCalls int // childCount plus one batch per page
+		wantErr   bool
+	}{
+		{name: "all children", parent: 1, wantFirst: 1000, wantCount: 250, wantCalls: 4},
+		{name: "stop within the first page", parent: 1, limit: 3, wantFirst: 1000, wantCount: 3, wantCalls: 2},
+		{name: "stop on a page boundary", parent: 1, limit: 100, wantFirst: 1000, wantCount: 100, wantCalls: 2},
+		{name: "stop in the second page", parent: 1, limit: 101, wantFirst: 1000, wantCount: 101, wantCalls: 3},
+		{name: "no children", parent: 2, wantCalls: 1},
+		{name: "unreadable child", parent: 3, wantCalls: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := newFakeSink()
+			m := NewBioIPManager(
+				WithBackend(simChain, chain.Miner()),
+				WithRegistry(simChain, simRegistry),
+				WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+				WithMetrics(sink),
+			)
+			defer m.Close()
+
+			it, err := m.ChildrenIterator(context.Background(), simChain, big.NewInt(tt.parent))
+			if err != nil {
+				t.Fatalf("ChildrenIterator failed: %v", err)
+			}
+
+			var got []int64
+			for it.Next() {
+				got = append(got, it.Value().Int64())
+				if len(got) == tt.limit {
+					break
+				}
+			}
+
+			if tt.wantErr {
+				if it.Err() == nil || it.Value() != nil {
+					t.Fatalf("got %v at %v, want an error", it.Err(), it.Value())
+				}
+				if it.Next() {
+					t.Error("Next succeeded after an error")
+				}
+			} else if it.Err() != nil {
+				t.Fatalf("iteration failed: %v", it.Err())
+			}
+
+			if len(got) != tt.wantCount {
+				t.Fatalf("got %d children, want %d", len(got), tt.wantCount)
+			}
+			for i, id := range got {
+				if id != tt.wantFirst+int64(i) {
+					t.Fatalf("child %d = %d, want %d", i, id, tt.wantFirst+int64(i))
+				}
+			}
+			if calls := sink.counters[chainrpc.MetricRPCCalls]; calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	m, _ := newSimManager(t, []*simchain.Contract{newSimRegistry()})
+	if _, err := m.ChildrenIterator(context.Background(), "avalanche", big.NewInt(1)); err == nil {
+		t.Error("expected a chain without a registry to fail")
+	}
+}
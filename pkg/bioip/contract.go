@@ -0,0 +1,246 @@
+package bioip
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed BioIPRegistry.abi.json
+var registryABIJSON string
+
+// registryABI is the parsed BioIPRegistry contract ABI
+var registryABI = mustParseABI(registryABIJSON)
+
+// mustParseABI parses an embedded contract ABI
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(fmt.Sprintf("bioip: invalid embedded ABI: %v", err))
+	}
+	return parsed
+}
+
+// registryBioIPAsset mirrors the BioIPRegistry.BioIPAsset tuple as decoded by the ABI
+type registryBioIPAsset struct {
+	Owner          common.Address
+	TokenId        *big.Int
+	ConsentState   uint8
+	CreatedAt      *big.Int
+	RevokedAt      *big.Int
+	ContentHash    [32]byte
+	DataType       string
+	DataSize       *big.Int
+	BioCID         [32]byte
+	IpAssetId      common.Address
+	LicenseTermsId *big.Int
+	HasLicense     bool
+	ParentTokenId  *big.Int
+	ChildTokenIds  []*big.Int
+	Generation     *big.Int
+	LicenseTokenId *big.Int
+}
+
+// toAsset converts the decoded tuple into a BioIPAsset
+func (r *registryBioIPAsset) toAsset() *BioIPAsset {
+	return &BioIPAsset{
+		Owner:          r.Owner,
+		TokenID:        r.TokenId,
+		ConsentState:   r.ConsentState,
+		CreatedAt:      r.CreatedAt,
+		RevokedAt:      r.RevokedAt,
+		ContentHash:    biocid.ContentHash(r.ContentHash),
+		DataType:       r.DataType,
+		DataSize:       r.DataSize,
+		BioCID:         r.BioCID,
+		IPAssetID:      r.IpAssetId,
+		LicenseTermsID: r.LicenseTermsId,
+		HasLicense:     r.HasLicense,
+		ParentTokenID:  r.ParentTokenId,
+		ChildTokenIDs:  r.ChildTokenIds,
+		Generation:     r.Generation,
+		LicenseTokenID: r.LicenseTokenId,
+	}
+}
+
+// childTokenIdsField is the position of childTokenIds within the encoded BioIPAsset tuple head
+const childTokenIdsField = 13
+
+// decodeBioIP decodes a raw getBioIP result, rejecting ChildTokenIDs longer than maxChildren
+// The length is checked before unpacking so the ABI decoder never allocates an oversized array
+func decodeBioIP(data []byte, maxChildren int) (*registryBioIPAsset, error) {
+	if n, ok := encodedChildCount(data); ok && n > uint64(maxChildren) {
+		return nil, fmt.Errorf("%w: %d exceeds limit of %d", ErrTooManyChildren, n, maxChildren)
+	}
+
+	out, err := registryABI.Unpack("getBioIP", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode getBioIP: %w", err)
+	}
+
+	return abi.ConvertType(out[0], new(registryBioIPAsset)).(*registryBioIPAsset), nil
+}
+
+// encodedChildCount reads the childTokenIds length prefix from a raw getBioIP result
+// Reports false if the data is too malformed to locate it; Unpack then reports the error
+func encodedChildCount(data []byte) (uint64, bool) {
+	word := func(pos uint64) (uint64, bool) {
+		if uint64(len(data)) < 32 || pos > uint64(len(data))-32 {
+			return 0, false
+		}
+		v := new(big.Int).SetBytes(data[pos : pos+32])
+		if !v.IsUint64() {
+			return 0, false
+		}
+		return v.Uint64(), true
+	}
+
+	// The tuple is dynamic, so the result starts with its offset
+	tuple, ok := word(0)
+	if !ok {
+		return 0, false
+	}
+	offset, ok := word(tuple + 32*childTokenIdsField)
+	if !ok {
+		return 0, false
+	}
+	return word(tuple + offset)
+}
+
+// registryLicenseToken mirrors the BioIPRegistry.LicenseToken tuple as decoded by the ABI
+type registryLicenseToken struct {
+	TokenId       *big.Int
+	ParentTokenId *big.Int
+	MintedFor     common.Address
+	MintedAt      *big.Int
+	Consumed      bool
+	ConsumedBy    *big.Int
+}
+
+// toLicenseToken converts the decoded tuple into a LicenseToken
+func (r *registryLicenseToken) toLicenseToken() *LicenseToken {
+	return &LicenseToken{
+		TokenID:       r.TokenId,
+		ParentTokenID: r.ParentTokenId,
+		MintedFor:     r.MintedFor,
+		MintedAt:      r.MintedAt,
+		Consumed:      r.Consumed,
+		ConsumedBy:    r.ConsumedBy,
+	}
+}
+
+// SetRegistry sets or overrides the BioIPRegistry address for a chain
+func (m *BioIPManager) SetRegistry(chain biocid.Chain, addr common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.registries[chain] = addr
+}
+
+// registryAddress returns the BioIPRegistry address configured for a chain
+func (m *BioIPManager) registryAddress(chain biocid.Chain) (common.Address, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addr, ok := m.registries[chain]
+	if !ok {
+		return common.Address{}, fmt.Errorf("no BioIPRegistry configured for chain: %s", chain)
+	}
+	return addr, nil
+}
+
+// registryStartBlock returns the block a chain's BioIPRegistry history starts at, 0 if unknown
+func (m *BioIPManager) registryStartBlock(chain biocid.Chain) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.startBlocks[chain]
+}
+
+// registry returns a binding to the BioIPRegistry contract on the given chain
+func (m *BioIPManager) registry(ctx context.Context, chain biocid.Chain) (*bind.BoundContract, error) {
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	return bind.NewBoundContract(addr, registryABI, client, client, client), nil
+}
+
+// call invokes a read-only registry method, retrying transient RPC failures
+func (m *BioIPManager) call(ctx context.Context, contract *bind.BoundContract, method string, args ...interface{}) ([]interface{}, error) {
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var out []interface{}
+	err := m.retryFor(method).Do(ctx, func() error {
+		out = nil
+		return contract.Call(&bind.CallOpts{Context: ctx}, &out, method, args...)
+	})
+	chainrpc.ObserveCall(m.logger, m.metrics, method, start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	return out, nil
+}
+
+// callRaw invokes a read-only registry method and returns the undecoded result
+func (m *BioIPManager) callRaw(ctx context.Context, chain biocid.Chain, method string, args ...interface{}) ([]byte, error) {
+	return m.callRawAt(ctx, chain, nil, method, args...)
+}
+
+// callRawAt is callRaw against the state at blockNumber, or the latest state if it is nil
+func (m *BioIPManager) callRawAt(ctx context.Context, chain biocid.Chain, blockNumber *big.Int, method string, args ...interface{}) ([]byte, error) {
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	input, err := registryABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var data []byte
+	err = m.retryFor(method).Do(ctx, func() error {
+		data, err = client.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: input}, blockNumber)
+		return err
+	})
+	chainrpc.ObserveCall(m.logger, m.metrics, method, start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	return data, nil
+}
+
+// retryFor returns the manager's retry policy, logging and counting each retry of op
+func (m *BioIPManager) retryFor(op string) chainrpc.RetryPolicy {
+	return m.retry.WithRetryHook(chainrpc.RetryHook(m.logger, m.metrics, op))
+}
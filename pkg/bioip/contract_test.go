@@ -0,0 +1,121 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSetRegistry(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0))
+
+	other := simchain.NewContract(common.HexToAddress("0x00000000000000000000000000000000000B1011"), registryABI)
+	otherAsset := testAsset(1, 0)
+	otherAsset.DataType = "bam"
+	other.On("getBioIP").Returns(otherAsset)
+
+	m, _ := newSimManager(t, []*simchain.Contract{reg, other})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		registry *common.Address
+		want     string
+	}{
+		{name: "configured by option", want: "vcf"},
+		{name: "overridden", registry: &other.Address, want: "bam"},
+		{name: "restored", registry: &simRegistry, want: "vcf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.registry != nil {
+				m.SetRegistry(simChain, *tt.registry)
+			}
+			asset, err := m.GetBioIP(ctx, simChain, big.NewInt(1))
+			if err != nil {
+				t.Fatalf("GetBioIP failed: %v", err)
+			}
+			if asset.DataType != tt.want {
+				t.Errorf("got data type %q, want %q", asset.DataType, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryAddressUnconfigured(t *testing.T) {
+	m := NewBioIPManager()
+	if _, err := m.registryAddress("story"); err == nil {
+		t.Error("expected an error for a chain without a registry")
+	}
+
+	m.SetRegistry("story", simRegistry)
+	if addr, err := m.registryAddress("story"); err != nil || addr != simRegistry {
+		t.Errorf("got %s, %v; want %s", addr, err, simRegistry)
+	}
+}
+
+// encodeBioIP ABI-encodes asset as getBioIP returns it, with the childTokenIds length word
+// replaced by childCount when it is nonzero
+func encodeBioIP(t *testing.T, asset registryBioIPAsset, childCount uint64) []byte {
+	t.Helper()
+
+	data, err := registryABI.Methods["getBioIP"].Outputs.Pack(asset)
+	if err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+	if childCount == 0 {
+		return data
+	}
+
+	word := func(pos uint64) uint64 { return new(big.Int).SetBytes(data[pos : pos+32]).Uint64() }
+	tuple := word(0)
+	lengthAt := tuple + word(tuple+32*childTokenIdsField)
+	copy(data[lengthAt:lengthAt+32], common.LeftPadBytes(new(big.Int).SetUint64(childCount).Bytes(), 32))
+	return data
+}
+
+func TestGetBioIPMaxChildren(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0, 2, 3, 4))
+	// Claims 2^40 children while carrying three; decoding it unchecked would allocate terabytes
+	reg.On("getBioIP", big.NewInt(5)).ReturnsRaw(encodeBioIP(t, testAsset(5, 0, 2, 3, 4), 1<<40))
+
+	tests := []struct {
+		name        string
+		tokenID     int64
+		maxChildren int // Zero keeps the default
+		wantErr     error
+	}{
+		{name: "at the limit", tokenID: 1, maxChildren: 3},
+		{name: "over the limit", tokenID: 1, maxChildren: 2, wantErr: ErrTooManyChildren},
+		{name: "forged length", tokenID: 5, wantErr: ErrTooManyChildren},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.maxChildren > 0 {
+				opts = append(opts, WithMaxChildren(tt.maxChildren))
+			}
+			m, _ := newSimManager(t, []*simchain.Contract{reg}, opts...)
+
+			asset, err := m.GetBioIP(context.Background(), simChain, big.NewInt(tt.tokenID))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBioIP failed: %v", err)
+			}
+			if len(asset.ChildTokenIDs) != 3 {
+				t.Errorf("got %d children, want 3", len(asset.ChildTokenIDs))
+			}
+		})
+	}
+}
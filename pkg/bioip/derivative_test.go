@@ -0,0 +1,275 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// derivativeFlow holds the token IDs a scripted derivative flow mints
+type derivativeFlow struct {
+	parent, license, child int64
+}
+
+// scriptDerivativeFlow scripts the registry side of CreateDerivativeFlow for owner
+//
+// The license token only exists once mintLicenseTokens is mined, so registering before
+// minting the license fails the same way it would on chain. Registration consumes the
+// license and links the child to the parent; with registers unset it reverts instead
+func scriptDerivativeFlow(reg *simchain.Contract, owner common.Address, flow derivativeFlow, registers bool) {
+	parent := testAsset(flow.parent, 0)
+	parent.Owner = owner
+	minted := testAsset(flow.child, 0)
+	minted.Owner = owner
+	minted.HasLicense = false
+	minted.LicenseTermsId = new(big.Int)
+	linked := minted
+	linked.ParentTokenId = big.NewInt(flow.parent)
+	linked.Generation = big.NewInt(1)
+	consumed := testLicense(flow.license, flow.parent, owner)
+	consumed.Consumed = true
+	consumed.ConsumedBy = big.NewInt(flow.child)
+
+	hash := [32]byte{byte(flow.child)}
+	bioCID := [32]byte{0xb1, byte(flow.child)}
+
+	scriptTree(reg, parent)
+	reg.On("getLicenseToken").Returns(zeroLicense())
+	reg.On("mintLicenseTokens", big.NewInt(flow.parent), owner, big.NewInt(1)).
+		Returns([]*big.Int{big.NewInt(flow.license)}).
+		Emits("LicenseTokenMinted", big.NewInt(flow.license), big.NewInt(flow.parent), owner).
+		Then(reg.Next("getLicenseToken", big.NewInt(flow.license)).Returns(testLicense(flow.license, flow.parent, owner)))
+	reg.On("mintDerivativeBioIP", hash, "vcf", big.NewInt(1024), bioCID, simIPAsset).
+		Returns(big.NewInt(flow.child)).
+		Emits("BioIPMinted", big.NewInt(flow.child), owner, hash, "vcf", bioCID, simIPAsset, new(big.Int)).
+		Then(reg.Next("getBioIP", big.NewInt(flow.child)).Returns(minted))
+
+	register := reg.On("registerDerivative", big.NewInt(flow.child), big.NewInt(flow.license))
+	if !registers {
+		register.Reverts()
+		return
+	}
+	register.
+		Emits("LicenseTokenConsumed", big.NewInt(flow.license), big.NewInt(flow.child)).
+		Then(
+			reg.Next("getBioIP", big.NewInt(flow.child)).Returns(linked),
+			reg.Next("getLicenseToken", big.NewInt(flow.license)).Returns(consumed),
+		)
+}
+
+// createDerivative runs CreateDerivativeFlow for flow's child under parent
+func createDerivative(m *BioIPManager, flow derivativeFlow, signer *bind.TransactOpts) (*big.Int, error) {
+	return m.CreateDerivativeFlow(context.Background(), simChain, big.NewInt(flow.parent),
+		biocid.ContentHash{byte(flow.child)}, "vcf", 1024, [32]byte{0xb1, byte(flow.child)}, simIPAsset, signer)
+}
+
+func TestCreateDerivativeFlow(t *testing.T) {
+	flow := derivativeFlow{parent: 1, license: 50, child: 2}
+
+	tests := []struct {
+		name   string
+		nonces NonceMode
+	}{
+		{name: "wait for each transaction", nonces: NonceWaitEach},
+		{name: "local nonces", nonces: NonceLocal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newSimRegistry()
+			m, chain := newSimManager(t, []*simchain.Contract{reg}, WithNonceMode(tt.nonces))
+			signer := chain.Auth(t, 0)
+			scriptDerivativeFlow(reg, signer.From, flow, true)
+			chain.Apply(t, reg)
+
+			ctx := context.Background()
+			childID, err := createDerivative(m, flow, signer)
+			if err != nil {
+				t.Fatalf("CreateDerivativeFlow failed: %v", err)
+			}
+			if childID.Int64() != flow.child {
+				t.Fatalf("got child %s, want %d", childID, flow.child)
+			}
+
+			lineage, err := m.GetLineage(ctx, simChain, childID)
+			if err != nil {
+				t.Fatalf("GetLineage failed: %v", err)
+			}
+			if len(lineage) != 1 || lineage[0].Int64() != flow.parent {
+				t.Errorf("got lineage %v, want [%d]", lineage, flow.parent)
+			}
+
+			license, err := m.GetLicenseToken(ctx, simChain, big.NewInt(flow.license))
+			if err != nil {
+				t.Fatalf("GetLicenseToken failed: %v", err)
+			}
+			if !license.Consumed || license.ConsumedBy.Int64() != flow.child {
+				t.Errorf("license consumed = %v by %v, want consumed by %d", license.Consumed, license.ConsumedBy, flow.child)
+			}
+		})
+	}
+}
+
+func TestCreateDerivativeFlowRegistrationFails(t *testing.T) {
+	flow := derivativeFlow{parent: 1, license: 50, child: 2}
+
+	reg := newSimRegistry()
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+	scriptDerivativeFlow(reg, signer.From, flow, false)
+	chain.Apply(t, reg)
+
+	ctx := context.Background()
+	before, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+
+	if _, err := createDerivative(m, flow, signer); err == nil {
+		t.Fatal("expected the registration failure to be returned")
+	}
+
+	// Both mints landed, leaving an unlinked child and an unconsumed license to retry with
+	after, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+	if sent := after - before; sent != 2 {
+		t.Errorf("sent %d transactions, want the two mints", sent)
+	}
+
+	child, err := m.GetBioIP(ctx, simChain, big.NewInt(flow.child))
+	if err != nil {
+		t.Fatalf("GetBioIP failed: %v", err)
+	}
+	if child.ParentTokenID.Sign() != 0 {
+		t.Errorf("child linked to %s, want no parent", child.ParentTokenID)
+	}
+
+	license, err := m.GetLicenseToken(ctx, simChain, big.NewInt(flow.license))
+	if err != nil {
+		t.Fatalf("GetLicenseToken failed: %v", err)
+	}
+	if license.Consumed {
+		t.Errorf("license consumed by %s after a failed registration", license.ConsumedBy)
+	}
+}
+
+func TestRegisterDerivativeBeforeLicenseMint(t *testing.T) {
+	flow := derivativeFlow{parent: 1, license: 50, child: 2}
+
+	reg := newSimRegistry()
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+	scriptDerivativeFlow(reg, signer.From, flow, true)
+	chain.Apply(t, reg)
+
+	// Out of order, the license token does not exist yet
+	err := m.RegisterDerivative(context.Background(), simChain,
+		big.NewInt(flow.child), big.NewInt(flow.parent), big.NewInt(flow.license), signer)
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("got %v, want %v", err, ErrTokenNotFound)
+	}
+}
+
+// sentBy returns the transactions from sender mined after block from, in chain order
+func sentBy(t *testing.T, chain *simchain.Chain, sender common.Address, from uint64) []*types.Transaction {
+	t.Helper()
+
+	ctx := context.Background()
+	head, err := chain.Backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber failed: %v", err)
+	}
+
+	var txs []*types.Transaction
+	for n := from + 1; n <= head.Number.Uint64(); n++ {
+		block, err := chain.Backend.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			t.Fatalf("BlockByNumber(%d) failed: %v", n, err)
+		}
+		for _, tx := range block.Transactions() {
+			if got, err := types.Sender(types.LatestSignerForChainID(simchain.ChainID), tx); err == nil && got == sender {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	return txs
+}
+
+func TestCreateDerivativeFlowNonces(t *testing.T) {
+	flow := derivativeFlow{parent: 1, license: 50, child: 2}
+	wantMethods := []string{"mintLicenseTokens", "mintDerivativeBioIP", "registerDerivative"}
+
+	tests := []struct {
+		name        string
+		nonces      NonceMode
+		mineEach    bool // Mine every transaction on send rather than on a timer
+		presetNonce bool
+	}{
+		{name: "wait for each transaction", nonces: NonceWaitEach, mineEach: true},
+		{name: "local nonces", nonces: NonceLocal},
+		{name: "local nonces from the signer", nonces: NonceLocal, presetNonce: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newSimRegistry()
+			chain := simchain.New(t, reg)
+			signer := chain.Auth(t, 0)
+			scriptDerivativeFlow(reg, signer.From, flow, true)
+			chain.Apply(t, reg)
+
+			// With local nonces the two mints share the pending pool until the next block
+			var backend chainrpc.Backend = chain.Miner()
+			if !tt.mineEach {
+				backend = chain.Backend
+				chain.AutoCommit(t, 20*time.Millisecond)
+			}
+			m := NewBioIPManager(
+				WithBackend(simChain, backend),
+				WithRegistry(simChain, simRegistry),
+				WithNonceMode(tt.nonces),
+			)
+			defer m.Close()
+
+			ctx := context.Background()
+			start, err := chain.Backend.HeaderByNumber(ctx, nil)
+			if err != nil {
+				t.Fatalf("HeaderByNumber failed: %v", err)
+			}
+			first, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+			if tt.presetNonce {
+				signer.Nonce = new(big.Int).SetUint64(first)
+			}
+
+			if _, err := createDerivative(m, flow, signer); err != nil {
+				t.Fatalf("CreateDerivativeFlow failed: %v", err)
+			}
+
+			txs := sentBy(t, chain, signer.From, start.Number.Uint64())
+			if len(txs) != len(wantMethods) {
+				t.Fatalf("mined %d transactions, want %d", len(txs), len(wantMethods))
+			}
+			for i, tx := range txs {
+				if tx.Nonce() != first+uint64(i) {
+					t.Errorf("transaction %d has nonce %d, want %d", i, tx.Nonce(), first+uint64(i))
+				}
+				method, err := registryABI.MethodById(tx.Data())
+				if err != nil || method.Name != wantMethods[i] {
+					t.Errorf("transaction %d calls %v, want %s", i, method, wantMethods[i])
+				}
+			}
+		})
+	}
+}
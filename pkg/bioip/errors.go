@@ -0,0 +1,47 @@
+package bioip
+
+import "errors"
+
+var (
+	// ErrTokenNotFound is returned when a token does not exist in the registry
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrLineageTooDeep is returned when a lineage walk exceeds the configured max depth
+	ErrLineageTooDeep = errors.New("lineage too deep")
+
+	// ErrLineageCycle is returned when a lineage walk revisits a token
+	ErrLineageCycle = errors.New("lineage cycle detected")
+
+	// ErrCollectionMismatch is returned when a BioCID's collection does not match the asset it resolves to
+	ErrCollectionMismatch = errors.New("collection mismatch")
+
+	// ErrContentHashMismatch is returned when a BioCID's content hash differs from the asset it resolves to
+	ErrContentHashMismatch = errors.New("content hash mismatch")
+
+	// ErrTooManyChildren is returned when a token reports more children than the configured limit
+	ErrTooManyChildren = errors.New("too many children")
+
+	// ErrGenerationMismatch is returned when a token's Generation disagrees with its depth
+	ErrGenerationMismatch = errors.New("generation mismatch")
+
+	// ErrParentNotLicensed is returned when a derivative parent has no PIL license terms
+	ErrParentNotLicensed = errors.New("parent has no license terms")
+
+	// ErrParentConsentInactive is returned when a derivative parent's consent is not active
+	ErrParentConsentInactive = errors.New("parent consent not active")
+
+	// ErrLicenseConsumed is returned when a one-time license token has already been used
+	ErrLicenseConsumed = errors.New("license token already consumed")
+
+	// ErrLicenseParentMismatch is returned when a license token was minted by a different parent
+	ErrLicenseParentMismatch = errors.New("license token parent mismatch")
+
+	// ErrInvalidPageToken is returned when a pagination token is malformed or for another query
+	ErrInvalidPageToken = errors.New("invalid page token")
+
+	// ErrIPAssetNotRegistered is returned when a token's IPAssetID is not its registered Story IP account
+	ErrIPAssetNotRegistered = errors.New("IP asset not registered")
+
+	// ErrLicenseNotForSigner is returned when a license token was minted for another wallet
+	ErrLicenseNotForSigner = errors.New("license token not minted for signer")
+)
@@ -0,0 +1,119 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EstimateMintRootBioIP dry-runs MintRootBioIP as sent by from
+func (m *BioIPManager) EstimateMintRootBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	from common.Address,
+	contentHash biocid.ContentHash,
+	dataType string,
+	dataSize uint64,
+	bioCID [32]byte,
+	ipAssetID common.Address,
+	licenseTermsID *big.Int,
+) (*chainrpc.GasEstimate, error) {
+	dataType, err := m.normalizeDataType(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.estimate(ctx, chain, from, "mintRootBioIP",
+		[32]byte(contentHash),
+		dataType,
+		new(big.Int).SetUint64(dataSize),
+		bioCID,
+		ipAssetID,
+		licenseTermsID,
+	)
+}
+
+// EstimateMintLicenseTokens dry-runs MintLicenseTokens as sent by from
+func (m *BioIPManager) EstimateMintLicenseTokens(
+	ctx context.Context,
+	chain biocid.Chain,
+	from common.Address,
+	parentTokenID *big.Int,
+	receiver common.Address,
+	amount *big.Int,
+) (*chainrpc.GasEstimate, error) {
+	return m.estimate(ctx, chain, from, "mintLicenseTokens",
+		parentTokenID,
+		receiver,
+		amount,
+	)
+}
+
+// EstimateMintDerivativeBioIP dry-runs MintDerivativeBioIP as sent by from
+func (m *BioIPManager) EstimateMintDerivativeBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	from common.Address,
+	contentHash biocid.ContentHash,
+	dataType string,
+	dataSize uint64,
+	bioCID [32]byte,
+	ipAssetID common.Address,
+) (*chainrpc.GasEstimate, error) {
+	dataType, err := m.normalizeDataType(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.estimate(ctx, chain, from, "mintDerivativeBioIP",
+		[32]byte(contentHash),
+		dataType,
+		new(big.Int).SetUint64(dataSize),
+		bioCID,
+		ipAssetID,
+	)
+}
+
+// EstimateRegisterDerivative dry-runs RegisterDerivative as sent by from
+// Unlike RegisterDerivative, the license token is not pre-validated; a bad token
+// surfaces as a revert from the estimate
+func (m *BioIPManager) EstimateRegisterDerivative(
+	ctx context.Context,
+	chain biocid.Chain,
+	from common.Address,
+	childTokenID *big.Int,
+	licenseTokenID *big.Int,
+) (*chainrpc.GasEstimate, error) {
+	return m.estimate(ctx, chain, from, "registerDerivative",
+		childTokenID,
+		licenseTokenID,
+	)
+}
+
+// estimate packs a registry write and estimates its gas without signing or sending it
+func (m *BioIPManager) estimate(
+	ctx context.Context,
+	chain biocid.Chain,
+	from common.Address,
+	method string,
+	args ...interface{},
+) (*chainrpc.GasEstimate, error) {
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	return chainrpc.EstimateGas(ctx, client, from, addr, registryABI, method, args...)
+}
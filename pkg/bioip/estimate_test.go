@@ -0,0 +1,68 @@
+package bioip
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+func TestEstimateMintRootBioIP(t *testing.T) {
+	reg := newSimRegistry()
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+	from := chain.Address(0)
+	scriptMintRoot(reg, from, 1, 7, true)
+	reg.On("mintRootBioIP", [32]byte{3}, "vcf", big.NewInt(1024), [32]byte{0xb1, 3}, simIPAsset, big.NewInt(1)).Reverts()
+	chain.Apply(t, reg)
+
+	tests := []struct {
+		name        string
+		contentHash byte
+		dataType    string
+		wantErr     bool
+	}{
+		{name: "estimated", contentHash: 1, dataType: "vcf"},
+		{name: "data type normalized", contentHash: 1, dataType: "VCF"},
+		{name: "reverts", contentHash: 3, dataType: "vcf", wantErr: true},
+		{name: "unknown data type", contentHash: 1, dataType: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			before, err := chain.Backend.PendingNonceAt(ctx, from)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+
+			est, err := m.EstimateMintRootBioIP(ctx, simChain, from,
+				biocid.ContentHash{tt.contentHash}, tt.dataType, 1024, [32]byte{0xb1, tt.contentHash}, simIPAsset, big.NewInt(1))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("EstimateMintRootBioIP failed: %v", err)
+				}
+				want, err := registryABI.Pack("mintRootBioIP", [32]byte{tt.contentHash}, "vcf", big.NewInt(1024), [32]byte{0xb1, tt.contentHash}, simIPAsset, big.NewInt(1))
+				if err != nil {
+					t.Fatalf("Pack failed: %v", err)
+				}
+				if !bytes.Equal(est.Data, want) || est.From != from || est.To != simRegistry || est.Gas == 0 {
+					t.Errorf("unexpected estimate %+v", est)
+				}
+			}
+
+			after, err := chain.Backend.PendingNonceAt(ctx, from)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+			if after != before {
+				t.Errorf("nonce moved from %d to %d, want no transaction", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// GetAvailableLicenseTokens returns unused license tokens for a parent
+// LicenseTokenMinted events from fromBlock onwards are scanned in block chunks and
+// tokens with a matching LicenseTokenConsumed event are dropped
+func (m *BioIPManager) GetAvailableLicenseTokens(
+	ctx context.Context,
+	chain biocid.Chain,
+	parentTokenID *big.Int,
+	fromBlock uint64,
+) ([]*big.Int, error) {
+	minted, err := m.scanRegistryLogs(ctx, chain, fromBlock, [][]common.Hash{
+		{registryABI.Events["LicenseTokenMinted"].ID},
+		nil,
+		{common.BigToHash(parentTokenID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenIDs []*big.Int
+	var topics []common.Hash
+	seen := make(map[common.Hash]bool)
+	for _, log := range minted {
+		if log.Removed || len(log.Topics) < 2 || seen[log.Topics[1]] {
+			continue
+		}
+		seen[log.Topics[1]] = true
+		tokenIDs = append(tokenIDs, log.Topics[1].Big())
+		topics = append(topics, log.Topics[1])
+	}
+
+	if len(tokenIDs) == 0 {
+		return []*big.Int{}, nil
+	}
+
+	consumed, err := m.scanRegistryLogs(ctx, chain, fromBlock, [][]common.Hash{
+		{registryABI.Events["LicenseTokenConsumed"].ID},
+		topics,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[common.Hash]bool)
+	for _, log := range consumed {
+		if !log.Removed && len(log.Topics) >= 2 {
+			used[log.Topics[1]] = true
+		}
+	}
+
+	available := []*big.Int{}
+	for i, tokenID := range tokenIDs {
+		if !used[topics[i]] {
+			available = append(available, tokenID)
+		}
+	}
+
+	return available, nil
+}
+
+// GetBioIPByContentHash finds the most recently minted BioIP with the given content hash
+// The registry's history is scanned from the block set by WithRegistryStartBlock, or from block 0;
+// use GetBioIPByContentHashInRange to bound the scan
+func (m *BioIPManager) GetBioIPByContentHash(
+	ctx context.Context,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
+) (*BioIPAsset, error) {
+	logs, err := m.scanRegistryLogs(ctx, chain, m.registryStartBlock(chain), [][]common.Hash{
+		{registryABI.Events["BioIPMinted"].ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.matchContentHash(ctx, chain, contentHash, logs)
+}
+
+// GetBioIPByContentHashInRange finds the most recently minted BioIP with the given content
+// hash among BioIPMinted events between fromBlock and toBlock inclusive
+func (m *BioIPManager) GetBioIPByContentHashInRange(
+	ctx context.Context,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
+	fromBlock uint64,
+	toBlock uint64,
+) (*BioIPAsset, error) {
+	if toBlock < fromBlock {
+		return nil, fmt.Errorf("invalid block range: %d-%d", fromBlock, toBlock)
+	}
+
+	logs, err := m.scanRegistryLogRange(ctx, chain, fromBlock, toBlock, [][]common.Hash{
+		{registryABI.Events["BioIPMinted"].ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.matchContentHash(ctx, chain, contentHash, logs)
+}
+
+// matchContentHash returns the newest still-existing asset whose BioIPMinted log has contentHash
+func (m *BioIPManager) matchContentHash(
+	ctx context.Context,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
+	logs []types.Log,
+) (*BioIPAsset, error) {
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		if log.Removed || len(log.Topics) < 2 {
+			continue
+		}
+
+		out, err := registryABI.Unpack("BioIPMinted", log.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode BioIPMinted: %w", err)
+		}
+
+		if hash, ok := out[0].([32]byte); !ok || hash != [32]byte(contentHash) {
+			continue
+		}
+
+		asset, err := m.GetBioIP(ctx, chain, log.Topics[1].Big())
+		if errors.Is(err, ErrTokenNotFound) {
+			continue
+		}
+		return asset, err
+	}
+
+	return nil, fmt.Errorf("%w: no BioIP with content hash %s", ErrTokenNotFound, contentHash)
+}
+
+// reconcileLicenseConsumption marks token consumed if a recent LicenseTokenConsumed event says so
+// Consumption is final, so any such event overrides an unconsumed view
+func (m *BioIPManager) reconcileLicenseConsumption(ctx context.Context, chain biocid.Chain, token *LicenseToken) error {
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	var head uint64
+	err = m.withRetry(ctx, "eth_blockNumber", func(ctx context.Context) error {
+		head, err = chainrpc.BlockNumber(ctx, client)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	from := uint64(0)
+	if head > m.reconcile {
+		from = head - m.reconcile
+	}
+
+	logs, err := m.scanRegistryLogRange(ctx, chain, from, head, [][]common.Hash{
+		{registryABI.Events["LicenseTokenConsumed"].ID},
+		{common.BigToHash(token.TokenID)},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, log := range logs {
+		if !log.Removed && len(log.Topics) >= 3 {
+			token.Consumed = true
+			token.ConsumedBy = log.Topics[2].Big()
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// scanRegistryLogs collects registry logs from fromBlock to the chain head
+func (m *BioIPManager) scanRegistryLogs(
+	ctx context.Context,
+	chain biocid.Chain,
+	fromBlock uint64,
+	topics [][]common.Hash,
+) ([]types.Log, error) {
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	var head uint64
+	err = m.withRetry(ctx, "eth_blockNumber", func(ctx context.Context) error {
+		head, err = chainrpc.BlockNumber(ctx, client)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	return m.scanRegistryLogRange(ctx, chain, fromBlock, head, topics)
+}
+
+// scanRegistryLogRange collects registry logs between fromBlock and toBlock inclusive
+// The range is queried in chunks, narrowed further when a provider rejects one as too large
+func (m *BioIPManager) scanRegistryLogRange(
+	ctx context.Context,
+	chain biocid.Chain,
+	fromBlock uint64,
+	toBlock uint64,
+	topics [][]common.Hash,
+) ([]types.Log, error) {
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{addr},
+		Topics:    topics,
+	}
+
+	filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		var logs []types.Log
+		err := m.withRetry(ctx, "eth_getLogs", func(ctx context.Context) error {
+			var err error
+			logs, err = client.FilterLogs(ctx, q)
+			return err
+		})
+		return logs, err
+	}
+
+	var logs []types.Log
+	err = chainrpc.ScanLogs(ctx, filter, query, chainrpc.DefaultLogChunk, func(chunk []types.Log) error {
+		logs = append(logs, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// withRetry runs the RPC op under the default timeout, retrying transient failures
+func (m *BioIPManager) withRetry(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := m.retryFor(op).Do(ctx, func() error {
+		return fn(ctx)
+	})
+	chainrpc.ObserveCall(m.logger, m.metrics, op, start, err)
+	return err
+}
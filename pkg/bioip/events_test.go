@@ -0,0 +1,84 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetBioIPByContentHash(t *testing.T) {
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	reg := newSimRegistry()
+	// Each mint is keyed by its token's BioCID so one content hash can be minted twice
+	mintArgs := func(contentHash byte, tokenID int64) []interface{} {
+		return []interface{}{[32]byte{contentHash}, "vcf", big.NewInt(1024), [32]byte{0xb1, byte(tokenID)}, simIPAsset, big.NewInt(1)}
+	}
+	mints := []struct{ contentHash, tokenID int64 }{{1, 7}, {2, 8}, {1, 9}, {3, 10}, {3, 11}}
+	for _, mint := range mints {
+		reg.On("mintRootBioIP", mintArgs(byte(mint.contentHash), mint.tokenID)...).
+			Emits("BioIPMinted", big.NewInt(mint.tokenID), owner, [32]byte{byte(mint.contentHash)}, "vcf",
+				[32]byte{0xb1, byte(mint.tokenID)}, simIPAsset, big.NewInt(1))
+	}
+	// Token 11 was burned, so getBioIP returns the zero asset for it
+	scriptTree(reg, testAsset(7, 0), testAsset(8, 0), testAsset(9, 0), testAsset(10, 0))
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+
+	blocks := make(map[int64]uint64)
+	for _, mint := range mints {
+		blocks[mint.tokenID] = transactSim(t, chain, "mintRootBioIP", mintArgs(byte(mint.contentHash), mint.tokenID)...)
+	}
+
+	tests := []struct {
+		name        string
+		contentHash byte
+		start       uint64 // WithRegistryStartBlock, for whole-chain scans
+		from, to    uint64 // Zero to scan the whole chain
+		want        int64
+		wantErr     error
+	}{
+		{name: "single mint", contentHash: 2, want: 8},
+		{name: "newest mint wins", contentHash: 1, want: 9},
+		{name: "burned token skipped", contentHash: 3, want: 10},
+		{name: "never minted", contentHash: 4, wantErr: ErrTokenNotFound},
+		{name: "start block skips older mints", contentHash: 2, start: blocks[9], wantErr: ErrTokenNotFound},
+		{name: "start block at the newest mint", contentHash: 1, start: blocks[9], want: 9},
+		{name: "range before the remint", contentHash: 1, from: blocks[7], to: blocks[8], want: 7},
+		{name: "range after the mint", contentHash: 2, from: blocks[9], to: blocks[11], wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WithRegistryStartBlock(simChain, tt.start)(m)
+
+			ctx := context.Background()
+			var asset *BioIPAsset
+			var err error
+			if tt.to == 0 {
+				asset, err = m.GetBioIPByContentHash(ctx, simChain, biocid.ContentHash{tt.contentHash})
+			} else {
+				asset, err = m.GetBioIPByContentHashInRange(ctx, simChain, biocid.ContentHash{tt.contentHash}, tt.from, tt.to)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lookup failed: %v", err)
+			}
+			if asset.TokenID.Int64() != tt.want {
+				t.Errorf("got token %s, want %d", asset.TokenID, tt.want)
+			}
+		})
+	}
+
+	if _, err := m.GetBioIPByContentHashInRange(context.Background(), simChain, biocid.ContentHash{1}, 5, 4); err == nil {
+		t.Error("expected an inverted block range to be rejected")
+	}
+}
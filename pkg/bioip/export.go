@@ -0,0 +1,143 @@
+package bioip
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// dotEscaper escapes strings for use inside double-quoted DOT IDs
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// ExportLineageDOT writes a lineage tree as a Graphviz DOT digraph
+// Nodes are labeled with token ID, data type, and generation; edges point parent→child
+// A token reached by several paths is drawn once with an edge from each parent
+// A nil root produces an empty digraph
+func ExportLineageDOT(root *LineageNode, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph lineage {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+
+	if root != nil {
+		written := make(map[string]bool)
+		writeDOTNode(bw, root, written)
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write DOT: %w", err)
+	}
+	return nil
+}
+
+// writeDOTNode writes node, its outgoing edges, and its subtree
+func writeDOTNode(w io.Writer, node *LineageNode, written map[string]bool) {
+	id := dotNodeID(node)
+	if written[id] {
+		return
+	}
+	written[id] = true
+
+	label := fmt.Sprintf("#%s\n%s\ngen %s", node.TokenID, node.DataType, node.Generation)
+	fmt.Fprintf(w, "\t\"%s\" [label=\"%s\"];\n", dotEscaper.Replace(id), dotEscaper.Replace(label))
+
+	for _, child := range node.Children {
+		if child == nil {
+			continue
+		}
+		fmt.Fprintf(w, "\t\"%s\" -> \"%s\";\n", dotEscaper.Replace(id), dotEscaper.Replace(dotNodeID(child)))
+	}
+
+	// A stub's token is written where it was expanded
+	for _, child := range node.Children {
+		if child != nil && !child.AlreadyExpanded {
+			writeDOTNode(w, child, written)
+		}
+	}
+}
+
+// dotNodeID returns the DOT node ID for a lineage node
+func dotNodeID(node *LineageNode) string {
+	return "token_" + node.TokenID.String()
+}
+
+// lineageNodeJSON is the wire form of a LineageNode
+type lineageNodeJSON struct {
+	TokenID         string         `json:"tokenId"`
+	BioCID          string         `json:"bioCID"`
+	DataType        string         `json:"dataType"`
+	Generation      string         `json:"generation"`
+	Children        []*LineageNode `json:"children"`
+	CycleDetected   bool           `json:"cycleDetected,omitempty"`
+	Truncated       bool           `json:"truncated,omitempty"`
+	AlreadyExpanded bool           `json:"alreadyExpanded,omitempty"`
+}
+
+// MarshalJSON encodes the node with children sorted by TokenID ascending
+// Numbers are rendered as decimal strings and BioCID as 0x-prefixed hex, so output is stable
+// across RPC providers that return children in different orders
+func (n *LineageNode) MarshalJSON() ([]byte, error) {
+	children := make([]*LineageNode, 0, len(n.Children))
+	for _, child := range n.Children {
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return compareTokenIDs(children[i], children[j]) < 0
+	})
+
+	return json.Marshal(lineageNodeJSON{
+		TokenID:         bigString(n.TokenID),
+		BioCID:          hexutil.Encode(n.BioCID[:]),
+		DataType:        n.DataType,
+		Generation:      bigString(n.Generation),
+		Children:        children,
+		CycleDetected:   n.CycleDetected,
+		Truncated:       n.Truncated,
+		AlreadyExpanded: n.AlreadyExpanded,
+	})
+}
+
+// ExportLineageJSON encodes a lineage tree as deterministic JSON
+// A nil root encodes as null
+func ExportLineageJSON(root *LineageNode) ([]byte, error) {
+	if root == nil {
+		return []byte("null"), nil
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lineage: %w", err)
+	}
+	return data, nil
+}
+
+// compareTokenIDs orders lineage nodes by TokenID, with nil IDs first
+func compareTokenIDs(a, b *LineageNode) int {
+	switch {
+	case a.TokenID == nil && b.TokenID == nil:
+		return 0
+	case a.TokenID == nil:
+		return -1
+	case b.TokenID == nil:
+		return 1
+	}
+	return a.TokenID.Cmp(b.TokenID)
+}
+
+// bigString renders a possibly-nil big.Int as a decimal string
+func bigString(x *big.Int) string {
+	if x == nil {
+		return "0"
+	}
+	return x.String()
+}
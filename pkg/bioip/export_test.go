@@ -0,0 +1,122 @@
+package bioip
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// lineageNode builds a lineage node for export tests
+func lineageNode(tokenID int64, dataType string, generation int64, children ...*LineageNode) *LineageNode {
+	return &LineageNode{
+		TokenID:    big.NewInt(tokenID),
+		BioCID:     [32]byte{0xb1, byte(tokenID)},
+		DataType:   dataType,
+		Generation: big.NewInt(generation),
+		Children:   children,
+	}
+}
+
+// diamondTree is 1 → {2, 3} → 4, with 4 expanded under 2 and stubbed under 3
+func diamondTree() *LineageNode {
+	four := lineageNode(4, "vcf", 2)
+	stub := four.expandedStub()
+	return lineageNode(1, "fastq", 0,
+		lineageNode(2, "bam", 1, four),
+		lineageNode(3, `cram "v3"`+"\nlossy", 1, stub),
+	)
+}
+
+const diamondDOT = `digraph lineage {
+	node [shape=box];
+	"token_1" [label="#1\nfastq\ngen 0"];
+	"token_1" -> "token_2";
+	"token_1" -> "token_3";
+	"token_2" [label="#2\nbam\ngen 1"];
+	"token_2" -> "token_4";
+	"token_4" [label="#4\nvcf\ngen 2"];
+	"token_3" [label="#3\ncram \"v3\"\nlossy\ngen 1"];
+	"token_3" -> "token_4";
+}
+`
+
+func TestExportLineageDOT(t *testing.T) {
+	tests := []struct {
+		name string
+		root *LineageNode
+		want string
+	}{
+		{name: "diamond", root: diamondTree(), want: diamondDOT},
+		{name: "single node", root: lineageNode(7, "vcf", 0), want: "digraph lineage {\n\tnode [shape=box];\n\t\"token_7\" [label=\"#7\\nvcf\\ngen 0\"];\n}\n"},
+		{name: "nil root", root: nil, want: "digraph lineage {\n\tnode [shape=box];\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportLineageDOT(tt.root, &buf); err != nil {
+				t.Fatalf("ExportLineageDOT failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter rejects every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("closed pipe")
+}
+
+func TestExportLineageDOTWriteError(t *testing.T) {
+	if err := ExportLineageDOT(diamondTree(), failingWriter{}); err == nil {
+		t.Error("expected the write error to be returned")
+	}
+}
+
+func TestExportLineageJSON(t *testing.T) {
+	scrambled := lineageNode(1, "fastq", 0,
+		lineageNode(10, "vcf", 1),
+		lineageNode(2, "bam", 1, lineageNode(5, "vcf", 2), lineageNode(4, "vcf", 2)),
+		nil,
+		lineageNode(3, "cram", 1),
+	)
+	scrambled.CycleDetected = true
+
+	want := `{"tokenId":"1","bioCID":"0xb101000000000000000000000000000000000000000000000000000000000000","dataType":"fastq","generation":"0","children":[` +
+		`{"tokenId":"2","bioCID":"0xb102000000000000000000000000000000000000000000000000000000000000","dataType":"bam","generation":"1","children":[` +
+		`{"tokenId":"4","bioCID":"0xb104000000000000000000000000000000000000000000000000000000000000","dataType":"vcf","generation":"2","children":[]},` +
+		`{"tokenId":"5","bioCID":"0xb105000000000000000000000000000000000000000000000000000000000000","dataType":"vcf","generation":"2","children":[]}]},` +
+		`{"tokenId":"3","bioCID":"0xb103000000000000000000000000000000000000000000000000000000000000","dataType":"cram","generation":"1","children":[]},` +
+		`{"tokenId":"10","bioCID":"0xb10a000000000000000000000000000000000000000000000000000000000000","dataType":"vcf","generation":"1","children":[]}],` +
+		`"cycleDetected":true}`
+
+	got, err := ExportLineageJSON(scrambled)
+	if err != nil {
+		t.Fatalf("ExportLineageJSON failed: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Reversing the on-chain order does not change the output
+	children := scrambled.Children
+	for i, j := 0, len(children)-1; i < j; i, j = i+1, j-1 {
+		children[i], children[j] = children[j], children[i]
+	}
+	again, err := ExportLineageJSON(scrambled)
+	if err != nil || !bytes.Equal(again, got) {
+		t.Errorf("output changed with child order:\n%s", again)
+	}
+
+	if children[0] == nil || children[0].TokenID.Int64() != 3 {
+		t.Error("MarshalJSON reordered the caller's children")
+	}
+
+	if null, err := ExportLineageJSON(nil); err != nil || string(null) != "null" {
+		t.Errorf("nil root = %s, %v", null, err)
+	}
+}
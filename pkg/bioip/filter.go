@@ -0,0 +1,25 @@
+package bioip
+
+// FilterByDataType returns a pruned copy of the tree keeping only nodes of dataType
+// and the ancestors needed to connect them to root
+// Returns nil if no node in the tree matches; the input tree is not modified
+func FilterByDataType(root *LineageNode, dataType string) *LineageNode {
+	if root == nil {
+		return nil
+	}
+
+	var children []*LineageNode
+	for _, child := range root.Children {
+		if kept := FilterByDataType(child, dataType); kept != nil {
+			children = append(children, kept)
+		}
+	}
+
+	if root.DataType != dataType && len(children) == 0 {
+		return nil
+	}
+
+	pruned := *root
+	pruned.Children = children
+	return &pruned
+}
@@ -0,0 +1,44 @@
+package bioip
+
+import "testing"
+
+// mixedTree is a fastq root with bam, vcf and cram derivatives
+func mixedTree() *LineageNode {
+	return lineageNode(1, "fastq", 0,
+		lineageNode(2, "bam", 1,
+			lineageNode(4, "vcf", 2),
+			lineageNode(5, "cram", 2),
+		),
+		lineageNode(3, "vcf", 1),
+		lineageNode(6, "bam", 1,
+			lineageNode(7, "bam", 2),
+		),
+	)
+}
+
+func TestFilterByDataType(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     *LineageNode
+		dataType string
+		want     string
+	}{
+		{name: "leaves kept with connecting ancestors", root: mixedTree(), dataType: "vcf", want: "1(2(4) 3)"},
+		{name: "inner and leaf matches", root: mixedTree(), dataType: "bam", want: "1(2 6(7))"},
+		{name: "single deep match", root: mixedTree(), dataType: "cram", want: "1(2(5))"},
+		{name: "root only", root: mixedTree(), dataType: "fastq", want: "1"},
+		{name: "no match", root: mixedTree(), dataType: "bcf", want: "<nil>"},
+		{name: "nil tree", dataType: "vcf", want: "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := treeShape(tt.root)
+			if got := treeShape(FilterByDataType(tt.root, tt.dataType)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if after := treeShape(tt.root); after != before {
+				t.Errorf("input changed from %s to %s", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package bioip
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+)
+
+// Equal reports whether two assets hold the same on-chain values
+// Numeric fields are compared by value, so distinct big.Int pointers are equal
+func (a *BioIPAsset) Equal(other *BioIPAsset) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+
+	if len(a.ChildTokenIDs) != len(other.ChildTokenIDs) {
+		return false
+	}
+	for i := range a.ChildTokenIDs {
+		if !bigEqual(a.ChildTokenIDs[i], other.ChildTokenIDs[i]) {
+			return false
+		}
+	}
+
+	return a.Owner == other.Owner &&
+		bigEqual(a.TokenID, other.TokenID) &&
+		a.ConsentState == other.ConsentState &&
+		bigEqual(a.CreatedAt, other.CreatedAt) &&
+		bigEqual(a.RevokedAt, other.RevokedAt) &&
+		a.ContentHash == other.ContentHash &&
+		a.DataType == other.DataType &&
+		bigEqual(a.DataSize, other.DataSize) &&
+		a.BioCID == other.BioCID &&
+		a.IPAssetID == other.IPAssetID &&
+		bigEqual(a.LicenseTermsID, other.LicenseTermsID) &&
+		a.HasLicense == other.HasLicense &&
+		bigEqual(a.ParentTokenID, other.ParentTokenID) &&
+		bigEqual(a.Generation, other.Generation) &&
+		bigEqual(a.LicenseTokenID, other.LicenseTokenID)
+}
+
+// String returns a compact summary of the asset for logging
+func (a *BioIPAsset) String() string {
+	if a == nil {
+		return "BioIPAsset(nil)"
+	}
+
+	return fmt.Sprintf("BioIPAsset(token=%s owner=%s type=%s gen=%s parent=%s children=%d license=%t)",
+		a.TokenID, a.Owner.Hex(), a.DataType, a.Generation, a.ParentTokenID, len(a.ChildTokenIDs), a.HasLicense)
+}
+
+// ToBioCID returns the unsigned BioCID for the asset as minted in collection on chain
+// Chain and collection are normalized as by NewBioCID; a nil TokenID is taken as token 0
+func (a *BioIPAsset) ToBioCID(chain biocid.Chain, collection string) *biocid.BioCID {
+	b := &biocid.BioCID{
+		Version:     "v1",
+		Chain:       chain,
+		Collection:  collection,
+		TokenID:     bigString(a.TokenID),
+		ContentHash: a.ContentHash.Hex(),
+	}
+	b.Normalize()
+	return b
+}
+
+// BiofsURI returns the biofs:// URI of path within the asset as minted in collection on chain
+// The result matches ToBiofsURI on the asset's BioCID (see ToBioCID)
+func (a *BioIPAsset) BiofsURI(chain biocid.Chain, collection, path string) string {
+	return a.ToBioCID(chain, collection).ToBiofsURI(path)
+}
+
+// Equal reports whether two license tokens hold the same on-chain values
+func (t *LicenseToken) Equal(other *LicenseToken) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+
+	return bigEqual(t.TokenID, other.TokenID) &&
+		bigEqual(t.ParentTokenID, other.ParentTokenID) &&
+		t.MintedFor == other.MintedFor &&
+		bigEqual(t.MintedAt, other.MintedAt) &&
+		t.Consumed == other.Consumed &&
+		bigEqual(t.ConsumedBy, other.ConsumedBy)
+}
+
+// String returns a compact summary of the license token for logging
+func (t *LicenseToken) String() string {
+	if t == nil {
+		return "LicenseToken(nil)"
+	}
+
+	if t.Consumed {
+		return fmt.Sprintf("LicenseToken(token=%s parent=%s for=%s consumedBy=%s)",
+			t.TokenID, t.ParentTokenID, t.MintedFor.Hex(), t.ConsumedBy)
+	}
+	return fmt.Sprintf("LicenseToken(token=%s parent=%s for=%s available)",
+		t.TokenID, t.ParentTokenID, t.MintedFor.Hex())
+}
+
+// bigEqual compares two possibly-nil big.Ints by value
+func bigEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,180 @@
+package bioip
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBioIPAssetEqual(t *testing.T) {
+	base := func() *BioIPAsset {
+		raw := testAsset(4, 2, 5, 6)
+		return raw.toAsset()
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *BioIPAsset)
+		want   bool
+	}{
+		{name: "identical", modify: func(a *BioIPAsset) {}, want: true},
+		{
+			name: "equal values in distinct big.Ints",
+			modify: func(a *BioIPAsset) {
+				// A non-normalized big.Int with a leading zero word still equals 1024
+				a.DataSize = new(big.Int).SetBits([]big.Word{1024, 0})
+				a.TokenID = new(big.Int).Set(a.TokenID)
+				a.ChildTokenIDs = []*big.Int{big.NewInt(5), big.NewInt(6)}
+			},
+			want: true,
+		},
+		{name: "token ID", modify: func(a *BioIPAsset) { a.TokenID = big.NewInt(9) }, want: false},
+		{name: "nil parent", modify: func(a *BioIPAsset) { a.ParentTokenID = nil }, want: false},
+		{name: "content hash", modify: func(a *BioIPAsset) { a.ContentHash[31] = 1 }, want: false},
+		{name: "owner", modify: func(a *BioIPAsset) { a.Owner = common.Address{1} }, want: false},
+		{name: "fewer children", modify: func(a *BioIPAsset) { a.ChildTokenIDs = a.ChildTokenIDs[:1] }, want: false},
+		{name: "child order", modify: func(a *BioIPAsset) { a.ChildTokenIDs[0], a.ChildTokenIDs[1] = a.ChildTokenIDs[1], a.ChildTokenIDs[0] }, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := base(), base()
+			tt.modify(b)
+			if got := a.Equal(b); got != tt.want {
+				t.Errorf("Equal = %v, want %v", got, tt.want)
+			}
+			if got := b.Equal(a); got != tt.want {
+				t.Errorf("reversed Equal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var nilAsset *BioIPAsset
+	if !nilAsset.Equal(nil) || nilAsset.Equal(base()) || base().Equal(nil) {
+		t.Error("nil assets should only equal each other")
+	}
+}
+
+func TestLicenseTokenEqual(t *testing.T) {
+	wallet := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	a := testLicense(50, 1, wallet)
+	b := testLicense(50, 1, wallet)
+	b.ParentTokenId = new(big.Int).SetBits([]big.Word{1, 0})
+
+	if !a.toLicenseToken().Equal(b.toLicenseToken()) {
+		t.Error("expected tokens with equal values to be equal")
+	}
+
+	b.Consumed = true
+	if a.toLicenseToken().Equal(b.toLicenseToken()) {
+		t.Error("expected a consumed token to differ")
+	}
+}
+
+func TestFormatStrings(t *testing.T) {
+	wallet := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	consumed := testLicense(51, 1, wallet)
+	consumed.Consumed = true
+	consumed.ConsumedBy = big.NewInt(3)
+	asset := testAsset(4, 2, 5, 6)
+	available := testLicense(50, 1, wallet)
+
+	var nilAsset *BioIPAsset
+	var nilLicense *LicenseToken
+
+	tests := []struct {
+		name string
+		got  string
+		want []string
+	}{
+		{name: "asset", got: asset.toAsset().String(), want: []string{"token=4", "parent=2", "children=2", "type=vcf", wallet.Hex()}},
+		{name: "available license", got: available.toLicenseToken().String(), want: []string{"token=50", "parent=1", "available"}},
+		{name: "consumed license", got: consumed.toLicenseToken().String(), want: []string{"token=51", "consumedBy=3"}},
+		{name: "nil asset", got: nilAsset.String(), want: []string{"BioIPAsset(nil)"}},
+		{name: "nil license", got: nilLicense.String(), want: []string{"LicenseToken(nil)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.got, want) {
+					t.Errorf("%q does not contain %q", tt.got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBiofsURI(t *testing.T) {
+	raw := testAsset(4, 2)
+	minted := raw.toAsset()
+	hash := minted.ContentHash.Hex()
+	const checksummed = "0xC91940118822d247B46d1eba6b7Ed2a16F3adc36"
+
+	tests := []struct {
+		name       string
+		asset      *BioIPAsset
+		chain      biocid.Chain
+		collection string
+		path       string
+		bioCID     string // Parsed; its ToBiofsURI must match the asset's BiofsURI
+		want       string
+	}{
+		{
+			name:       "minted asset",
+			asset:      minted,
+			chain:      simChain,
+			collection: simRegistry.Hex(),
+			path:       "/sample.vcf",
+			bioCID:     "biocid://v1/story/" + simRegistry.Hex() + "/4/" + hash,
+			want:       "biofs://story/" + simRegistry.Hex() + "/4/sample.vcf",
+		},
+		{
+			name:       "normalized chain and collection",
+			asset:      minted,
+			chain:      "Story",
+			collection: strings.ToLower(checksummed),
+			path:       "/sample.vcf",
+			bioCID:     "biocid://v1/Story/" + strings.ToLower(checksummed) + "/4/" + hash,
+			want:       "biofs://story/" + checksummed + "/4/sample.vcf",
+		},
+		{
+			name:       "escaped path",
+			asset:      minted,
+			chain:      simChain,
+			collection: simRegistry.Hex(),
+			path:       "/runs/a b#1.bam",
+			bioCID:     "biocid://v1/story/" + simRegistry.Hex() + "/4/" + hash,
+			want:       "biofs://story/" + simRegistry.Hex() + "/4/runs/a%20b%231.bam",
+		},
+		{
+			name:       "zero-value token ID",
+			asset:      &BioIPAsset{},
+			chain:      simChain,
+			collection: simRegistry.Hex(),
+			path:       "/",
+			bioCID:     "biocid://v1/story/" + simRegistry.Hex() + "/0/" + biocid.ContentHash{}.Hex(),
+			want:       "biofs://story/" + simRegistry.Hex() + "/0/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := biocid.ParseBioCID(tt.bioCID)
+			if err != nil {
+				t.Fatalf("ParseBioCID failed: %v", err)
+			}
+			if got := tt.asset.ToBioCID(tt.chain, tt.collection); !got.Equal(b) || got.IsSigned() {
+				t.Errorf("ToBioCID = %s, want %s", got, b)
+			}
+
+			got := tt.asset.BiofsURI(tt.chain, tt.collection, tt.path)
+			if want := b.ToBiofsURI(tt.path); got != want {
+				t.Errorf("got %s, BioCID gives %s", got, want)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
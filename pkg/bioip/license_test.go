@@ -0,0 +1,252 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testLicense is an unconsumed license token minted by parent for wallet
+func testLicense(tokenID, parent int64, wallet common.Address) registryLicenseToken {
+	return registryLicenseToken{
+		TokenId:       big.NewInt(tokenID),
+		ParentTokenId: big.NewInt(parent),
+		MintedFor:     wallet,
+		MintedAt:      big.NewInt(1700000000),
+		ConsumedBy:    new(big.Int),
+	}
+}
+
+// zeroLicense is the tuple getLicenseToken returns for an unminted license token
+func zeroLicense() registryLicenseToken {
+	return registryLicenseToken{
+		TokenId:       new(big.Int),
+		ParentTokenId: new(big.Int),
+		MintedAt:      new(big.Int),
+		ConsumedBy:    new(big.Int),
+	}
+}
+
+func TestRegisterDerivative(t *testing.T) {
+	reg := newSimRegistry()
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+
+	consumed := testLicense(51, 1, signer.From)
+	consumed.Consumed = true
+	consumed.ConsumedBy = big.NewInt(3)
+
+	reg.On("getLicenseToken").Returns(zeroLicense())
+	reg.On("getLicenseToken", big.NewInt(50)).Returns(testLicense(50, 1, signer.From))
+	reg.On("getLicenseToken", big.NewInt(51)).Returns(consumed)
+	reg.On("getLicenseToken", big.NewInt(52)).Returns(testLicense(52, 9, signer.From))
+	reg.On("getLicenseToken", big.NewInt(53)).Returns(testLicense(53, 1, chain.Address(1)))
+	reg.On("registerDerivative")
+	chain.Apply(t, reg)
+
+	tests := []struct {
+		name    string
+		license int64
+		wantErr error
+	}{
+		{name: "fresh", license: 50},
+		{name: "consumed", license: 51, wantErr: ErrLicenseConsumed},
+		{name: "other parent", license: 52, wantErr: ErrLicenseParentMismatch},
+		{name: "other wallet", license: 53, wantErr: ErrLicenseNotForSigner},
+		{name: "unminted", license: 99, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			before, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+
+			err = m.RegisterDerivative(ctx, simChain, big.NewInt(3), big.NewInt(1), big.NewInt(tt.license), signer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			after, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+			sent := after > before
+			if want := tt.wantErr == nil; sent != want {
+				t.Errorf("transaction sent = %v, want %v", sent, want)
+			}
+		})
+	}
+}
+
+func TestGetAvailableLicenseTokens(t *testing.T) {
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	reg := newSimRegistry()
+	reg.On("mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(2)).
+		Emits("LicenseTokenMinted", big.NewInt(50), big.NewInt(1), receiver).
+		Emits("LicenseTokenMinted", big.NewInt(51), big.NewInt(1), receiver)
+	reg.On("mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(1)).
+		Emits("LicenseTokenMinted", big.NewInt(52), big.NewInt(1), receiver)
+	reg.On("mintLicenseTokens", big.NewInt(2), receiver, big.NewInt(1)).
+		Emits("LicenseTokenMinted", big.NewInt(60), big.NewInt(2), receiver)
+	reg.On("registerDerivative", big.NewInt(3), big.NewInt(51)).
+		Emits("LicenseTokenConsumed", big.NewInt(51), big.NewInt(3))
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+
+	transactSim(t, chain, "mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(2))
+	transactSim(t, chain, "mintLicenseTokens", big.NewInt(2), receiver, big.NewInt(1))
+	transactSim(t, chain, "registerDerivative", big.NewInt(3), big.NewInt(51))
+	later := transactSim(t, chain, "mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(1))
+
+	tests := []struct {
+		name      string
+		parent    int64
+		fromBlock uint64
+		want      []int64
+	}{
+		{name: "consumed dropped", parent: 1, want: []int64{50, 52}},
+		{name: "other parent", parent: 2, want: []int64{60}},
+		{name: "bounded scan", parent: 1, fromBlock: later, want: []int64{52}},
+		{name: "none minted", parent: 9, want: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.GetAvailableLicenseTokens(context.Background(), simChain, big.NewInt(tt.parent), tt.fromBlock)
+			if err != nil {
+				t.Fatalf("GetAvailableLicenseTokens failed: %v", err)
+			}
+			if !reflect.DeepEqual(tokenInts(got), tt.want) {
+				t.Errorf("got %v, want %v", tokenInts(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLicenseTokenReconciled(t *testing.T) {
+	wallet := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	consumed := testLicense(52, 1, wallet)
+	consumed.Consumed = true
+	consumed.ConsumedBy = big.NewInt(4)
+
+	// The view lags: it reports 50 and 51 unconsumed although registerDerivative consumed them
+	reg := newSimRegistry()
+	reg.On("getLicenseToken", big.NewInt(50)).Returns(testLicense(50, 1, wallet))
+	reg.On("getLicenseToken", big.NewInt(51)).Returns(testLicense(51, 1, wallet))
+	reg.On("getLicenseToken", big.NewInt(52)).Returns(consumed)
+	reg.On("getLicenseToken", big.NewInt(53)).Returns(testLicense(53, 1, wallet))
+	reg.On("registerDerivative", big.NewInt(2), big.NewInt(50)).
+		Emits("LicenseTokenConsumed", big.NewInt(50), big.NewInt(2))
+	reg.On("registerDerivative", big.NewInt(3), big.NewInt(51)).
+		Emits("LicenseTokenConsumed", big.NewInt(51), big.NewInt(3))
+	reg.On("mintLicenseTokens", big.NewInt(1), wallet, big.NewInt(1))
+	chain := simchain.New(t, reg)
+
+	transactSim(t, chain, "registerDerivative", big.NewInt(2), big.NewInt(50))
+	for i := 0; i < 3; i++ {
+		transactSim(t, chain, "mintLicenseTokens", big.NewInt(1), wallet, big.NewInt(1))
+	}
+	transactSim(t, chain, "registerDerivative", big.NewInt(3), big.NewInt(51))
+
+	tests := []struct {
+		name         string
+		lookback     uint64
+		license      int64
+		wantConsumed bool
+		wantBy       int64
+	}{
+		{name: "reconciliation off", license: 51},
+		{name: "recent event", lookback: 2, license: 51, wantConsumed: true, wantBy: 3},
+		{name: "event before the lookback", lookback: 2, license: 50},
+		{name: "long lookback", lookback: 100, license: 50, wantConsumed: true, wantBy: 2},
+		{name: "view already consumed", lookback: 100, license: 52, wantConsumed: true, wantBy: 4},
+		{name: "no event", lookback: 100, license: 53},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewBioIPManager(
+				WithBackend(simChain, chain.Miner()),
+				WithRegistry(simChain, simRegistry),
+				WithEventReconciliation(tt.lookback),
+			)
+			defer m.Close()
+
+			got, err := m.GetLicenseToken(context.Background(), simChain, big.NewInt(tt.license))
+			if err != nil {
+				t.Fatalf("GetLicenseToken failed: %v", err)
+			}
+			if got.Consumed != tt.wantConsumed {
+				t.Fatalf("consumed = %v, want %v", got.Consumed, tt.wantConsumed)
+			}
+			if tt.wantConsumed && got.ConsumedBy.Int64() != tt.wantBy {
+				t.Errorf("consumed by %s, want %d", got.ConsumedBy, tt.wantBy)
+			}
+		})
+	}
+}
+
+// spanLimitBackend rejects log queries spanning more than maxSpan blocks, like public RPCs do
+type spanLimitBackend struct {
+	chainrpc.Backend
+	maxSpan uint64
+
+	mu    sync.Mutex
+	spans int // Accepted queries
+}
+
+func (b *spanLimitBackend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.ToBlock.Uint64()-q.FromBlock.Uint64()+1 > b.maxSpan {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	b.mu.Lock()
+	b.spans++
+	b.mu.Unlock()
+	return b.Backend.FilterLogs(ctx, q)
+}
+
+func TestGetAvailableLicenseTokensNarrowsSpans(t *testing.T) {
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	reg := newSimRegistry()
+	reg.On("mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(1)).
+		Emits("LicenseTokenMinted", big.NewInt(50), big.NewInt(1), receiver)
+	reg.On("mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(2)).
+		Emits("LicenseTokenMinted", big.NewInt(51), big.NewInt(1), receiver)
+	reg.On("registerDerivative", big.NewInt(3), big.NewInt(50)).
+		Emits("LicenseTokenConsumed", big.NewInt(50), big.NewInt(3))
+	chain := simchain.New(t, reg)
+
+	transactSim(t, chain, "mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(1))
+	for i := 0; i < 5; i++ {
+		chain.Backend.Commit()
+	}
+	transactSim(t, chain, "mintLicenseTokens", big.NewInt(1), receiver, big.NewInt(2))
+	transactSim(t, chain, "registerDerivative", big.NewInt(3), big.NewInt(50))
+
+	backend := &spanLimitBackend{Backend: chain.Miner(), maxSpan: 2}
+	m := NewBioIPManager(
+		WithBackend(simChain, backend),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	)
+	defer m.Close()
+
+	got, err := m.GetAvailableLicenseTokens(context.Background(), simChain, big.NewInt(1), 0)
+	if err != nil {
+		t.Fatalf("GetAvailableLicenseTokens failed: %v", err)
+	}
+	if want := []int64{51}; !reflect.DeepEqual(tokenInts(got), want) {
+		t.Errorf("got %v, want %v", tokenInts(got), want)
+	}
+	if backend.spans < 2 {
+		t.Errorf("history scanned in %d queries, want it split", backend.spans)
+	}
+}
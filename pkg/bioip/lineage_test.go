@@ -0,0 +1,498 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum"
+)
+
+// tokenInts converts token IDs to int64s for comparison
+func tokenInts(ids []*big.Int) []int64 {
+	out := []int64{}
+	for _, id := range ids {
+		out = append(out, id.Int64())
+	}
+	return out
+}
+
+func TestGetLineage(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		testAsset(1, 0, 2),
+		testAsset(2, 1, 3),
+		testAsset(3, 2, 4),
+		testAsset(4, 3),
+		// 10 and 11 name each other as parents
+		testAsset(10, 11),
+		testAsset(11, 10),
+		// 20's parent was never minted
+		testAsset(20, 21),
+	)
+
+	tests := []struct {
+		name     string
+		tokenID  int64
+		maxDepth int
+		want     []int64
+		wantErr  error
+	}{
+		{name: "root", tokenID: 1, want: []int64{}},
+		{name: "great-grandchild", tokenID: 4, want: []int64{3, 2, 1}},
+		{name: "within max depth", tokenID: 4, maxDepth: 3, want: []int64{3, 2, 1}},
+		{name: "beyond max depth", tokenID: 4, maxDepth: 2, wantErr: ErrLineageTooDeep},
+		{name: "cycle", tokenID: 10, wantErr: ErrLineageCycle},
+		{name: "missing parent", tokenID: 20, wantErr: ErrTokenNotFound},
+		{name: "missing token", tokenID: 99, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.maxDepth > 0 {
+				opts = append(opts, WithMaxDepth(tt.maxDepth))
+			}
+			m, _ := newSimManager(t, []*simchain.Contract{reg}, opts...)
+
+			got, err := m.GetLineage(context.Background(), simChain, big.NewInt(tt.tokenID))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetLineage failed: %v", err)
+			}
+			if !reflect.DeepEqual(tokenInts(got), tt.want) {
+				t.Errorf("got %v, want %v", tokenInts(got), tt.want)
+			}
+		})
+	}
+}
+
+// treeShape renders a lineage tree as nested token IDs, marking stubs with a trailing *
+func treeShape(n *LineageNode) string {
+	if n == nil {
+		return "<nil>"
+	}
+	s := n.TokenID.String()
+	if n.AlreadyExpanded {
+		s += "*"
+	}
+	if len(n.Children) == 0 {
+		return s
+	}
+	s += "("
+	for i, child := range n.Children {
+		if i > 0 {
+			s += " "
+		}
+		s += treeShape(child)
+	}
+	return s + ")"
+}
+
+func TestGetLineageTreeRepeats(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		// Diamond: 4 derives from both 2 and 3
+		testAsset(1, 0, 2, 3),
+		testAsset(2, 1, 4),
+		testAsset(3, 1, 4),
+		testAsset(4, 2, 5),
+		testAsset(5, 4),
+		// Cycle: 12 lists its ancestor 10 as a child
+		testAsset(10, 0, 11),
+		testAsset(11, 10, 12),
+		testAsset(12, 11, 10),
+		// Self-loop
+		testAsset(20, 0, 20),
+	)
+	modes := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "sequential"},
+		{name: "batched", opts: []Option{WithBatchLineage(true)}},
+		{name: "concurrent", opts: []Option{WithLineageConcurrency(4)}},
+	}
+
+	tests := []struct {
+		name      string
+		root      int64
+		want      string
+		wantCycle bool
+		wantCount int
+	}{
+		{name: "diamond", root: 1, want: "1(2(4(5)) 3(4*))", wantCount: 4},
+		{name: "cycle", root: 10, want: "10(11(12))", wantCycle: true, wantCount: 2},
+		{name: "self-loop", root: 20, want: "20", wantCycle: true, wantCount: 0},
+	}
+	for _, mode := range modes {
+		m, _ := newSimManager(t, []*simchain.Contract{reg}, mode.opts...)
+		for _, tt := range tests {
+			t.Run(mode.name+"/"+tt.name, func(t *testing.T) {
+				tree, err := m.GetLineageTree(context.Background(), simChain, big.NewInt(tt.root))
+				if err != nil {
+					t.Fatalf("GetLineageTree failed: %v", err)
+				}
+				if got := treeShape(tree); got != tt.want {
+					t.Errorf("got %s, want %s", got, tt.want)
+				}
+				if tree.CycleDetected != tt.wantCycle {
+					t.Errorf("CycleDetected = %v, want %v", tree.CycleDetected, tt.wantCycle)
+				}
+				if got := tree.CountDescendants(); got != tt.wantCount {
+					t.Errorf("CountDescendants = %d, want %d", got, tt.wantCount)
+				}
+			})
+		}
+	}
+}
+
+func TestGetLineageTreeLimited(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		testAsset(1, 0, 2, 3),
+		testAsset(2, 1, 4, 5),
+		testAsset(4, 2, 6),
+		testAsset(5, 2),
+		testAsset(6, 4),
+	)
+	// 3 is listed as a child but fails to load, as does a root of its own
+	reg.On("getBioIP", big.NewInt(3)).Reverts()
+	reg.On("getBioIP", big.NewInt(30)).Reverts()
+	modes := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "sequential"},
+		{name: "batched", opts: []Option{WithBatchLineage(true)}},
+		{name: "concurrent", opts: []Option{WithLineageConcurrency(4)}},
+	}
+
+	tests := []struct {
+		name          string
+		root          int64
+		maxDepth      int
+		maxNodes      int
+		want          string
+		wantTruncated bool
+		wantFailed    []int64 // Tokens reported in Errors, each a child of its predecessor in the tree
+		wantErr       bool
+	}{
+		{name: "unlimited", root: 1, maxDepth: 10, want: "1(2(4(6) 5))", wantFailed: []int64{3}},
+		{name: "depth limit", root: 1, maxDepth: 1, want: "1(2)", wantTruncated: true, wantFailed: []int64{3}},
+		{name: "node limit", root: 1, maxDepth: 10, maxNodes: 2, want: "1(2)", wantTruncated: true},
+		{name: "node limit counts depth first", root: 1, maxDepth: 10, maxNodes: 3, want: "1(2(4))", wantTruncated: true},
+		{name: "subtree", root: 2, maxDepth: 10, want: "2(4(6) 5)"},
+		{name: "root fails", root: 30, maxDepth: 10, wantErr: true},
+	}
+	for _, mode := range modes {
+		m, _ := newSimManager(t, []*simchain.Contract{reg}, mode.opts...)
+		for _, tt := range tests {
+			t.Run(mode.name+"/"+tt.name, func(t *testing.T) {
+				tree, err := m.GetLineageTreeLimited(context.Background(), simChain, big.NewInt(tt.root), tt.maxDepth, tt.maxNodes)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("got %s, want an error", treeShape(tree))
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("GetLineageTreeLimited failed: %v", err)
+				}
+				if got := treeShape(tree); got != tt.want {
+					t.Errorf("got %s, want %s", got, tt.want)
+				}
+				if tree.Truncated != tt.wantTruncated {
+					t.Errorf("Truncated = %v, want %v", tree.Truncated, tt.wantTruncated)
+				}
+
+				failed := []int64{}
+				for _, e := range tree.Errors {
+					failed = append(failed, e.TokenID.Int64())
+					if e.ParentTokenID.Int64() != 1 || e.Err == nil {
+						t.Errorf("error for token %s: parent %s, err %v", e.TokenID, e.ParentTokenID, e.Err)
+					}
+				}
+				if len(tt.wantFailed) == 0 {
+					tt.wantFailed = []int64{}
+				}
+				if !reflect.DeepEqual(failed, tt.wantFailed) {
+					t.Errorf("failed tokens %v, want %v", failed, tt.wantFailed)
+				}
+			})
+		}
+	}
+}
+
+// slowBackend delays each call by delay(tokenID), where tokenID is the call's first argument
+type slowBackend struct {
+	chainrpc.Backend
+	delay func(tokenID int64) time.Duration
+}
+
+func (b *slowBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var tokenID int64
+	if len(call.Data) >= 36 {
+		tokenID = new(big.Int).SetBytes(call.Data[4:36]).Int64()
+	}
+
+	select {
+	case <-time.After(b.delay(tokenID)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// The simulated backend only serves the latest block
+	return b.Backend.CallContract(ctx, call, nil)
+}
+
+// newSlowManager is newSimManager behind a slowBackend
+func newSlowManager(t testing.TB, reg *simchain.Contract, delay func(tokenID int64) time.Duration, opts ...Option) *BioIPManager {
+	t.Helper()
+
+	chain := simchain.New(t, reg)
+	opts = append([]Option{
+		WithBackend(simChain, &slowBackend{Backend: chain.Miner(), delay: delay}),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	}, opts...)
+
+	m := NewBioIPManager(opts...)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// scriptWideTree scripts a tree of 1 + width + width*width tokens below token 1
+func scriptWideTree(reg *simchain.Contract, width int64) {
+	var children []int64
+	for i := int64(0); i < width; i++ {
+		children = append(children, 2+i)
+	}
+	scriptTree(reg, testAsset(1, 0, children...))
+
+	next := 2 + width
+	for _, child := range children {
+		var grandchildren []int64
+		for i := int64(0); i < width; i++ {
+			grandchildren = append(grandchildren, next)
+			scriptTree(reg, testAsset(next, child))
+			next++
+		}
+		scriptTree(reg, testAsset(child, 1, grandchildren...))
+	}
+}
+
+func TestGetLineageTreeOrderIndependent(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		testAsset(1, 0, 2, 3, 4, 5),
+		testAsset(2, 1, 6, 7, 8),
+		testAsset(3, 1, 8),
+		testAsset(4, 1, 9),
+		testAsset(5, 1),
+		testAsset(6, 2),
+		testAsset(7, 2, 10),
+		testAsset(8, 2),
+		testAsset(10, 7),
+	)
+	// 9 is listed as a child but fails to load
+	reg.On("getBioIP", big.NewInt(9)).Reverts()
+
+	// Higher token IDs answer first, so concurrent fetches complete in reverse order
+	reversed := func(tokenID int64) time.Duration { return time.Duration(12-tokenID) * time.Millisecond }
+
+	shape := func(tree *LineageNode) string {
+		s := treeShape(tree)
+		for _, e := range tree.Errors {
+			s += " !" + e.TokenID.String()
+		}
+		return s
+	}
+	sequential, err := newSlowManager(t, reg, reversed).GetLineageTree(context.Background(), simChain, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("sequential GetLineageTree failed: %v", err)
+	}
+	if got, want := shape(sequential), "1(2(6 7(10) 8) 3(8*) 4 5) !9"; got != want {
+		t.Fatalf("sequential tree %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "two workers", opts: []Option{WithLineageConcurrency(2)}},
+		{name: "worker per sibling", opts: []Option{WithLineageConcurrency(8)}},
+		{name: "batched", opts: []Option{WithBatchLineage(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newSlowManager(t, reg, reversed, tt.opts...)
+
+			got, err := m.GetLineageTree(context.Background(), simChain, big.NewInt(1))
+			if err != nil {
+				t.Fatalf("GetLineageTree failed: %v", err)
+			}
+			if shape(got) != shape(sequential) {
+				t.Errorf("got %s, sequential walk gives %s", shape(got), shape(sequential))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := m.GetLineageTree(ctx, simChain, big.NewInt(1)); !errors.Is(err, context.Canceled) {
+				t.Errorf("canceled walk: got %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+// BenchmarkGetLineageTree walks a 43-token tree behind 1ms of latency per call
+// Batched lineage is left out: slowBackend exposes no RPC client, so batches degrade to single calls
+func BenchmarkGetLineageTree(b *testing.B) {
+	reg := newSimRegistry()
+	scriptWideTree(reg, 6)
+	latency := func(int64) time.Duration { return time.Millisecond }
+
+	modes := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "sequential"},
+		{name: "concurrent", opts: []Option{WithLineageConcurrency(8)}},
+	}
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			m := newSlowManager(b, reg, latency, mode.opts...)
+			ctx := context.Background()
+			root := big.NewInt(1)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.GetLineageTree(ctx, simChain, root); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDescendants(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		// Three levels with a diamond: 5 derives from both 3 and 4
+		testAsset(1, 0, 2, 3),
+		testAsset(2, 1, 4),
+		testAsset(3, 1, 5),
+		testAsset(4, 2, 5, 6),
+		testAsset(5, 3),
+		testAsset(6, 4),
+		// 11 lists its parent 10 as a child
+		testAsset(10, 0, 11),
+		testAsset(11, 10, 10),
+		// 20's child was never minted
+		testAsset(20, 0, 21),
+	)
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name           string
+		tokenID        int64
+		maxGenerations int
+		want           []int64
+		wantErr        error
+	}{
+		{name: "leaf", tokenID: 6, want: []int64{}},
+		{name: "ordered by generation", tokenID: 1, want: []int64{2, 3, 4, 5, 6}},
+		{name: "one generation", tokenID: 1, maxGenerations: 1, want: []int64{2, 3}},
+		{name: "two generations", tokenID: 1, maxGenerations: 2, want: []int64{2, 3, 4, 5}},
+		{name: "subtree", tokenID: 2, want: []int64{4, 5, 6}},
+		{name: "cycle", tokenID: 10, want: []int64{11}},
+		{name: "missing child", tokenID: 20, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.GetDescendants(context.Background(), simChain, big.NewInt(tt.tokenID), tt.maxGenerations)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetDescendants failed: %v", err)
+			}
+			if !reflect.DeepEqual(tokenInts(got), tt.want) {
+				t.Errorf("got %v, want %v", tokenInts(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyGeneration(t *testing.T) {
+	generation := func(asset registryBioIPAsset, gen int64) registryBioIPAsset {
+		asset.Generation = big.NewInt(gen)
+		return asset
+	}
+
+	reg := newSimRegistry()
+	scriptTree(reg,
+		// Consistent chain 1 → 2 → 3
+		testAsset(1, 0, 2),
+		testAsset(2, 1, 3),
+		generation(testAsset(3, 2), 2),
+		// 12 repeats its parent's generation
+		testAsset(10, 0, 11),
+		testAsset(11, 10, 12),
+		generation(testAsset(12, 11), 1),
+		// 21 is mislabeled, so the walk from 22 reports it first
+		testAsset(20, 0, 21),
+		generation(testAsset(21, 20, 22), 5),
+		generation(testAsset(22, 21), 7),
+		// A root claiming to be a derivative
+		generation(testAsset(30, 0), 1),
+		// 40 and 41 name each other as parents
+		testAsset(40, 41),
+		testAsset(41, 40),
+	)
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name      string
+		tokenID   int64
+		wantErr   error
+		wantToken string // Token named in the mismatch error
+	}{
+		{name: "consistent", tokenID: 3},
+		{name: "root", tokenID: 1},
+		{name: "same generation as parent", tokenID: 12, wantErr: ErrGenerationMismatch, wantToken: "token 12 "},
+		{name: "first inconsistency from root", tokenID: 22, wantErr: ErrGenerationMismatch, wantToken: "token 21 "},
+		{name: "root with generation", tokenID: 30, wantErr: ErrGenerationMismatch, wantToken: "token 30 "},
+		{name: "cycle", tokenID: 40, wantErr: ErrLineageCycle},
+		{name: "missing", tokenID: 99, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := m.VerifyGeneration(context.Background(), simChain, big.NewInt(tt.tokenID))
+			if tt.wantErr == nil {
+				if err != nil || !ok {
+					t.Fatalf("got %v, %v; want true", ok, err)
+				}
+				return
+			}
+			if ok || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, %v; want false, %v", ok, err, tt.wantErr)
+			}
+			if tt.wantToken != "" && !strings.Contains(err.Error(), tt.wantToken) {
+				t.Errorf("error %q does not name %q", err, tt.wantToken)
+			}
+		})
+	}
+}
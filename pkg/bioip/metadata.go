@@ -0,0 +1,94 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BuildLineageMetadata assembles ancestors and descendants of tokenID into a LineageMetadata
+// Ancestors are ordered parent→root and descendants breadth-first; BioCIDs use the
+// registry as collection and carry no consent signature, which is not stored on-chain
+func (m *BioIPManager) BuildLineageMetadata(
+	ctx context.Context,
+	chain biocid.Chain,
+	tokenID *big.Int,
+) (*biocid.LineageMetadata, error) {
+	collection, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := m.GetLineage(ctx, chain, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ancestors: %w", err)
+	}
+
+	descendants, err := m.GetDescendants(ctx, chain, tokenID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get descendants: %w", err)
+	}
+
+	ids := make([]*big.Int, 0, 1+len(ancestors)+len(descendants))
+	ids = append(ids, tokenID)
+	ids = append(ids, ancestors...)
+	ids = append(ids, descendants...)
+
+	assets, err := m.BatchGetBioIP(ctx, chain, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(id *big.Int) (*biocid.BioCID, error) {
+		asset, ok := assets[id.String()]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, id)
+		}
+		return assetBioCID(chain, collection, asset), nil
+	}
+
+	self, err := lookup(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &biocid.LineageMetadata{
+		Self:        self,
+		Ancestors:   make([]*biocid.BioCID, 0, len(ancestors)),
+		Descendants: make([]*biocid.BioCID, 0, len(descendants)),
+		Generation:  len(ancestors),
+	}
+
+	for _, id := range ancestors {
+		b, err := lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		meta.Ancestors = append(meta.Ancestors, b)
+	}
+
+	for _, id := range descendants {
+		b, err := lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		meta.Descendants = append(meta.Descendants, b)
+	}
+
+	return meta, nil
+}
+
+// assetBioCID builds the BioCID of a registry asset, without a consent signature
+func assetBioCID(chain biocid.Chain, collection common.Address, asset *BioIPAsset) *biocid.BioCID {
+	return &biocid.BioCID{
+		Version:     "v1",
+		Chain:       biocid.Chain(strings.ToLower(chain.String())),
+		Collection:  collection.Hex(),
+		TokenID:     asset.TokenID.String(),
+		ContentHash: asset.ContentHash.Hex(),
+	}
+}
@@ -0,0 +1,92 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+// bioCIDTokens lists the token IDs of bs
+func bioCIDTokens(bs []*biocid.BioCID) []string {
+	out := []string{}
+	for _, b := range bs {
+		out = append(out, b.TokenID)
+	}
+	return out
+}
+
+func TestBuildLineageMetadata(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		testAsset(1, 0, 2),
+		testAsset(2, 1, 3, 5),
+		testAsset(3, 2, 4),
+		testAsset(4, 3),
+		testAsset(5, 2),
+		// 20's child was never minted
+		testAsset(20, 0, 21),
+	)
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name            string
+		tokenID         int64
+		wantAncestors   []string
+		wantDescendants []string
+		wantRoot        string
+		wantParent      string // Empty for no parent
+		wantErr         error
+	}{
+		{name: "root", tokenID: 1, wantAncestors: []string{}, wantDescendants: []string{"2", "3", "5", "4"}, wantRoot: "1"},
+		{name: "middle", tokenID: 3, wantAncestors: []string{"2", "1"}, wantDescendants: []string{"4"}, wantRoot: "1", wantParent: "2"},
+		{name: "leaf", tokenID: 4, wantAncestors: []string{"3", "2", "1"}, wantDescendants: []string{}, wantRoot: "1", wantParent: "3"},
+		{name: "missing token", tokenID: 99, wantErr: ErrTokenNotFound},
+		{name: "missing descendant", tokenID: 20, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := m.BuildLineageMetadata(context.Background(), simChain, big.NewInt(tt.tokenID))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildLineageMetadata failed: %v", err)
+			}
+
+			if meta.Self.TokenID != big.NewInt(tt.tokenID).String() || meta.Self.Collection != simRegistry.Hex() {
+				t.Errorf("unexpected self %+v", meta.Self)
+			}
+			if meta.Self.ContentHash != (biocid.ContentHash{byte(tt.tokenID)}).Hex() {
+				t.Errorf("self content hash = %s", meta.Self.ContentHash)
+			}
+			if got := bioCIDTokens(meta.Ancestors); !reflect.DeepEqual(got, tt.wantAncestors) {
+				t.Errorf("ancestors = %v, want %v", got, tt.wantAncestors)
+			}
+			if got := bioCIDTokens(meta.Descendants); !reflect.DeepEqual(got, tt.wantDescendants) {
+				t.Errorf("descendants = %v, want %v", got, tt.wantDescendants)
+			}
+			if meta.Generation != len(tt.wantAncestors) {
+				t.Errorf("generation = %d, want %d", meta.Generation, len(tt.wantAncestors))
+			}
+
+			if root := meta.GetRoot(); root == nil || root.TokenID != tt.wantRoot {
+				t.Errorf("GetRoot = %+v, want token %s", root, tt.wantRoot)
+			}
+			parent := meta.GetParent()
+			switch {
+			case tt.wantParent == "" && parent != nil:
+				t.Errorf("GetParent = %+v, want nil", parent)
+			case tt.wantParent != "" && (parent == nil || parent.TokenID != tt.wantParent):
+				t.Errorf("GetParent = %+v, want token %s", parent, tt.wantParent)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package bioip
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Option configures a BioIPManager
+type Option func(*BioIPManager)
+
+// WithChainRegistry sets the registry used to resolve chain names to RPC endpoints
+func WithChainRegistry(chains *biocid.ChainRegistry) Option {
+	return func(m *BioIPManager) {
+		if chains != nil {
+			m.chains = chains
+		}
+	}
+}
+
+// WithRegistry sets the BioIPRegistry contract address for a chain
+func WithRegistry(chain biocid.Chain, addr common.Address) Option {
+	return func(m *BioIPManager) {
+		m.registries[chain] = addr
+	}
+}
+
+// WithRegistryStartBlock sets the block a chain's BioIPRegistry was deployed at
+// Scans over the registry's whole history, such as GetBioIPByContentHash, start there instead of block 0
+func WithRegistryStartBlock(chain biocid.Chain, block uint64) Option {
+	return func(m *BioIPManager) {
+		m.startBlocks[chain] = block
+	}
+}
+
+// WithIPAssetRegistry sets the Story Protocol IPAssetRegistry address used by VerifyIPAsset for a chain
+// Story's own deployment is configured for the "story" chain by default
+func WithIPAssetRegistry(chain biocid.Chain, addr common.Address) Option {
+	return func(m *BioIPManager) {
+		m.ipRegistries[chain] = addr
+	}
+}
+
+// WithRetryPolicy sets how transient RPC failures are retried
+func WithRetryPolicy(policy chainrpc.RetryPolicy) Option {
+	return func(m *BioIPManager) {
+		m.retry = policy
+	}
+}
+
+// WithMaxDepth bounds the number of generations walked by lineage queries
+func WithMaxDepth(depth int) Option {
+	return func(m *BioIPManager) {
+		if depth > 0 {
+			m.maxDepth = depth
+		}
+	}
+}
+
+// WithMaxChildren bounds the number of ChildTokenIDs decoded for a single token
+// Tokens reporting more children fail with ErrTooManyChildren
+func WithMaxChildren(n int) Option {
+	return func(m *BioIPManager) {
+		if n > 0 {
+			m.maxChildren = n
+		}
+	}
+}
+
+// WithBatchLineage makes GetLineageTree prefetch each generation with BatchGetBioIP
+func WithBatchLineage(enabled bool) Option {
+	return func(m *BioIPManager) {
+		m.batchLineage = enabled
+	}
+}
+
+// WithLineageConcurrency makes GetLineageTree fetch each generation with up to n concurrent GetBioIP calls
+// The tree is assembled in the same order as a sequential walk, so its shape does not depend on n;
+// n <= 1 fetches sequentially, and WithBatchLineage takes precedence when both are set
+func WithLineageConcurrency(n int) Option {
+	return func(m *BioIPManager) {
+		m.concurrency = n
+	}
+}
+
+// WithDefaultTimeout sets the deadline applied to RPC calls whose context has none
+// A non-positive duration disables the default deadline
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(m *BioIPManager) {
+		m.timeout = d
+	}
+}
+
+// WithWaitMined makes mint calls wait for their receipts and parse minted token IDs from logs
+func WithWaitMined(wait bool) Option {
+	return func(m *BioIPManager) {
+		m.waitReceipts = wait
+	}
+}
+
+// WithEventReconciliation makes GetLicenseToken check LicenseTokenConsumed events from the
+// last lookback blocks, so a token the contract view still reports unconsumed is caught
+func WithEventReconciliation(lookback uint64) Option {
+	return func(m *BioIPManager) {
+		m.reconcile = lookback
+	}
+}
+
+// WithFeeStrategy sets how write methods price transactions on every chain
+func WithFeeStrategy(strategy chainrpc.FeeStrategy) Option {
+	return func(m *BioIPManager) {
+		m.fees = strategy
+	}
+}
+
+// WithChainFeeStrategy overrides the fee strategy for a single chain
+func WithChainFeeStrategy(chain biocid.Chain, strategy chainrpc.FeeStrategy) Option {
+	return func(m *BioIPManager) {
+		m.chainFees[chain] = strategy
+	}
+}
+
+// WithNonceMode sets how CreateDerivativeFlow sequences its transactions
+func WithNonceMode(mode NonceMode) Option {
+	return func(m *BioIPManager) {
+		m.nonces = mode
+	}
+}
+
+// WithCustomDataTypes makes mint methods accept data types without a canonical form
+// By default such types are rejected with datatype.ErrUnknownDataType
+func WithCustomDataTypes(allow bool) Option {
+	return func(m *BioIPManager) {
+		m.customTypes = allow
+	}
+}
+
+// WithRPCEndpoints sends chain's requests to urls instead of its registered RPC URL
+// Endpoints are tried in order; the one in use is kept until it fails to dial or returns
+// chainrpc.DefaultFailoverThreshold transient errors in a row, then the next one takes over
+// Backends injected with WithBackend take precedence; if a URL is invalid, chain's calls fail
+// with chainrpc.ErrInvalidEndpoint
+func WithRPCEndpoints(chain biocid.Chain, urls ...string) Option {
+	return func(m *BioIPManager) {
+		if old, ok := m.failovers[chain]; ok {
+			old.Close()
+			delete(m.failovers, chain)
+		}
+		delete(m.urlErrs, chain)
+
+		failover, err := chainrpc.NewFailover(urls, 0)
+		if err != nil {
+			m.urlErrs[chain] = fmt.Errorf("RPC endpoints for %s: %w", chain, err)
+			return
+		}
+		m.failovers[chain] = failover
+	}
+}
+
+// WithRateLimit paces requests to chain's RPC endpoint to rps per second, with bursts of up to burst
+// Waiting requests give up when their context is done; a non-positive rps removes the limit
+func WithRateLimit(chain biocid.Chain, rps float64, burst int) Option {
+	return func(m *BioIPManager) {
+		if rps <= 0 {
+			delete(m.limiters, chain)
+			return
+		}
+		m.limiters[chain] = chainrpc.NewRateLimiter(rps, burst)
+	}
+}
+
+// WithResolverCache caches BioCIDToBioIP lookups of up to maxEntries tokens (unbounded if non-positive)
+// Immutable fields such as ContentHash, Generation and ParentTokenID are kept for immutableTTL;
+// once volatileTTL has passed, a hit re-reads the token and takes only its Owner, ConsentState,
+// RevokedAt, license and ChildTokenIDs fields, so consent changes surface within volatileTTL
+func WithResolverCache(immutableTTL, volatileTTL time.Duration, maxEntries int) Option {
+	return func(m *BioIPManager) {
+		if immutableTTL > 0 {
+			m.resolver = newResolverCache(immutableTTL, volatileTTL, maxEntries)
+		}
+	}
+}
+
+// WithBackend uses backend for chain instead of dialing its RPC URL
+// Useful for simulated backends in tests; the caller remains responsible for closing it
+func WithBackend(chain biocid.Chain, backend chainrpc.Backend) Option {
+	return func(m *BioIPManager) {
+		m.backends[chain] = backend
+	}
+}
+
+// WithLogger sets the logger receiving RPC, retry, and cache events
+func WithLogger(logger chainrpc.Logger) Option {
+	return func(m *BioIPManager) {
+		if logger != nil {
+			m.logger = logger
+		}
+	}
+}
+
+// WithMetrics sets the sink receiving RPC latency, retry, and consent counters
+func WithMetrics(metrics chainrpc.MetricsSink) Option {
+	return func(m *BioIPManager) {
+		if metrics != nil {
+			m.metrics = metrics
+		}
+	}
+}
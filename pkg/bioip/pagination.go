@@ -0,0 +1,155 @@
+package bioip
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+)
+
+// defaultPageLimit is used by GetDescendantsPage when limit is not positive
+const defaultPageLimit = 100
+
+// descendantsCursor is the BFS state carried between GetDescendantsPage calls
+type descendantsCursor struct {
+	Root   string            `json:"r"`
+	Queue  []descendantsNode `json:"q"` // Emitted tokens whose children are not fully emitted
+	Offset int               `json:"o"` // Children of Queue[0] already considered
+	Seen   []string          `json:"s"` // Root and every token emitted so far
+}
+
+// descendantsNode is a queued token and its generation below the root
+type descendantsNode struct {
+	ID  string `json:"i"`
+	Gen int    `json:"g"`
+}
+
+// GetDescendantsPage returns up to limit descendants of tokenID in breadth-first order
+// Pass the returned nextPageToken to continue; an empty nextPageToken means the walk is done
+// Tokens reachable along several paths (or through a cycle) are returned once
+// The page token encodes the BFS frontier and every token returned so far, so its size grows
+// with the number of descendants already paged through
+func (m *BioIPManager) GetDescendantsPage(
+	ctx context.Context,
+	chain biocid.Chain,
+	tokenID string,
+	pageToken string,
+	limit int,
+) (ids []*big.Int, nextPageToken string, err error) {
+	root, err := biocid.ParseTokenID(tokenID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	cursor := descendantsCursor{
+		Root:  root.String(),
+		Queue: []descendantsNode{{ID: root.String()}},
+		Seen:  []string{root.String()},
+	}
+	if pageToken != "" {
+		cursor, err = decodeDescendantsCursor(pageToken, root.String())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	seen := make(map[string]bool, len(cursor.Seen))
+	for _, id := range cursor.Seen {
+		seen[id] = true
+	}
+
+	ids = []*big.Int{}
+	for len(ids) < limit && len(cursor.Queue) > 0 {
+		head := cursor.Queue[0]
+		if head.Gen >= m.maxDepth {
+			cursor.Queue, cursor.Offset = cursor.Queue[1:], 0
+			continue
+		}
+
+		id, ok := new(big.Int).SetString(head.ID, 10)
+		if !ok {
+			return nil, "", fmt.Errorf("%w: bad token ID %q", ErrInvalidPageToken, head.ID)
+		}
+
+		bioip, err := m.GetBioIP(ctx, chain, id)
+		if err != nil {
+			return nil, "", err
+		}
+
+		children := bioip.ChildTokenIDs
+		if cursor.Offset > len(children) {
+			return nil, "", fmt.Errorf("%w: offset beyond children of %s", ErrInvalidPageToken, head.ID)
+		}
+
+		for cursor.Offset < len(children) && len(ids) < limit {
+			childID := children[cursor.Offset]
+			cursor.Offset++
+			if seen[childID.String()] {
+				continue
+			}
+
+			seen[childID.String()] = true
+			cursor.Seen = append(cursor.Seen, childID.String())
+			ids = append(ids, childID)
+			cursor.Queue = append(cursor.Queue, descendantsNode{ID: childID.String(), Gen: head.Gen + 1})
+		}
+
+		if cursor.Offset == len(children) {
+			cursor.Queue, cursor.Offset = cursor.Queue[1:], 0
+		}
+	}
+
+	if len(cursor.Queue) == 0 {
+		return ids, "", nil
+	}
+
+	next, err := encodeDescendantsCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return ids, next, nil
+}
+
+// encodeDescendantsCursor serializes a cursor into an opaque page token
+func encodeDescendantsCursor(cursor descendantsCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeDescendantsCursor parses a page token and checks it belongs to root
+func decodeDescendantsCursor(token, root string) (descendantsCursor, error) {
+	var cursor descendantsCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidPageToken, err)
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidPageToken, err)
+	}
+
+	if cursor.Root != root {
+		return cursor, fmt.Errorf("%w: token is for root %s, not %s", ErrInvalidPageToken, cursor.Root, root)
+	}
+
+	if len(cursor.Seen) == 0 || cursor.Seen[0] != root {
+		return cursor, fmt.Errorf("%w: missing visited set", ErrInvalidPageToken)
+	}
+
+	if cursor.Offset < 0 {
+		return cursor, fmt.Errorf("%w: negative offset", ErrInvalidPageToken)
+	}
+
+	return cursor, nil
+}
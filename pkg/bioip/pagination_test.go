@@ -0,0 +1,108 @@
+package bioip
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+func TestGetDescendantsPage(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg,
+		// 5 derives from both 3 and 4, and 6 lists its ancestor 2 as a child
+		testAsset(1, 0, 2, 3),
+		testAsset(2, 1, 4),
+		testAsset(3, 1, 5),
+		testAsset(4, 2, 5, 6),
+		testAsset(5, 3),
+		testAsset(6, 4, 2, 7),
+		testAsset(7, 6),
+		// 11 lists the root 10 as a child
+		testAsset(10, 0, 11),
+		testAsset(11, 10, 10),
+	)
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name    string
+		tokenID string
+		want    []int64
+	}{
+		{name: "diamond and cycle", tokenID: "1", want: []int64{2, 3, 4, 5, 6, 7}},
+		{name: "cycle through the root", tokenID: "10", want: []int64{11}},
+		{name: "leaf", tokenID: "7", want: []int64{}},
+	}
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 3, 0} {
+			t.Run(fmt.Sprintf("%s/limit %d", tt.name, limit), func(t *testing.T) {
+				got := []int64{}
+				pageToken := ""
+				for pages := 0; ; pages++ {
+					if pages > len(tt.want)+1 {
+						t.Fatalf("paging did not terminate, got %v", got)
+					}
+
+					ids, next, err := m.GetDescendantsPage(context.Background(), simChain, tt.tokenID, pageToken, limit)
+					if err != nil {
+						t.Fatalf("GetDescendantsPage failed: %v", err)
+					}
+					if limit > 0 && len(ids) > limit {
+						t.Fatalf("page of %d exceeds the limit", len(ids))
+					}
+					got = append(got, tokenInts(ids)...)
+					if next == "" {
+						break
+					}
+					pageToken = next
+				}
+
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestGetDescendantsPageInvalidToken(t *testing.T) {
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0, 2, 3), testAsset(2, 1), testAsset(3, 1))
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+	ctx := context.Background()
+
+	_, next, err := m.GetDescendantsPage(ctx, simChain, "1", "", 1)
+	if err != nil || next == "" {
+		t.Fatalf("first page: next %q, err %v", next, err)
+	}
+
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name    string
+		tokenID string
+		token   string
+	}{
+		{name: "other root", tokenID: "2", token: next},
+		{name: "not base64", tokenID: "1", token: "!!"},
+		{name: "not JSON", tokenID: "1", token: encode("nope")},
+		{name: "no visited set", tokenID: "1", token: encode(`{"r":"1","q":[{"i":"1"}],"o":1}`)},
+		{name: "negative offset", tokenID: "1", token: encode(`{"r":"1","q":[{"i":"1"}],"o":-1,"s":["1"]}`)},
+		{name: "offset past children", tokenID: "1", token: encode(`{"r":"1","q":[{"i":"1"}],"o":5,"s":["1"]}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := m.GetDescendantsPage(ctx, simChain, tt.tokenID, tt.token, 1)
+			if !errors.Is(err, ErrInvalidPageToken) {
+				t.Errorf("got %v, want %v", err, ErrInvalidPageToken)
+			}
+		})
+	}
+
+}
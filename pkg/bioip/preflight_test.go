@@ -0,0 +1,86 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/consent"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPreflightDerivative(t *testing.T) {
+	unlicensed := testAsset(2, 0)
+	unlicensed.HasLicense = false
+	noTerms := testAsset(3, 0)
+	noTerms.LicenseTermsId = new(big.Int)
+	revoked := testAsset(4, 0)
+	revoked.ConsentState = uint8(consent.ConsentRevoked)
+	deleted := testAsset(5, 0)
+	deleted.ConsentState = uint8(consent.ConsentDeleted)
+	pending := testAsset(6, 0)
+	pending.ConsentState = uint8(consent.ConsentPending)
+
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0), unlicensed, noTerms, revoked, deleted, pending)
+	m, _ := newSimManager(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name     string
+		parent   int64
+		wantErr  error
+		wantWrap error
+	}{
+		{name: "licensed and active", parent: 1},
+		{name: "no license", parent: 2, wantErr: ErrParentNotLicensed},
+		{name: "no license terms ID", parent: 3, wantErr: ErrParentNotLicensed},
+		{name: "revoked", parent: 4, wantErr: ErrParentConsentInactive, wantWrap: consent.ErrConsentRevoked},
+		{name: "deleted", parent: 5, wantErr: ErrParentConsentInactive, wantWrap: consent.ErrConsentDeleted},
+		{name: "pending", parent: 6, wantErr: ErrParentConsentInactive},
+		{name: "missing", parent: 99, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.PreflightDerivative(context.Background(), simChain, big.NewInt(tt.parent))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("PreflightDerivative failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantWrap != nil && !errors.Is(err, tt.wantWrap) {
+				t.Errorf("got %v, want it to wrap %v", err, tt.wantWrap)
+			}
+		})
+	}
+}
+
+func TestCreateDerivativeFlowStopsAtPreflight(t *testing.T) {
+	unlicensed := testAsset(2, 0)
+	unlicensed.HasLicense = false
+
+	reg := newSimRegistry()
+	scriptTree(reg, unlicensed)
+	m, chain := newSimManager(t, []*simchain.Contract{reg})
+
+	signer := chain.Auth(t, 0)
+	_, err := m.CreateDerivativeFlow(context.Background(), simChain, big.NewInt(2),
+		biocid.ContentHash{9}, "vcf", 1024, [32]byte{9}, common.Address{}, signer)
+	if !errors.Is(err, ErrParentNotLicensed) {
+		t.Fatalf("got %v, want %v", err, ErrParentNotLicensed)
+	}
+
+	nonce, err := chain.Backend.PendingNonceAt(context.Background(), signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+	if nonce != 0 {
+		t.Errorf("preflight failure still sent %d transactions", nonce)
+	}
+}
@@ -0,0 +1,74 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Session binds a BioIPManager to one signer so write methods can be called without passing it
+// Each call signs with a fresh copy of the signer, so nonces and fees are looked up again rather
+// than reused; calls on one session are serialized
+type Session struct {
+	mu      sync.Mutex
+	manager *BioIPManager
+	signer  *bind.TransactOpts
+}
+
+// NewSession returns a session sending transactions from signer through m
+func NewSession(m *BioIPManager, signer *bind.TransactOpts) (*Session, error) {
+	if m == nil {
+		return nil, fmt.Errorf("manager is required")
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("signer is required")
+	}
+	return &Session{manager: m, signer: signer}, nil
+}
+
+// From returns the address transactions are sent from
+func (s *Session) From() common.Address {
+	return s.signer.From
+}
+
+// MintRootBioIP mints a root BioIP asset from the session's signer
+func (s *Session) MintRootBioIP(
+	ctx context.Context,
+	chain biocid.Chain,
+	contentHash biocid.ContentHash,
+	dataType string,
+	dataSize uint64,
+	bioCID [32]byte,
+	ipAssetID common.Address,
+	licenseTermsID *big.Int,
+) (*MintResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.manager.MintRootBioIP(ctx, chain, contentHash, dataType, dataSize, bioCID, ipAssetID, licenseTermsID,
+		chainrpc.FreshTransactOpts(ctx, s.signer))
+}
+
+// CreateDerivativeFlow runs the derivative flow from the session's signer
+func (s *Session) CreateDerivativeFlow(
+	ctx context.Context,
+	chain biocid.Chain,
+	parentTokenID *big.Int,
+	childContentHash biocid.ContentHash,
+	childDataType string,
+	childDataSize uint64,
+	childBioCID [32]byte,
+	childIPAssetID common.Address,
+) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.manager.CreateDerivativeFlow(ctx, chain, parentTokenID, childContentHash, childDataType, childDataSize,
+		childBioCID, childIPAssetID, chainrpc.FreshTransactOpts(ctx, s.signer))
+}
@@ -0,0 +1,121 @@
+package bioip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestNewSession(t *testing.T) {
+	m, chain := newSimManager(t, nil)
+	signer := chain.Auth(t, 0)
+
+	tests := []struct {
+		name    string
+		m       *BioIPManager
+		signer  *bind.TransactOpts
+		wantErr bool
+	}{
+		{name: "valid", m: m, signer: signer},
+		{name: "no manager", signer: signer, wantErr: true},
+		{name: "no signer", m: m, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSession(tt.m, tt.signer)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSession failed: %v", err)
+			}
+			if s.From() != signer.From {
+				t.Errorf("From = %s, want %s", s.From().Hex(), signer.From.Hex())
+			}
+		})
+	}
+}
+
+func TestSessionWrites(t *testing.T) {
+	flow := derivativeFlow{parent: 1, license: 50, child: 2}
+
+	reg := newSimRegistry()
+	m, chain := newSimManager(t, []*simchain.Contract{reg}, WithWaitMined(true))
+	signer := chain.Auth(t, 0)
+	scriptMintRoot(reg, signer.From, 1, 7, true)
+	scriptMintRoot(reg, signer.From, 3, 9, true)
+	scriptDerivativeFlow(reg, signer.From, flow, true)
+	chain.Apply(t, reg)
+
+	// A nonce and fees pinned on the signer would make every call after the first fail
+	ctx := context.Background()
+	nonce, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+	signer.Nonce = new(big.Int).SetUint64(nonce)
+	signer.GasLimit = 1
+
+	s, err := NewSession(m, signer)
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() (*big.Int, error)
+		want int64
+	}{
+		{
+			name: "first mint",
+			run: func() (*big.Int, error) {
+				r, err := s.MintRootBioIP(ctx, simChain, biocid.ContentHash{1}, "vcf", 1024, [32]byte{0xb1, 1}, simIPAsset, big.NewInt(1))
+				if err != nil {
+					return nil, err
+				}
+				return r.TokenID(), nil
+			},
+			want: 7,
+		},
+		{
+			name: "second mint",
+			run: func() (*big.Int, error) {
+				r, err := s.MintRootBioIP(ctx, simChain, biocid.ContentHash{3}, "vcf", 1024, [32]byte{0xb1, 3}, simIPAsset, big.NewInt(1))
+				if err != nil {
+					return nil, err
+				}
+				return r.TokenID(), nil
+			},
+			want: 9,
+		},
+		{
+			name: "derivative flow",
+			run: func() (*big.Int, error) {
+				return s.CreateDerivativeFlow(ctx, simChain, big.NewInt(flow.parent),
+					biocid.ContentHash{byte(flow.child)}, "vcf", 1024, [32]byte{0xb1, byte(flow.child)}, simIPAsset)
+			},
+			want: flow.child,
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.run()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got == nil || got.Int64() != tt.want {
+			t.Errorf("%s: got token %v, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// The session worked on copies
+	if signer.Nonce.Uint64() != nonce || signer.GasLimit != 1 {
+		t.Errorf("signer changed to nonce %s, gas limit %d", signer.Nonce, signer.GasLimit)
+	}
+}
@@ -0,0 +1,105 @@
+package bioip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// simChain is the chain name simulated-backend tests register their backend under
+const simChain = "story"
+
+// simRegistry is the address of the scripted BioIPRegistry
+var simRegistry = common.HexToAddress("0x00000000000000000000000000000000000B1010")
+
+// newSimRegistry returns a scripted BioIPRegistry; getBioIP of an unconfigured token returns the zero asset
+func newSimRegistry() *simchain.Contract {
+	reg := simchain.NewContract(simRegistry, registryABI)
+	reg.On("getBioIP").Returns(zeroAsset())
+	return reg
+}
+
+// newSimManager starts a simulated chain holding the given contracts and returns a manager using it
+func newSimManager(t testing.TB, contracts []*simchain.Contract, opts ...Option) (*BioIPManager, *simchain.Chain) {
+	t.Helper()
+
+	chain := simchain.New(t, contracts...)
+	opts = append([]Option{
+		WithBackend(simChain, chain.Miner()),
+		WithRegistry(simChain, simRegistry),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	}, opts...)
+
+	m := NewBioIPManager(opts...)
+	t.Cleanup(m.Close)
+	return m, chain
+}
+
+// zeroAsset is the tuple getBioIP returns for an unminted token
+func zeroAsset() registryBioIPAsset {
+	return registryBioIPAsset{
+		TokenId:        new(big.Int),
+		CreatedAt:      new(big.Int),
+		RevokedAt:      new(big.Int),
+		DataSize:       new(big.Int),
+		LicenseTermsId: new(big.Int),
+		ParentTokenId:  new(big.Int),
+		ChildTokenIds:  []*big.Int{},
+		Generation:     new(big.Int),
+		LicenseTokenId: new(big.Int),
+	}
+}
+
+// testAsset is a minted asset with the given parent (0 for a root) and children
+func testAsset(tokenID, parent int64, children ...int64) registryBioIPAsset {
+	asset := zeroAsset()
+	asset.Owner = common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	asset.TokenId = big.NewInt(tokenID)
+	asset.ConsentState = 1
+	asset.CreatedAt = big.NewInt(1700000000)
+	asset.ContentHash = [32]byte{byte(tokenID)}
+	asset.DataType = "vcf"
+	asset.DataSize = big.NewInt(1024)
+	asset.BioCID = [32]byte{0xb1, byte(tokenID)}
+	asset.IpAssetId = common.BigToAddress(big.NewInt(0x1000 + tokenID))
+	asset.HasLicense = true
+	asset.LicenseTermsId = big.NewInt(1)
+	asset.ParentTokenId = big.NewInt(parent)
+	if parent != 0 {
+		asset.Generation = big.NewInt(1)
+	}
+	for _, child := range children {
+		asset.ChildTokenIds = append(asset.ChildTokenIds, big.NewInt(child))
+	}
+	return asset
+}
+
+// scriptTree scripts getBioIP for each asset
+func scriptTree(reg *simchain.Contract, assets ...registryBioIPAsset) {
+	for _, asset := range assets {
+		reg.On("getBioIP", asset.TokenId).Returns(asset)
+	}
+}
+
+// transactSim sends a registry transaction from account 0, mines it and returns its block number
+func transactSim(t testing.TB, chain *simchain.Chain, method string, args ...interface{}) uint64 {
+	t.Helper()
+
+	bound := bind.NewBoundContract(simRegistry, registryABI, chain.Backend, chain.Backend, chain.Backend)
+	tx, err := bound.Transact(chain.Auth(t, 0), method, args...)
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	chain.Backend.Commit()
+
+	receipt, err := chain.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("no receipt for %s: %v", method, err)
+	}
+	return receipt.BlockNumber.Uint64()
+}
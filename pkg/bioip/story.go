@@ -0,0 +1,89 @@
+package bioip
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed IPAssetRegistry.abi.json
+var ipAssetRegistryABIJSON string
+
+// ipAssetRegistryABI is the subset of Story Protocol's IPAssetRegistry ABI used to check IP accounts
+var ipAssetRegistryABI = mustParseABI(ipAssetRegistryABIJSON)
+
+// defaultIPAssetRegistries are Story Protocol's IPAssetRegistry deployments by chain name
+var defaultIPAssetRegistries = map[biocid.Chain]common.Address{
+	biocid.ChainStory: common.HexToAddress("0x77319B4031e6eF1250907aa00018B8B1c67a244b"),
+}
+
+// VerifyIPAsset checks that the token's IPAssetID is the IP account Story Protocol registered for it
+// The IP account must be registered and be the one the IP Asset Registry derives for this
+// registry's token; ErrIPAssetNotRegistered is returned otherwise
+func (m *BioIPManager) VerifyIPAsset(ctx context.Context, chain biocid.Chain, tokenID *big.Int) error {
+	asset, err := m.GetBioIP(ctx, chain, tokenID)
+	if err != nil {
+		return err
+	}
+	if asset.IPAssetID == (common.Address{}) {
+		return fmt.Errorf("%w: token %s has no IP asset", ErrIPAssetNotRegistered, tokenID)
+	}
+
+	info, ok := m.chains.Lookup(chain.String())
+	if !ok || info.ChainID == nil {
+		return fmt.Errorf("no chain ID known for chain: %s", chain)
+	}
+
+	registryAddr, err := m.registryAddress(chain)
+	if err != nil {
+		return err
+	}
+
+	contract, err := m.ipAssetRegistry(ctx, chain)
+	if err != nil {
+		return err
+	}
+
+	out, err := m.call(ctx, contract, "isRegistered", asset.IPAssetID)
+	if err != nil {
+		return err
+	}
+	if !*abi.ConvertType(out[0], new(bool)).(*bool) {
+		return fmt.Errorf("%w: %s", ErrIPAssetNotRegistered, asset.IPAssetID.Hex())
+	}
+
+	out, err = m.call(ctx, contract, "ipId", info.ChainID, registryAddr, tokenID)
+	if err != nil {
+		return err
+	}
+	expected := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	if expected != asset.IPAssetID {
+		return fmt.Errorf("%w: %s is not the IP account of token %s (expected %s)",
+			ErrIPAssetNotRegistered, asset.IPAssetID.Hex(), tokenID, expected.Hex())
+	}
+
+	return nil
+}
+
+// ipAssetRegistry returns a binding to Story Protocol's IP Asset Registry on the given chain
+func (m *BioIPManager) ipAssetRegistry(ctx context.Context, chain biocid.Chain) (*bind.BoundContract, error) {
+	m.mu.Lock()
+	addr, ok := m.ipRegistries[chain]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no IP Asset Registry configured for chain: %s", chain)
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	return bind.NewBoundContract(addr, ipAssetRegistryABI, client, client, client), nil
+}
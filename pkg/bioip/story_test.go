@@ -0,0 +1,59 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// simIPRegistry is the address of the scripted Story IPAssetRegistry
+var simIPRegistry = common.HexToAddress("0x00000000000000000000000000000000001A55E7")
+
+func TestVerifyIPAsset(t *testing.T) {
+	info, ok := biocid.DefaultChains.Lookup(simChain)
+	if !ok {
+		t.Fatalf("chain %s is not registered", simChain)
+	}
+
+	noAsset := testAsset(4, 0)
+	noAsset.IpAssetId = common.Address{}
+
+	reg := newSimRegistry()
+	scriptTree(reg, testAsset(1, 0), testAsset(2, 0), testAsset(3, 0), noAsset)
+
+	ipReg := simchain.NewContract(simIPRegistry, ipAssetRegistryABI)
+	ipReg.On("isRegistered").Returns(false)
+	ipReg.On("ipId").Returns(common.Address{})
+	for _, id := range []int64{1, 3} {
+		ipReg.On("isRegistered", testAsset(id, 0).IpAssetId).Returns(true)
+	}
+	ipReg.On("ipId", info.ChainID, simRegistry, big.NewInt(1)).Returns(testAsset(1, 0).IpAssetId)
+	ipReg.On("ipId", info.ChainID, simRegistry, big.NewInt(3)).Returns(common.HexToAddress("0xdead"))
+
+	m, _ := newSimManager(t, []*simchain.Contract{reg, ipReg}, WithIPAssetRegistry(simChain, simIPRegistry))
+
+	tests := []struct {
+		name    string
+		tokenID int64
+		wantErr error
+	}{
+		{name: "registered to the token", tokenID: 1},
+		{name: "not registered", tokenID: 2, wantErr: ErrIPAssetNotRegistered},
+		{name: "registered to another token", tokenID: 3, wantErr: ErrIPAssetNotRegistered},
+		{name: "no IP asset", tokenID: 4, wantErr: ErrIPAssetNotRegistered},
+		{name: "unminted token", tokenID: 5, wantErr: ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.VerifyIPAsset(context.Background(), simChain, big.NewInt(tt.tokenID))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package bioip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/datatype"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// NonceMode selects how CreateDerivativeFlow sequences its transactions
+type NonceMode int
+
+const (
+	// NonceWaitEach waits for each transaction to be mined before sending the next
+	NonceWaitEach NonceMode = iota
+
+	// NonceLocal reads the signer's pending nonce once and assigns the following nonces locally,
+	// so independent transactions are sent without waiting for each other
+	NonceLocal
+)
+
+// MintResult describes a submitted mint transaction
+type MintResult struct {
+	TxHash   common.Hash
+	TokenIDs []*big.Int     // Token IDs parsed from the receipt's event logs (empty until mined)
+	Receipt  *types.Receipt // Receipt of the mined transaction (nil if not waited for)
+
+	tx *types.Transaction
+}
+
+// TokenID returns the first minted token ID, or nil if none are known yet
+func (r *MintResult) TokenID() *big.Int {
+	if len(r.TokenIDs) == 0 {
+		return nil
+	}
+	return r.TokenIDs[0]
+}
+
+// submit sends a registry transaction and, when wait is set, blocks for its receipt
+// and collects the token IDs indexed in the given event's first topic
+func (m *BioIPManager) submit(
+	ctx context.Context,
+	chain biocid.Chain,
+	signer *bind.TransactOpts,
+	wait bool,
+	event string,
+	method string,
+	args ...interface{},
+) (*MintResult, error) {
+	result, err := m.send(ctx, chain, signer, method, args...)
+	if err != nil || !wait {
+		return result, err
+	}
+
+	return m.await(ctx, chain, result, event)
+}
+
+// send signs and sends a registry transaction without waiting for it to be mined
+func (m *BioIPManager) send(
+	ctx context.Context,
+	chain biocid.Chain,
+	signer *bind.TransactOpts,
+	method string,
+	args ...interface{},
+) (*MintResult, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signer is required")
+	}
+
+	if err := m.checkChainID(ctx, chain); err != nil {
+		return nil, err
+	}
+
+	contract, err := m.registry(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *signer
+	if opts.Context == nil {
+		opts.Context = ctx
+	}
+
+	if err := m.applyFees(ctx, chain, &opts); err != nil {
+		return nil, err
+	}
+
+	tx, err := contract.Transact(&opts, method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	m.logger.Info("sent transaction", "chain", chain, "method", method, "tx", tx.Hash().Hex())
+
+	return &MintResult{TxHash: tx.Hash(), tx: tx}, nil
+}
+
+// await blocks until a sent transaction is mined and collects the token IDs
+// indexed in the given event's first topic
+func (m *BioIPManager) await(ctx context.Context, chain biocid.Chain, result *MintResult, event string) (*MintResult, error) {
+	tx := result.tx
+	receipt, err := m.waitMined(ctx, chain, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := m.registryAddress(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Receipt = receipt
+	result.TokenIDs = indexedTokenIDs(receipt.Logs, addr, event)
+	if event != "" && len(result.TokenIDs) == 0 {
+		return nil, fmt.Errorf("no %s event in transaction %s", event, tx.Hash().Hex())
+	}
+
+	return result, nil
+}
+
+// normalizeDataType maps dataType to its canonical form before it is stored on-chain
+func (m *BioIPManager) normalizeDataType(dataType string) (string, error) {
+	return datatype.Normalizer{AllowCustom: m.customTypes}.Normalize(dataType)
+}
+
+// pendingNonce returns the nonce of the signer's next transaction
+// A nonce already set on the signer is used as the starting point
+func (m *BioIPManager) pendingNonce(ctx context.Context, chain biocid.Chain, signer *bind.TransactOpts) (uint64, error) {
+	if signer == nil {
+		return 0, fmt.Errorf("signer is required")
+	}
+	if signer.Nonce != nil {
+		return signer.Nonce.Uint64(), nil
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	var nonce uint64
+	err = m.withRetry(ctx, "eth_getTransactionCount", func(ctx context.Context) error {
+		nonce, err = client.PendingNonceAt(ctx, signer.From)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// waitMined blocks until tx is mined and fails if it reverted
+func (m *BioIPManager) waitMined(ctx context.Context, chain biocid.Chain, tx *types.Transaction) (*types.Receipt, error) {
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for transaction %s: %w", tx.Hash().Hex(), err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+
+	return receipt, nil
+}
+
+// indexedTokenIDs extracts the first indexed uint256 of each matching registry event
+func indexedTokenIDs(logs []*types.Log, registry common.Address, event string) []*big.Int {
+	topic := registryABI.Events[event].ID
+
+	var ids []*big.Int
+	for _, log := range logs {
+		if log.Address != registry || len(log.Topics) < 2 || log.Topics[0] != topic {
+			continue
+		}
+		ids = append(ids, log.Topics[1].Big())
+	}
+	return ids
+}
+
+// applyFees prices opts with the fee strategy configured for chain
+func (m *BioIPManager) applyFees(ctx context.Context, chain biocid.Chain, opts *bind.TransactOpts) error {
+	strategy, ok := m.chainFees[chain]
+	if !ok {
+		strategy = m.fees
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	if err := strategy.Apply(ctx, client, opts); err != nil {
+		return fmt.Errorf("failed to set fees on %s: %w", chain, err)
+	}
+	return nil
+}
+
+// checkChainID verifies the chain's backend reports the chain ID registered for it
+func (m *BioIPManager) checkChainID(ctx context.Context, chain biocid.Chain) error {
+	info, ok := m.chains.Lookup(chain.String())
+	if !ok {
+		return nil
+	}
+
+	client, err := m.getClient(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, m.timeout)
+	defer cancel()
+
+	if err := chainrpc.CheckChainID(ctx, client, info.ChainID); err != nil {
+		return fmt.Errorf("%s: %w", chain, err)
+	}
+	return nil
+}
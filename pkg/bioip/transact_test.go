@@ -0,0 +1,253 @@
+package bioip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/datatype"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// simIPAsset is the IP asset ID minted test assets are registered under
+var simIPAsset = common.HexToAddress("0x0000000000000000000000000000000000001000")
+
+// scriptMintRoot scripts mintRootBioIP of contentHash to mint tokenID, emitting BioIPMinted when emit is set
+func scriptMintRoot(reg *simchain.Contract, owner common.Address, contentHash byte, tokenID int64, emit bool) *simchain.Call {
+	hash := [32]byte{contentHash}
+	bioCID := [32]byte{0xb1, contentHash}
+	call := reg.On("mintRootBioIP", hash, "vcf", big.NewInt(1024), bioCID, simIPAsset, big.NewInt(1)).
+		Returns(big.NewInt(tokenID))
+	if emit {
+		call.Emits("BioIPMinted", big.NewInt(tokenID), owner, hash, "vcf", bioCID, simIPAsset, big.NewInt(1))
+	}
+	return call
+}
+
+func TestMintRootBioIP(t *testing.T) {
+	reg := newSimRegistry()
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	scriptMintRoot(reg, owner, 1, 7, true)
+	scriptMintRoot(reg, owner, 2, 8, false)
+	reg.On("mintRootBioIP", [32]byte{3}, "vcf", big.NewInt(1024), [32]byte{0xb1, 3}, simIPAsset, big.NewInt(1)).Reverts()
+
+	tests := []struct {
+		name        string
+		contentHash byte
+		wait        bool
+		noSigner    bool
+		wantTokenID int64
+		wantErr     bool
+	}{
+		{name: "waits for receipt", contentHash: 1, wait: true, wantTokenID: 7},
+		{name: "returns without waiting", contentHash: 1},
+		{name: "no event in receipt", contentHash: 2, wait: true, wantErr: true},
+		{name: "reverts", contentHash: 3, wait: true, wantErr: true},
+		{name: "no signer", contentHash: 1, noSigner: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, chain := newSimManager(t, []*simchain.Contract{reg}, WithWaitMined(tt.wait))
+			signer := chain.Auth(t, 0)
+			if tt.noSigner {
+				signer = nil
+			}
+
+			result, err := m.MintRootBioIP(context.Background(), simChain,
+				biocid.ContentHash{tt.contentHash}, "vcf", 1024, [32]byte{0xb1, tt.contentHash}, simIPAsset, big.NewInt(1), signer)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MintRootBioIP failed: %v", err)
+			}
+			if result.TxHash == (common.Hash{}) {
+				t.Error("missing transaction hash")
+			}
+
+			if !tt.wait {
+				if result.Receipt != nil || result.TokenID() != nil {
+					t.Errorf("got receipt %v and token %v without waiting", result.Receipt, result.TokenID())
+				}
+				return
+			}
+			if result.Receipt == nil || result.Receipt.Status != types.ReceiptStatusSuccessful {
+				t.Fatalf("got receipt %+v, want a successful one", result.Receipt)
+			}
+			if result.Receipt.TxHash != result.TxHash {
+				t.Errorf("receipt is for %s, want %s", result.Receipt.TxHash, result.TxHash)
+			}
+			if got := result.TokenID(); got == nil || got.Int64() != tt.wantTokenID {
+				t.Errorf("got token %v, want %d", got, tt.wantTokenID)
+			}
+		})
+	}
+}
+
+// chainIDBackend reports a fixed chain ID, which the simulated backend cannot
+type chainIDBackend struct {
+	chainrpc.Backend
+	id *big.Int
+}
+
+func (b *chainIDBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.id, nil
+}
+
+func TestMintChecksChainID(t *testing.T) {
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	reg := newSimRegistry()
+	scriptMintRoot(reg, owner, 1, 7, true)
+	chain := simchain.New(t, reg)
+	signer := chain.Auth(t, 0)
+
+	local := biocid.NewChainRegistry()
+	local.Register(simChain, "http://127.0.0.1:8545", simchain.ChainID)
+
+	tests := []struct {
+		name    string
+		chains  *biocid.ChainRegistry
+		wantErr error
+	}{
+		{name: "matching chain ID", chains: local},
+		{name: "backend on another chain", chains: biocid.DefaultChains, wantErr: chainrpc.ErrChainIDMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewBioIPManager(
+				WithChainRegistry(tt.chains),
+				WithBackend(simChain, &chainIDBackend{Backend: chain.Miner(), id: simchain.ChainID}),
+				WithRegistry(simChain, simRegistry),
+				WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+			)
+			defer m.Close()
+
+			ctx := context.Background()
+			before, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = m.MintRootBioIP(ctx, simChain, biocid.ContentHash{1}, "vcf", 1024, [32]byte{0xb1, 1}, simIPAsset, big.NewInt(1), signer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			after, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent, want := after > before, tt.wantErr == nil; sent != want {
+				t.Errorf("transaction sent = %v, want %v", sent, want)
+			}
+		})
+	}
+}
+
+func TestMintAppliesFeeStrategy(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+	legacy := chainrpc.LegacyFees(gwei(10))
+	dynamic := chainrpc.DynamicFees(gwei(1), gwei(20))
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantType uint8
+		wantFee  *big.Int // Gas price of legacy transactions, fee cap of dynamic ones
+		wantTip  *big.Int
+	}{
+		{name: "legacy everywhere", opts: []Option{WithFeeStrategy(legacy)}, wantType: types.LegacyTxType, wantFee: gwei(10)},
+		{name: "dynamic everywhere", opts: []Option{WithFeeStrategy(dynamic)}, wantType: types.DynamicFeeTxType, wantFee: gwei(20), wantTip: gwei(1)},
+		{
+			name:     "chain override",
+			opts:     []Option{WithFeeStrategy(legacy), WithChainFeeStrategy(simChain, dynamic)},
+			wantType: types.DynamicFeeTxType, wantFee: gwei(20), wantTip: gwei(1),
+		},
+		{
+			name:     "override for another chain",
+			opts:     []Option{WithFeeStrategy(legacy), WithChainFeeStrategy("avalanche", dynamic)},
+			wantType: types.LegacyTxType, wantFee: gwei(10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newSimRegistry()
+			m, chain := newSimManager(t, []*simchain.Contract{reg}, append(tt.opts, WithWaitMined(true))...)
+			signer := chain.Auth(t, 0)
+			scriptMintRoot(reg, signer.From, 1, 7, true)
+			chain.Apply(t, reg)
+
+			ctx := context.Background()
+			result, err := m.MintRootBioIP(ctx, simChain, biocid.ContentHash{1}, "vcf", 1024, [32]byte{0xb1, 1}, simIPAsset, big.NewInt(1), signer)
+			if err != nil {
+				t.Fatalf("MintRootBioIP failed: %v", err)
+			}
+
+			tx, _, err := chain.Backend.TransactionByHash(ctx, result.TxHash)
+			if err != nil {
+				t.Fatalf("TransactionByHash failed: %v", err)
+			}
+			if tx.Type() != tt.wantType {
+				t.Fatalf("got transaction type %d, want %d", tx.Type(), tt.wantType)
+			}
+			if tx.GasFeeCap().Cmp(tt.wantFee) != 0 {
+				t.Errorf("fee = %s, want %s", tx.GasFeeCap(), tt.wantFee)
+			}
+			if tt.wantTip != nil && tx.GasTipCap().Cmp(tt.wantTip) != 0 {
+				t.Errorf("tip = %s, want %s", tx.GasTipCap(), tt.wantTip)
+			}
+		})
+	}
+}
+
+func TestMintNormalizesDataType(t *testing.T) {
+	reg := newSimRegistry()
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	scriptMintRoot(reg, owner, 1, 7, true)
+	reg.On("mintRootBioIP", [32]byte{2}, "hl7", big.NewInt(1024), [32]byte{0xb1, 2}, simIPAsset, big.NewInt(1)).
+		Returns(big.NewInt(8)).
+		Emits("BioIPMinted", big.NewInt(8), owner, [32]byte{2}, "hl7", [32]byte{0xb1, 2}, simIPAsset, big.NewInt(1))
+
+	tests := []struct {
+		name        string
+		dataType    string
+		contentHash byte
+		custom      bool
+		wantTokenID int64
+		wantErr     error
+	}{
+		{name: "alias", dataType: "VCF", contentHash: 1, wantTokenID: 7},
+		{name: "MIME type", dataType: "text/x-vcf", contentHash: 1, wantTokenID: 7},
+		{name: "unknown", dataType: "HL7", contentHash: 2, wantErr: datatype.ErrUnknownDataType},
+		{name: "custom allowed", dataType: "HL7", contentHash: 2, custom: true, wantTokenID: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, chain := newSimManager(t, []*simchain.Contract{reg}, WithWaitMined(true), WithCustomDataTypes(tt.custom))
+			signer := chain.Auth(t, 0)
+
+			result, err := m.MintRootBioIP(context.Background(), simChain,
+				biocid.ContentHash{tt.contentHash}, tt.dataType, 1024, [32]byte{0xb1, tt.contentHash}, simIPAsset, big.NewInt(1), signer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MintRootBioIP failed: %v", err)
+			}
+			if got := result.TokenID(); got == nil || got.Int64() != tt.wantTokenID {
+				t.Errorf("got token %v, want %d", got, tt.wantTokenID)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package bioip
+
+import (
+	"math/big"
+	"sort"
+)
+
+// CountDescendants returns the number of distinct nodes below n
+func (n *LineageNode) CountDescendants() int {
+	if n == nil {
+		return 0
+	}
+	return len(n.Flatten()) - 1
+}
+
+// MaxDepth returns the number of generations between n and its deepest descendant
+// A leaf or nil node has depth 0; nodes already visited and AlreadyExpanded stubs are skipped
+func (n *LineageNode) MaxDepth() int {
+	return n.maxDepth(make(map[*LineageNode]bool))
+}
+
+// maxDepth computes MaxDepth, skipping nodes in visited
+func (n *LineageNode) maxDepth(visited map[*LineageNode]bool) int {
+	if n == nil || visited[n] {
+		return 0
+	}
+	visited[n] = true
+
+	depth := 0
+	for _, child := range n.Children {
+		if child == nil || visited[child] || child.AlreadyExpanded {
+			continue
+		}
+		if d := child.maxDepth(visited) + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// Flatten returns n and its descendants in pre-order
+// Each node appears once, so cyclic inputs terminate; AlreadyExpanded stubs are left out
+func (n *LineageNode) Flatten() []*LineageNode {
+	var nodes []*LineageNode
+	visited := make(map[*LineageNode]bool)
+
+	var walk func(node *LineageNode)
+	walk = func(node *LineageNode) {
+		if node == nil || visited[node] || node.AlreadyExpanded {
+			return
+		}
+		visited[node] = true
+		nodes = append(nodes, node)
+
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	return nodes
+}
+
+// LineageDiff lists the token IDs that differ between two lineage trees, each in ascending order
+type LineageDiff struct {
+	Added   []*big.Int // In the new tree only
+	Removed []*big.Int // In the old tree only
+	Changed []*big.Int // In both, with a different BioCID, data type, generation, or parent
+}
+
+// IsEmpty reports whether the two trees held the same nodes
+func (d LineageDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffLineage compares two lineage trees node by node, matching nodes by TokenID
+// Children are compared as sets, so a reordered but otherwise equal tree yields an empty diff
+// Either tree may be nil
+func DiffLineage(oldRoot, newRoot *LineageNode) LineageDiff {
+	before := indexLineage(oldRoot)
+	after := indexLineage(newRoot)
+
+	var diff LineageDiff
+	for key, a := range after {
+		b, ok := before[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, a.node.TokenID)
+		case !a.equal(b):
+			diff.Changed = append(diff.Changed, a.node.TokenID)
+		}
+	}
+	for key, b := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, b.node.TokenID)
+		}
+	}
+
+	for _, ids := range [][]*big.Int{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+	}
+	return diff
+}
+
+// lineageEntry is a node together with the token ID of the parent it was found under
+type lineageEntry struct {
+	node   *LineageNode
+	parent string
+}
+
+// equal reports whether two entries for the same token carry the same data and position
+func (e lineageEntry) equal(o lineageEntry) bool {
+	return e.parent == o.parent &&
+		e.node.BioCID == o.node.BioCID &&
+		e.node.DataType == o.node.DataType &&
+		bigEqual(e.node.Generation, o.node.Generation)
+}
+
+// indexLineage maps each token ID in the tree rooted at root to its entry
+// Nodes without a token ID and AlreadyExpanded stubs are skipped; a token seen twice keeps its first position
+func indexLineage(root *LineageNode) map[string]lineageEntry {
+	index := make(map[string]lineageEntry)
+
+	var walk func(node *LineageNode, parent string)
+	walk = func(node *LineageNode, parent string) {
+		if node == nil || node.TokenID == nil || node.AlreadyExpanded {
+			return
+		}
+		key := node.TokenID.String()
+		if _, ok := index[key]; ok {
+			return
+		}
+		index[key] = lineageEntry{node: node, parent: parent}
+
+		for _, child := range node.Children {
+			walk(child, key)
+		}
+	}
+	walk(root, "")
+
+	return index
+}
@@ -0,0 +1,131 @@
+package bioip
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// nodeIDs lists the token IDs of nodes in order
+func nodeIDs(nodes []*LineageNode) []int64 {
+	out := []int64{}
+	for _, n := range nodes {
+		out = append(out, n.TokenID.Int64())
+	}
+	return out
+}
+
+func TestLineageNodeHelpers(t *testing.T) {
+	balanced := lineageNode(1, "fastq", 0,
+		lineageNode(2, "bam", 1, lineageNode(4, "vcf", 2), lineageNode(5, "vcf", 2)),
+		lineageNode(3, "bam", 1, lineageNode(6, "vcf", 2), lineageNode(7, "vcf", 2)),
+	)
+	unbalanced := lineageNode(1, "fastq", 0,
+		lineageNode(2, "bam", 1,
+			lineageNode(3, "bam", 2,
+				lineageNode(4, "vcf", 3),
+			),
+		),
+		lineageNode(5, "vcf", 1),
+	)
+	// 3 lists its ancestor 1 as a child
+	cyclic := lineageNode(1, "fastq", 0, lineageNode(2, "bam", 1, lineageNode(3, "vcf", 2)))
+	cyclic.Children[0].Children[0].Children = []*LineageNode{cyclic}
+
+	tests := []struct {
+		name        string
+		root        *LineageNode
+		wantCount   int
+		wantDepth   int
+		wantFlatten []int64
+	}{
+		{name: "balanced", root: balanced, wantCount: 6, wantDepth: 2, wantFlatten: []int64{1, 2, 4, 5, 3, 6, 7}},
+		{name: "unbalanced", root: unbalanced, wantCount: 4, wantDepth: 3, wantFlatten: []int64{1, 2, 3, 4, 5}},
+		{name: "leaf", root: lineageNode(9, "vcf", 0), wantCount: 0, wantDepth: 0, wantFlatten: []int64{9}},
+		{name: "diamond stub skipped", root: diamondTree(), wantCount: 3, wantDepth: 2, wantFlatten: []int64{1, 2, 4, 3}},
+		{name: "cycle", root: cyclic, wantCount: 2, wantDepth: 2, wantFlatten: []int64{1, 2, 3}},
+		{name: "nil", wantCount: 0, wantDepth: 0, wantFlatten: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.root.CountDescendants(); got != tt.wantCount {
+				t.Errorf("CountDescendants = %d, want %d", got, tt.wantCount)
+			}
+			if got := tt.root.MaxDepth(); got != tt.wantDepth {
+				t.Errorf("MaxDepth = %d, want %d", got, tt.wantDepth)
+			}
+			if got := nodeIDs(tt.root.Flatten()); !reflect.DeepEqual(got, tt.wantFlatten) {
+				t.Errorf("Flatten = %v, want %v", got, tt.wantFlatten)
+			}
+		})
+	}
+}
+
+func TestDiffLineage(t *testing.T) {
+	// base is 1 → {2 → 4, 3}
+	base := func() *LineageNode {
+		return lineageNode(1, "fastq", 0, lineageNode(2, "bam", 1, lineageNode(4, "vcf", 2)), lineageNode(3, "bam", 1))
+	}
+	relabeled := base()
+	relabeled.Children[1].DataType = "cram"
+	moved := lineageNode(1, "fastq", 0, lineageNode(2, "bam", 1), lineageNode(3, "bam", 1, lineageNode(4, "vcf", 2)))
+	reorderedDiamond := diamondTree()
+	reorderedDiamond.Children[0], reorderedDiamond.Children[1] = reorderedDiamond.Children[1], reorderedDiamond.Children[0]
+
+	tests := []struct {
+		name        string
+		old, new    *LineageNode
+		wantAdded   []int64
+		wantRemoved []int64
+		wantChanged []int64
+	}{
+		{name: "unchanged", old: base(), new: base()},
+		{
+			name:      "added child",
+			old:       base(),
+			new:       lineageNode(1, "fastq", 0, lineageNode(2, "bam", 1, lineageNode(4, "vcf", 2), lineageNode(5, "vcf", 2)), lineageNode(3, "bam", 1)),
+			wantAdded: []int64{5},
+		},
+		{
+			name:        "removed child",
+			old:         base(),
+			new:         lineageNode(1, "fastq", 0, lineageNode(2, "bam", 1), lineageNode(3, "bam", 1)),
+			wantRemoved: []int64{4},
+		},
+		{name: "removed subtree", old: base(), new: lineageNode(1, "fastq", 0, lineageNode(3, "bam", 1)), wantRemoved: []int64{2, 4}},
+		{name: "reordered", old: base(), new: lineageNode(1, "fastq", 0, lineageNode(3, "bam", 1), lineageNode(2, "bam", 1, lineageNode(4, "vcf", 2)))},
+		{name: "reordered diamond", old: diamondTree(), new: reorderedDiamond},
+		{name: "changed data type", old: base(), new: relabeled, wantChanged: []int64{3}},
+		{name: "moved to another parent", old: base(), new: moved, wantChanged: []int64{4}},
+		{name: "no old tree", new: base(), wantAdded: []int64{1, 2, 3, 4}},
+		{name: "no new tree", old: base(), wantRemoved: []int64{1, 2, 3, 4}},
+		{name: "both nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffLineage(tt.old, tt.new)
+			for _, got := range []struct {
+				name string
+				ids  []*big.Int
+				want []int64
+			}{
+				{"added", diff.Added, tt.wantAdded},
+				{"removed", diff.Removed, tt.wantRemoved},
+				{"changed", diff.Changed, tt.wantChanged},
+			} {
+				want := got.want
+				if want == nil {
+					want = []int64{}
+				}
+				if ids := tokenInts(got.ids); !reflect.DeepEqual(ids, want) {
+					t.Errorf("%s = %v, want %v", got.name, ids, want)
+				}
+			}
+
+			wantEmpty := len(tt.wantAdded)+len(tt.wantRemoved)+len(tt.wantChanged) == 0
+			if diff.IsEmpty() != wantEmpty {
+				t.Errorf("IsEmpty = %v, want %v", diff.IsEmpty(), wantEmpty)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package chainrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend is the chain access needed by BioIPManager and ConsentChecker
+// *ethclient.Client satisfies it, as do simulated backends used in tests
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// rpcClientProvider is implemented by backends backed by a JSON-RPC connection
+type rpcClientProvider interface {
+	Client() *rpc.Client
+}
+
+// rpcClientDialer is implemented by backends that connect to a JSON-RPC endpoint on demand
+type rpcClientDialer interface {
+	Client(ctx context.Context) *rpc.Client
+}
+
+// rpcClient returns b's JSON-RPC client, or nil if it has none or cannot connect within ctx
+func rpcClient(ctx context.Context, b Backend) *rpc.Client {
+	switch provider := b.(type) {
+	case rpcClientProvider:
+		return provider.Client()
+	case rpcClientDialer:
+		return provider.Client(ctx)
+	}
+	return nil
+}
+
+// blockNumberReader is implemented by backends that can report the head block directly
+type blockNumberReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// BlockNumber returns the current head block of b
+// Backends without a BlockNumber method fall back to the latest header
+func BlockNumber(ctx context.Context, b Backend) (uint64, error) {
+	if reader, ok := b.(blockNumberReader); ok {
+		return reader.BlockNumber(ctx)
+	}
+
+	header, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// BatchCallContract runs eth_call with each calldata against one contract
+// JSON-RPC backends send a single batch request; others fall back to sequential calls
+// Per-call failures are returned in errs; err is only set when the batch itself fails
+func BatchCallContract(ctx context.Context, b Backend, to common.Address, calldata [][]byte) (results [][]byte, errs []error, err error) {
+	results = make([][]byte, len(calldata))
+	errs = make([]error, len(calldata))
+
+	client := rpcClient(ctx, b)
+	if client == nil {
+		for i, data := range calldata {
+			results[i], errs[i] = b.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+		}
+		return results, errs, nil
+	}
+
+	// A batch is a single request, so it takes a single rate limit token
+	if limited, ok := b.(*rateLimitedBackend); ok {
+		if err := limited.limiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	raw := make([]hexutil.Bytes, len(calldata))
+	batch := make([]rpc.BatchElem, len(calldata))
+	for i, data := range calldata {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   to,
+				"data": hexutil.Bytes(data),
+			}, "latest"},
+			Result: &raw[i],
+		}
+	}
+
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		return nil, nil, fmt.Errorf("failed to send batch: %w", err)
+	}
+
+	for i, elem := range batch {
+		results[i], errs[i] = raw[i], elem.Error
+	}
+	return results, errs, nil
+}
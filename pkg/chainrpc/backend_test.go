@@ -0,0 +1,104 @@
+package chainrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeEth is an in-process eth namespace that echoes calldata back reversed
+// Calldata of 0xdead fails
+type fakeEth struct{}
+
+func (f *fakeEth) Call(ctx context.Context, args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	// ethclient sends calldata as input; raw batches send it as data
+	field, ok := args["input"].(string)
+	if !ok {
+		field, _ = args["data"].(string)
+	}
+	data, err := hexutil.Decode(field)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(data, []byte{0xde, 0xad}) {
+		return nil, errors.New("execution reverted")
+	}
+
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out, nil
+}
+
+// newFakeClient returns an ethclient talking to an in-process fakeEth
+func newFakeClient(t *testing.T) (*ethclient.Client, *fakeEth) {
+	t.Helper()
+
+	eth := &fakeEth{}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", eth); err != nil {
+		t.Fatalf("failed to register fake eth: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+	return client, eth
+}
+
+// sequentialBackend hides the JSON-RPC client of the backend it wraps
+type sequentialBackend struct {
+	Backend
+}
+
+func TestBatchCallContract(t *testing.T) {
+	client, _ := newFakeClient(t)
+
+	backends := []struct {
+		name    string
+		backend Backend
+	}{
+		{name: "json-rpc batch", backend: client},
+		{name: "sequential fallback", backend: sequentialBackend{client}},
+		{name: "rate limited", backend: RateLimit(client, NewRateLimiter(1000, 10))},
+	}
+	calldata := [][]byte{{0x01, 0x02}, {0xde, 0xad}, {0x03}}
+
+	for _, tt := range backends {
+		t.Run(tt.name, func(t *testing.T) {
+			results, errs, err := BatchCallContract(context.Background(), tt.backend, common.Address{1}, calldata)
+			if err != nil {
+				t.Fatalf("BatchCallContract failed: %v", err)
+			}
+			if len(results) != len(calldata) || len(errs) != len(calldata) {
+				t.Fatalf("got %d results and %d errors, want %d", len(results), len(errs), len(calldata))
+			}
+
+			if errs[0] != nil || !bytes.Equal(results[0], []byte{0x02, 0x01}) {
+				t.Errorf("call 0: got %x, %v", results[0], errs[0])
+			}
+			if errs[1] == nil {
+				t.Error("call 1: expected a per-call error")
+			}
+			if errs[2] != nil || !bytes.Equal(results[2], []byte{0x03}) {
+				t.Errorf("call 2: got %x, %v", results[2], errs[2])
+			}
+		})
+	}
+}
+
+func TestBatchCallContractClosed(t *testing.T) {
+	client, _ := newFakeClient(t)
+	client.Close()
+
+	if _, _, err := BatchCallContract(context.Background(), client, common.Address{1}, [][]byte{{0x01}}); err == nil {
+		t.Error("expected the batch itself to fail on a closed client")
+	}
+}
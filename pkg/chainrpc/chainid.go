@@ -0,0 +1,41 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// chainIDReader is implemented by backends that can report their chain ID
+type chainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// CheckChainID verifies that backend is connected to the chain with the expected ID
+// This guards EIP-155 signers against broadcasting to a different network than intended
+// A nil expected ID or a backend that cannot report its chain ID skips the check
+func CheckChainID(ctx context.Context, backend Backend, expected *big.Int) error {
+	if expected == nil {
+		return nil
+	}
+
+	reader, ok := backend.(chainIDReader)
+	if !ok {
+		return nil
+	}
+
+	actual, err := reader.ChainID(ctx)
+	if errors.Is(err, errNoChainID) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	if actual.Cmp(expected) != 0 {
+		return fmt.Errorf("%w: backend reports %s, expected %s", ErrChainIDMismatch, actual, expected)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// chainIDBackend reports a fixed chain ID or error
+type chainIDBackend struct {
+	Backend
+	id  *big.Int
+	err error
+}
+
+func (b *chainIDBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.id, b.err
+}
+
+func TestCheckChainID(t *testing.T) {
+	story := big.NewInt(1514)
+	down := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		backend  Backend
+		expected *big.Int
+		wantErr  error
+	}{
+		{name: "match", backend: &chainIDBackend{id: big.NewInt(1514)}, expected: story},
+		{name: "mismatch", backend: &chainIDBackend{id: big.NewInt(1)}, expected: story, wantErr: ErrChainIDMismatch},
+		{name: "no expectation", backend: &chainIDBackend{id: big.NewInt(1)}},
+		{name: "cannot report", backend: sequentialBackend{}, expected: story},
+		{name: "rate limited cannot report", backend: RateLimit(sequentialBackend{}, NewRateLimiter(1000, 10)), expected: story},
+		{name: "rate limited mismatch", backend: RateLimit(&chainIDBackend{id: big.NewInt(1)}, NewRateLimiter(1000, 10)), expected: story, wantErr: ErrChainIDMismatch},
+		{name: "lookup fails", backend: &chainIDBackend{err: down}, expected: story, wantErr: down},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckChainID(context.Background(), tt.backend, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
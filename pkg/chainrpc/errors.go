@@ -0,0 +1,14 @@
+package chainrpc
+
+import "errors"
+
+var (
+	// ErrRPCConnect is returned when an RPC endpoint cannot be dialed
+	ErrRPCConnect = errors.New("failed to connect to RPC")
+
+	// ErrInvalidEndpoint is returned when an RPC endpoint URL is empty or cannot be dialed by go-ethereum
+	ErrInvalidEndpoint = errors.New("invalid RPC endpoint")
+
+	// ErrChainIDMismatch is returned when a backend's chain ID differs from the named chain's
+	ErrChainIDMismatch = errors.New("chain ID mismatch")
+)
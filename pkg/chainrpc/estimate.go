@@ -0,0 +1,51 @@
+package chainrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasEstimate is the result of dry-running a contract write
+type GasEstimate struct {
+	From common.Address
+	To   common.Address
+	Data []byte // ABI-encoded calldata
+	Gas  uint64
+}
+
+// EstimateGas packs a contract call and estimates its gas as if sent by from
+// Nothing is signed or broadcast; a call that would revert returns an error
+func EstimateGas(
+	ctx context.Context,
+	backend Backend,
+	from common.Address,
+	to common.Address,
+	contractABI abi.ABI,
+	method string,
+	args ...interface{},
+) (*GasEstimate, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+
+	gas, err := backend.EstimateGas(ctx, ethereum.CallMsg{
+		From: from,
+		To:   &to,
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas for %s: %w", method, err)
+	}
+
+	return &GasEstimate{
+		From: from,
+		To:   to,
+		Data: data,
+		Gas:  gas,
+	}, nil
+}
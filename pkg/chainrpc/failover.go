@@ -0,0 +1,227 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultFailoverThreshold is the number of consecutive transient failures after which
+// a Failover moves on to its next endpoint
+const DefaultFailoverThreshold = 3
+
+// Failover is a Backend spreading requests over an ordered list of RPC endpoints
+// Requests go to the current endpoint until it fails to dial or returns threshold transient
+// errors in a row, then to the next one, wrapping around after the last
+// It is safe for concurrent use
+type Failover struct {
+	mu        sync.Mutex
+	urls      []string
+	clients   []*ethclient.Client // Dialed clients, indexed like urls
+	current   int                 // Index of the endpoint requests are sent to
+	failures  int                 // Consecutive transient failures of the current endpoint
+	threshold int
+}
+
+// NewFailover returns a backend over urls, tried in order
+// Endpoints are dialed lazily, but each URL is checked up front; a threshold below 1 uses
+// DefaultFailoverThreshold
+func NewFailover(urls []string, threshold int) (*Failover, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%w: at least one RPC endpoint is required", ErrInvalidEndpoint)
+	}
+	for _, raw := range urls {
+		if err := checkEndpoint(raw); err != nil {
+			return nil, err
+		}
+	}
+	if threshold < 1 {
+		threshold = DefaultFailoverThreshold
+	}
+
+	return &Failover{
+		urls:      append([]string(nil), urls...),
+		clients:   make([]*ethclient.Client, len(urls)),
+		threshold: threshold,
+	}, nil
+}
+
+// checkEndpoint rejects URLs rpc.DialContext cannot dial; a URL without a scheme is an IPC path
+func checkEndpoint(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return fmt.Errorf("%w: empty URL", ErrInvalidEndpoint)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEndpoint, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss", "stdio", "":
+		return nil
+	default:
+		return fmt.Errorf("%w: %s: unsupported scheme %q", ErrInvalidEndpoint, raw, u.Scheme)
+	}
+}
+
+// URL returns the endpoint requests are currently sent to
+func (f *Failover) URL() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.urls[f.current]
+}
+
+// Close closes every dialed client
+func (f *Failover) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, client := range f.clients {
+		if client != nil {
+			client.Close()
+			f.clients[i] = nil
+		}
+	}
+}
+
+// client returns the current endpoint's client, dialing it if needed
+// Endpoints that fail to dial are skipped; an error is returned only when none can be dialed
+func (f *Failover) client(ctx context.Context) (*ethclient.Client, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for range f.urls {
+		i := f.current
+		if f.clients[i] != nil {
+			return f.clients[i], i, nil
+		}
+
+		client, err := ethclient.DialContext(ctx, f.urls[i])
+		if err == nil {
+			f.clients[i] = client
+			return client, i, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", f.urls[i], err))
+		f.advance()
+	}
+
+	return nil, 0, fmt.Errorf("%w: %w", ErrRPCConnect, errors.Join(errs...))
+}
+
+// record notes the outcome of a request sent to endpoint i
+func (f *Failover) record(i int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Another request may already have moved on from this endpoint
+	if i != f.current {
+		return
+	}
+
+	if !IsTransient(err) {
+		f.failures = 0
+		return
+	}
+
+	f.failures++
+	if f.failures >= f.threshold {
+		f.advance()
+	}
+}
+
+// advance moves to the next endpoint; the caller holds f.mu
+func (f *Failover) advance() {
+	f.current = (f.current + 1) % len(f.urls)
+	f.failures = 0
+}
+
+// failoverDo runs fn against the current endpoint and records its outcome
+func failoverDo[T any](ctx context.Context, f *Failover, fn func(client *ethclient.Client) (T, error)) (T, error) {
+	client, i, err := f.client(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	out, err := fn(client)
+	f.record(i, err)
+	return out, err
+}
+
+func (f *Failover) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) ([]byte, error) { return c.CodeAt(ctx, contract, blockNumber) })
+}
+
+func (f *Failover) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) ([]byte, error) { return c.CallContract(ctx, call, blockNumber) })
+}
+
+func (f *Failover) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (*types.Header, error) { return c.HeaderByNumber(ctx, number) })
+}
+
+func (f *Failover) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) ([]byte, error) { return c.PendingCodeAt(ctx, account) })
+}
+
+func (f *Failover) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (uint64, error) { return c.PendingNonceAt(ctx, account) })
+}
+
+func (f *Failover) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (*big.Int, error) { return c.SuggestGasPrice(ctx) })
+}
+
+func (f *Failover) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (*big.Int, error) { return c.SuggestGasTipCap(ctx) })
+}
+
+func (f *Failover) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (uint64, error) { return c.EstimateGas(ctx, call) })
+}
+
+func (f *Failover) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := failoverDo(ctx, f, func(c *ethclient.Client) (struct{}, error) { return struct{}{}, c.SendTransaction(ctx, tx) })
+	return err
+}
+
+func (f *Failover) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) ([]types.Log, error) { return c.FilterLogs(ctx, query) })
+}
+
+func (f *Failover) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (ethereum.Subscription, error) { return c.SubscribeFilterLogs(ctx, query, ch) })
+}
+
+func (f *Failover) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (*types.Receipt, error) { return c.TransactionReceipt(ctx, txHash) })
+}
+
+func (f *Failover) BlockNumber(ctx context.Context) (uint64, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (uint64, error) { return c.BlockNumber(ctx) })
+}
+
+func (f *Failover) ChainID(ctx context.Context) (*big.Int, error) {
+	return failoverDo(ctx, f, func(c *ethclient.Client) (*big.Int, error) { return c.ChainID(ctx) })
+}
+
+// Client returns the current endpoint's JSON-RPC client, or nil if none can be dialed within ctx
+func (f *Failover) Client(ctx context.Context) *rpc.Client {
+	client, _, err := f.client(ctx)
+	if err != nil {
+		return nil
+	}
+	return client.Client()
+}
@@ -0,0 +1,114 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestNewFailover(t *testing.T) {
+	tests := []struct {
+		name    string
+		urls    []string
+		wantErr bool
+	}{
+		{name: "http", urls: []string{"http://localhost:8545", "https://rpc.example.org"}},
+		{name: "websocket", urls: []string{"wss://rpc.example.org/ws"}},
+		{name: "IPC path", urls: []string{"/var/run/geth.ipc"}},
+		{name: "no endpoints", wantErr: true},
+		{name: "empty URL", urls: []string{"http://localhost:8545", " "}, wantErr: true},
+		{name: "unsupported scheme", urls: []string{"ftp://rpc.example.org"}, wantErr: true},
+		{name: "malformed URL", urls: []string{"http://[::1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFailover(tt.urls, 0)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEndpoint) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidEndpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewFailover failed: %v", err)
+			}
+			defer f.Close()
+			if f.URL() != tt.urls[0] {
+				t.Errorf("starts at %s, want %s", f.URL(), tt.urls[0])
+			}
+		})
+	}
+}
+
+// newFakeEndpoint serves fakeEth over HTTP
+func newFakeEndpoint(t *testing.T) string {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &fakeEth{}); err != nil {
+		t.Fatalf("failed to register fake eth: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	endpoint := httptest.NewServer(server)
+	t.Cleanup(endpoint.Close)
+	return endpoint.URL
+}
+
+func TestFailoverMovesOn(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := newFakeEndpoint(t)
+
+	f, err := NewFailover([]string{down.URL, up}, 2)
+	if err != nil {
+		t.Fatalf("NewFailover failed: %v", err)
+	}
+	defer f.Close()
+
+	to := common.HexToAddress("0x01")
+	call := ethereum.CallMsg{To: &to, Data: []byte{1, 2}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		wantURL string
+	}{
+		{name: "first failure", wantErr: true, wantURL: down.URL},
+		{name: "threshold reached", wantErr: true, wantURL: up},
+		{name: "next endpoint", wantURL: up},
+	}
+	for _, tt := range tests {
+		out, err := f.CallContract(ctx, call, nil)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: got error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr && string(out) != string([]byte{2, 1}) {
+			t.Errorf("%s: got %x, want 0201", tt.name, out)
+		}
+		if f.URL() != tt.wantURL {
+			t.Errorf("%s: at %s, want %s", tt.name, f.URL(), tt.wantURL)
+		}
+	}
+
+	// Batches use the current endpoint's client under the caller's context
+	results, errs, err := BatchCallContract(ctx, f, to, [][]byte{{3, 4}, {0xde, 0xad}})
+	if err != nil {
+		t.Fatalf("BatchCallContract failed: %v", err)
+	}
+	if string(results[0]) != string([]byte{4, 3}) || errs[0] != nil {
+		t.Errorf("call 0 = %x, %v; want 0403", results[0], errs[0])
+	}
+	if errs[1] == nil {
+		t.Error("call 1 should have reverted")
+	}
+}
@@ -0,0 +1,130 @@
+package chainrpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// FeeMode selects how a FeeStrategy prices transactions
+type FeeMode int
+
+const (
+	// FeeDefault leaves pricing to the signer's TransactOpts and go-ethereum's defaults
+	FeeDefault FeeMode = iota
+
+	// FeeLegacy sends legacy transactions at a fixed gas price
+	FeeLegacy
+
+	// FeeDynamic sends EIP-1559 transactions with a fixed tip and fee cap
+	FeeDynamic
+
+	// FeeSuggest asks the node for a tip and base fee, falling back to its gas price on
+	// chains without EIP-1559
+	FeeSuggest
+)
+
+// FeeStrategy populates the fee fields of a transaction before it is sent
+// Fees already set on the signer's TransactOpts always take precedence
+type FeeStrategy struct {
+	Mode      FeeMode
+	GasPrice  *big.Int // FeeLegacy gas price
+	GasTipCap *big.Int // FeeDynamic priority fee
+	GasFeeCap *big.Int // FeeDynamic fee cap; with FeeSuggest, an optional ceiling on the suggested fee
+}
+
+// LegacyFees returns a strategy sending legacy transactions at gasPrice
+func LegacyFees(gasPrice *big.Int) FeeStrategy {
+	return FeeStrategy{Mode: FeeLegacy, GasPrice: gasPrice}
+}
+
+// DynamicFees returns a strategy sending EIP-1559 transactions with the given tip and fee cap
+func DynamicFees(tipCap, feeCap *big.Int) FeeStrategy {
+	return FeeStrategy{Mode: FeeDynamic, GasTipCap: tipCap, GasFeeCap: feeCap}
+}
+
+// SuggestedFees returns a strategy pricing transactions from the node's suggestions
+// A non-nil maxFeeCap bounds the fee cap (or gas price) that will be paid
+func SuggestedFees(maxFeeCap *big.Int) FeeStrategy {
+	return FeeStrategy{Mode: FeeSuggest, GasFeeCap: maxFeeCap}
+}
+
+// Apply sets the fee fields of opts according to the strategy
+// opts is left untouched if any of its fee fields is already set
+func (s FeeStrategy) Apply(ctx context.Context, backend bind.ContractBackend, opts *bind.TransactOpts) error {
+	if opts.GasPrice != nil || opts.GasTipCap != nil || opts.GasFeeCap != nil {
+		return nil
+	}
+
+	switch s.Mode {
+	case FeeDefault:
+		return nil
+
+	case FeeLegacy:
+		if s.GasPrice == nil {
+			return fmt.Errorf("legacy fee strategy requires a gas price")
+		}
+		opts.GasPrice = new(big.Int).Set(s.GasPrice)
+		return nil
+
+	case FeeDynamic:
+		if s.GasTipCap == nil || s.GasFeeCap == nil {
+			return fmt.Errorf("dynamic fee strategy requires a tip cap and a fee cap")
+		}
+		if s.GasFeeCap.Cmp(s.GasTipCap) < 0 {
+			return fmt.Errorf("fee cap %s is below tip cap %s", s.GasFeeCap, s.GasTipCap)
+		}
+		opts.GasTipCap = new(big.Int).Set(s.GasTipCap)
+		opts.GasFeeCap = new(big.Int).Set(s.GasFeeCap)
+		return nil
+
+	case FeeSuggest:
+		return s.applySuggested(ctx, backend, opts)
+
+	default:
+		return fmt.Errorf("unknown fee mode: %d", s.Mode)
+	}
+}
+
+// applySuggested prices opts from the node, using the same fee cap formula as go-ethereum
+func (s FeeStrategy) applySuggested(ctx context.Context, backend bind.ContractBackend, opts *bind.TransactOpts) error {
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	if head.BaseFee == nil {
+		price, err := backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		opts.GasPrice = s.ceiling(price)
+		return nil
+	}
+
+	tip, err := backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+	}
+
+	// Leave room for the base fee to double before the transaction is priced out
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	feeCap = s.ceiling(feeCap)
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+
+	opts.GasTipCap = tip
+	opts.GasFeeCap = feeCap
+	return nil
+}
+
+// ceiling bounds fee by the strategy's GasFeeCap, if set
+func (s FeeStrategy) ceiling(fee *big.Int) *big.Int {
+	if s.GasFeeCap != nil && fee.Cmp(s.GasFeeCap) > 0 {
+		return new(big.Int).Set(s.GasFeeCap)
+	}
+	return fee
+}
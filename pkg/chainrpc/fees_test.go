@@ -0,0 +1,115 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// feeBackend suggests fixed fees; a nil baseFee models a chain without EIP-1559
+type feeBackend struct {
+	Backend
+	baseFee  *big.Int
+	gasPrice *big.Int
+	tip      *big.Int
+	err      error
+}
+
+func (b *feeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &types.Header{Number: big.NewInt(42), BaseFee: b.baseFee}, nil
+}
+
+func (b *feeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *feeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.tip, nil
+}
+
+func TestFeeStrategyApply(t *testing.T) {
+	london := &feeBackend{baseFee: big.NewInt(10), tip: big.NewInt(2)}
+	legacy := &feeBackend{gasPrice: big.NewInt(30)}
+
+	tests := []struct {
+		name     string
+		strategy FeeStrategy
+		backend  *feeBackend
+		preset   *big.Int // Gas price already set on the signer
+		wantGas  int64
+		wantTip  int64
+		wantCap  int64
+		wantErr  bool
+	}{
+		{name: "default", strategy: FeeStrategy{}, backend: london},
+		{name: "legacy", strategy: LegacyFees(big.NewInt(25)), backend: london, wantGas: 25},
+		{name: "legacy without a price", strategy: FeeStrategy{Mode: FeeLegacy}, backend: london, wantErr: true},
+		{name: "dynamic", strategy: DynamicFees(big.NewInt(3), big.NewInt(40)), backend: london, wantTip: 3, wantCap: 40},
+		{name: "dynamic cap below tip", strategy: DynamicFees(big.NewInt(3), big.NewInt(2)), backend: london, wantErr: true},
+		{name: "dynamic without a cap", strategy: FeeStrategy{Mode: FeeDynamic, GasTipCap: big.NewInt(3)}, backend: london, wantErr: true},
+		{name: "suggested", strategy: SuggestedFees(nil), backend: london, wantTip: 2, wantCap: 22},
+		{name: "suggested under a ceiling", strategy: SuggestedFees(big.NewInt(15)), backend: london, wantTip: 2, wantCap: 15},
+		{name: "ceiling below the tip", strategy: SuggestedFees(big.NewInt(1)), backend: london, wantTip: 1, wantCap: 1},
+		{name: "suggested without EIP-1559", strategy: SuggestedFees(nil), backend: legacy, wantGas: 30},
+		{name: "suggested price under a ceiling", strategy: SuggestedFees(big.NewInt(20)), backend: legacy, wantGas: 20},
+		{name: "header fails", strategy: SuggestedFees(nil), backend: &feeBackend{err: errors.New("rpc down")}, wantErr: true},
+		{name: "signer fees win", strategy: LegacyFees(big.NewInt(25)), backend: london, preset: big.NewInt(7), wantGas: 7},
+		{name: "unknown mode", strategy: FeeStrategy{Mode: FeeMode(99)}, backend: london, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &bind.TransactOpts{GasPrice: tt.preset}
+			err := tt.strategy.Apply(context.Background(), tt.backend, opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			for _, field := range []struct {
+				name string
+				got  *big.Int
+				want int64
+			}{
+				{"GasPrice", opts.GasPrice, tt.wantGas},
+				{"GasTipCap", opts.GasTipCap, tt.wantTip},
+				{"GasFeeCap", opts.GasFeeCap, tt.wantCap},
+			} {
+				if field.want == 0 {
+					if field.got != nil {
+						t.Errorf("%s = %s, want unset", field.name, field.got)
+					}
+					continue
+				}
+				if field.got == nil || field.got.Int64() != field.want {
+					t.Errorf("%s = %v, want %d", field.name, field.got, field.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFeeStrategyCopiesValues(t *testing.T) {
+	price := big.NewInt(25)
+	opts := &bind.TransactOpts{}
+	if err := LegacyFees(price).Apply(context.Background(), &feeBackend{}, opts); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// Later changes to the signer's fields must not leak into the shared strategy
+	opts.GasPrice.SetInt64(1)
+	if price.Int64() != 25 {
+		t.Errorf("strategy gas price changed to %s", price)
+	}
+}
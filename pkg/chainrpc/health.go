@@ -0,0 +1,45 @@
+package chainrpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// CheckHealth verifies backend answers ChainID and BlockNumber and is on the expected chain
+func CheckHealth(ctx context.Context, backend Backend, expected *big.Int) error {
+	if err := CheckChainID(ctx, backend, expected); err != nil {
+		return err
+	}
+
+	if _, err := BlockNumber(ctx, backend); err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	return nil
+}
+
+// HealthCheckAll runs check for every chain concurrently and collects the results
+// A chain maps to nil when healthy; ctx bounds all checks
+func HealthCheckAll(ctx context.Context, chains []string, check func(ctx context.Context, chain string) error) map[string]error {
+	results := make(map[string]error, len(chains))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chain := range chains {
+		wg.Add(1)
+		go func(chain string) {
+			defer wg.Done()
+
+			err := check(ctx, chain)
+
+			mu.Lock()
+			results[chain] = err
+			mu.Unlock()
+		}(chain)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,101 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+// headBackend reports a fixed chain ID and head block, or errors
+type headBackend struct {
+	chainIDBackend
+	headErr error
+}
+
+func (b *headBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return 42, b.headErr
+}
+
+func TestCheckHealth(t *testing.T) {
+	story := big.NewInt(1514)
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		backend Backend
+		wantErr error
+	}{
+		{name: "healthy", backend: &headBackend{chainIDBackend: chainIDBackend{id: story}}},
+		{name: "wrong chain", backend: &headBackend{chainIDBackend: chainIDBackend{id: big.NewInt(1)}}, wantErr: ErrChainIDMismatch},
+		{name: "chain ID fails", backend: &headBackend{chainIDBackend: chainIDBackend{err: down}}, wantErr: down},
+		{name: "head fails", backend: &headBackend{chainIDBackend: chainIDBackend{id: story}, headErr: down}, wantErr: down},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckHealth(context.Background(), tt.backend, story)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthCheckAll(t *testing.T) {
+	chains := []string{"story", "base", "polygon"}
+	down := errors.New("connection refused")
+
+	// Every check waits for all of them to start, so this only finishes if they run concurrently
+	var started sync.WaitGroup
+	started.Add(len(chains))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := HealthCheckAll(ctx, chains, func(ctx context.Context, chain string) error {
+		started.Done()
+		waited := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(waited)
+		}()
+
+		select {
+		case <-waited:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if chain == "base" {
+			return down
+		}
+		return nil
+	})
+
+	if len(got) != len(chains) {
+		t.Fatalf("got %d results, want %d", len(got), len(chains))
+	}
+	for _, chain := range chains {
+		want := error(nil)
+		if chain == "base" {
+			want = down
+		}
+		if !errors.Is(got[chain], want) {
+			t.Errorf("%s: got %v, want %v", chain, got[chain], want)
+		}
+	}
+}
+
+func TestHealthCheckAllDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	got := HealthCheckAll(ctx, []string{"story"}, func(ctx context.Context, chain string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(got["story"], context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", got["story"], context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,20 @@
+package chainrpc
+
+// Logger receives structured events from BioIPManager and ConsentChecker
+// keyvals alternate between string keys and arbitrary values
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// NopLogger returns a Logger that discards all events
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
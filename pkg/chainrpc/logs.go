@@ -0,0 +1,101 @@
+package chainrpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultLogChunk is the block span of each eth_getLogs request when scanning history
+const DefaultLogChunk = 10000
+
+// LogFilterFunc runs a single eth_getLogs query
+type LogFilterFunc func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+
+// tooManyResultsMessages are the error fragments providers use to reject an oversized log query
+var tooManyResultsMessages = []string{
+	"query returned more than",
+	"too many results",
+	"log response size exceeded",
+	"block range is too wide",
+	"block range too large",
+	"exceed maximum block range",
+	"is limited to a",
+}
+
+// IsTooManyResults reports whether err is a provider rejecting a log query as too large
+func IsTooManyResults(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range tooManyResultsMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanLogs runs query over its FromBlock-ToBlock range in spans of at most chunk blocks,
+// passing each span's logs to handler in block order
+// A span the provider rejects as too large is halved and retried, and the smaller span is
+// kept for the rest of the scan; a single block that is still rejected fails the scan
+func ScanLogs(
+	ctx context.Context,
+	filter LogFilterFunc,
+	query ethereum.FilterQuery,
+	chunk uint64,
+	handler func(logs []types.Log) error,
+) error {
+	if query.BlockHash != nil || query.FromBlock == nil || query.ToBlock == nil {
+		return fmt.Errorf("log scan requires a FromBlock and ToBlock")
+	}
+	if !query.FromBlock.IsUint64() || !query.ToBlock.IsUint64() {
+		return fmt.Errorf("invalid block range: %s-%s", query.FromBlock, query.ToBlock)
+	}
+	if chunk == 0 {
+		chunk = DefaultLogChunk
+	}
+
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	for from <= to {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := from + chunk - 1
+		if end > to || end < from {
+			end = to
+		}
+
+		q := query
+		q.FromBlock = new(big.Int).SetUint64(from)
+		q.ToBlock = new(big.Int).SetUint64(end)
+
+		logs, err := filter(ctx, q)
+		if err != nil {
+			if IsTooManyResults(err) && end > from {
+				chunk = (end - from + 1) / 2
+				continue
+			}
+			return fmt.Errorf("failed to filter logs in blocks %d-%d: %w", from, end, err)
+		}
+
+		if err := handler(logs); err != nil {
+			return err
+		}
+
+		if end == to {
+			break
+		}
+		from = end + 1
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestScanLogs(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to uint64
+		chunk    uint64
+		maxSpan  uint64 // Spans wider than this are rejected as too many results
+		want     []string
+		wantErr  bool
+	}{
+		{name: "single chunk", from: 0, to: 9, chunk: 100, maxSpan: 100, want: []string{"0-9"}},
+		{name: "chunked", from: 0, to: 24, chunk: 10, maxSpan: 100, want: []string{"0-9", "10-19", "20-24"}},
+		{name: "halved and kept", from: 0, to: 11, chunk: 8, maxSpan: 4, want: []string{"0-3", "4-7", "8-11"}},
+		{name: "single block rejected", from: 5, to: 5, chunk: 8, maxSpan: 0, wantErr: true},
+		{name: "default chunk", from: 0, to: DefaultLogChunk, maxSpan: DefaultLogChunk + 1, want: []string{fmt.Sprintf("0-%d", DefaultLogChunk-1), fmt.Sprintf("%d-%d", DefaultLogChunk, DefaultLogChunk)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spans []string
+			filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+				from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+				if to-from+1 > tt.maxSpan {
+					return nil, errors.New("query returned more than 10000 results")
+				}
+				spans = append(spans, fmt.Sprintf("%d-%d", from, to))
+				return []types.Log{{BlockNumber: from}}, nil
+			}
+
+			var blocks []uint64
+			query := ethereum.FilterQuery{FromBlock: new(big.Int).SetUint64(tt.from), ToBlock: new(big.Int).SetUint64(tt.to)}
+			err := ScanLogs(context.Background(), filter, query, tt.chunk, func(logs []types.Log) error {
+				for _, log := range logs {
+					blocks = append(blocks, log.BlockNumber)
+				}
+				return nil
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScanLogs failed: %v", err)
+			}
+			if !reflect.DeepEqual(spans, tt.want) {
+				t.Errorf("got spans %v, want %v", spans, tt.want)
+			}
+			if len(blocks) != len(tt.want) {
+				t.Errorf("handler saw %d chunks, want %d", len(blocks), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestScanLogsHandlerError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		calls++
+		return nil, nil
+	}
+
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(99)}
+	err := ScanLogs(context.Background(), filter, query, 10, func([]types.Log) error { return stop })
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("got %v after %d calls, want %v after 1", err, calls, stop)
+	}
+}
+
+func TestScanLogsRequiresRange(t *testing.T) {
+	filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) { return nil, nil }
+	handler := func([]types.Log) error { return nil }
+
+	if err := ScanLogs(context.Background(), filter, ethereum.FilterQuery{FromBlock: big.NewInt(0)}, 10, handler); err == nil {
+		t.Error("expected an error without a ToBlock")
+	}
+}
+
+func TestIsTooManyResults(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: errors.New("query returned more than 10000 results"), want: true},
+		{err: errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"), want: true},
+		{err: errors.New("eth_getLogs is limited to a 10,000 range"), want: true},
+		{err: fmt.Errorf("rpc: %w", errors.New("block range too large")), want: true},
+		{err: errors.New("connection refused")},
+		{err: nil},
+	}
+	for _, tt := range tests {
+		if got := IsTooManyResults(tt.err); got != tt.want {
+			t.Errorf("IsTooManyResults(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestScanLogsOtherErrors(t *testing.T) {
+	down := errors.New("connection refused")
+	calls := 0
+	filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		calls++
+		return nil, down
+	}
+
+	// Only oversized queries are narrowed; anything else fails the scan at once
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(99)}
+	err := ScanLogs(context.Background(), filter, query, 10, func([]types.Log) error { return nil })
+	if !errors.Is(err, down) || calls != 1 {
+		t.Errorf("got %v after %d calls, want %v after 1", err, calls, down)
+	}
+}
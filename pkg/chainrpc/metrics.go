@@ -0,0 +1,57 @@
+package chainrpc
+
+import "time"
+
+// Metric names reported to a MetricsSink
+const (
+	MetricRPCCalls      = "biofs_rpc_calls_total"
+	MetricRPCErrors     = "biofs_rpc_errors_total"
+	MetricRPCRetries    = "biofs_rpc_retries_total"
+	MetricRPCDuration   = "biofs_rpc_duration_seconds"
+	MetricCacheHits     = "biofs_consent_cache_hits_total"
+	MetricCacheMisses   = "biofs_consent_cache_misses_total"
+	MetricConsentDenied = "biofs_consent_denied_total"
+)
+
+// MetricsSink receives counters and histogram observations
+// Adapt it to Prometheus, OpenTelemetry, or any other metrics backend
+type MetricsSink interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
+
+// NopMetrics returns a MetricsSink that discards everything
+func NopMetrics() MetricsSink {
+	return nopMetrics{}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) IncCounter(string, map[string]string)                {}
+func (nopMetrics) ObserveHistogram(string, float64, map[string]string) {}
+
+// ObserveCall logs and records metrics for a finished RPC call that began at start
+func ObserveCall(logger Logger, metrics MetricsSink, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	labels := map[string]string{"method": method}
+
+	metrics.IncCounter(MetricRPCCalls, labels)
+	metrics.ObserveHistogram(MetricRPCDuration, elapsed.Seconds(), labels)
+
+	if err != nil {
+		metrics.IncCounter(MetricRPCErrors, labels)
+		logger.Error("rpc call failed", "method", method, "duration", elapsed, "error", err)
+		return
+	}
+
+	logger.Debug("rpc call", "method", method, "duration", elapsed)
+}
+
+// RetryHook returns an OnRetry hook that logs and counts retries of method
+func RetryHook(logger Logger, metrics MetricsSink, method string) func(attempt int, err error) {
+	labels := map[string]string{"method": method}
+	return func(attempt int, err error) {
+		metrics.IncCounter(MetricRPCRetries, labels)
+		logger.Info("retrying rpc", "method", method, "attempt", attempt, "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package chainrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingSink records metric names in the order they arrive
+type recordingSink struct {
+	names  []string
+	labels []map[string]string
+}
+
+func (s *recordingSink) IncCounter(name string, labels map[string]string) {
+	s.names = append(s.names, name)
+	s.labels = append(s.labels, labels)
+}
+
+func (s *recordingSink) ObserveHistogram(name string, value float64, labels map[string]string) {
+	s.names = append(s.names, name)
+	s.labels = append(s.labels, labels)
+}
+
+// levelLogger records the level of every event
+type levelLogger struct {
+	levels []string
+}
+
+func (l *levelLogger) Debug(string, ...interface{}) { l.levels = append(l.levels, "debug") }
+func (l *levelLogger) Info(string, ...interface{})  { l.levels = append(l.levels, "info") }
+func (l *levelLogger) Error(string, ...interface{}) { l.levels = append(l.levels, "error") }
+
+func TestObserveCall(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantNames  []string
+		wantLevels []string
+	}{
+		{name: "success", wantNames: []string{MetricRPCCalls, MetricRPCDuration}, wantLevels: []string{"debug"}},
+		{
+			name:       "failure",
+			err:        errors.New("connection refused"),
+			wantNames:  []string{MetricRPCCalls, MetricRPCDuration, MetricRPCErrors},
+			wantLevels: []string{"error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, logger := &recordingSink{}, &levelLogger{}
+			ObserveCall(logger, sink, "getBioIP", time.Now(), tt.err)
+
+			if !reflect.DeepEqual(sink.names, tt.wantNames) {
+				t.Errorf("metrics = %v, want %v", sink.names, tt.wantNames)
+			}
+			if !reflect.DeepEqual(logger.levels, tt.wantLevels) {
+				t.Errorf("log levels = %v, want %v", logger.levels, tt.wantLevels)
+			}
+			for i, labels := range sink.labels {
+				if labels["method"] != "getBioIP" {
+					t.Errorf("%s labels = %v", sink.names[i], labels)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryHook(t *testing.T) {
+	sink, logger := &recordingSink{}, &levelLogger{}
+	hook := RetryHook(logger, sink, "checkConsent")
+	hook(1, errors.New("timeout"))
+	hook(2, errors.New("timeout"))
+
+	if want := []string{MetricRPCRetries, MetricRPCRetries}; !reflect.DeepEqual(sink.names, want) {
+		t.Errorf("metrics = %v, want %v", sink.names, want)
+	}
+	if want := []string{"info", "info"}; !reflect.DeepEqual(logger.levels, want) {
+		t.Errorf("log levels = %v, want %v", logger.levels, want)
+	}
+}
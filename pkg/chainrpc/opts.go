@@ -0,0 +1,20 @@
+package chainrpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// FreshTransactOpts returns a copy of signer bound to ctx with its nonce, gas limit, and fees cleared
+// so each is looked up again when the transaction is sent
+func FreshTransactOpts(ctx context.Context, signer *bind.TransactOpts) *bind.TransactOpts {
+	opts := *signer
+	opts.Context = ctx
+	opts.Nonce = nil
+	opts.GasLimit = 0
+	opts.GasPrice = nil
+	opts.GasTipCap = nil
+	opts.GasFeeCap = nil
+	return &opts
+}
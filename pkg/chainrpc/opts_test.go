@@ -0,0 +1,42 @@
+package chainrpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFreshTransactOpts(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "call")
+	signer := &bind.TransactOpts{
+		From:      common.HexToAddress("0x00000000000000000000000000000000000000A1"),
+		Nonce:     big.NewInt(4),
+		Value:     big.NewInt(5),
+		GasLimit:  21000,
+		GasPrice:  big.NewInt(6),
+		GasTipCap: big.NewInt(7),
+		GasFeeCap: big.NewInt(8),
+		Context:   context.Background(),
+	}
+
+	got := FreshTransactOpts(ctx, signer)
+	if got == signer {
+		t.Fatal("returned the signer itself")
+	}
+	if got.From != signer.From || got.Value != signer.Value {
+		t.Errorf("sender or value not kept: %+v", got)
+	}
+	if got.Context.Value(ctxKey{}) != "call" {
+		t.Error("not bound to the call's context")
+	}
+	if got.Nonce != nil || got.GasLimit != 0 || got.GasPrice != nil || got.GasTipCap != nil || got.GasFeeCap != nil {
+		t.Errorf("nonce, gas, or fees kept: %+v", got)
+	}
+	if signer.Nonce.Int64() != 4 || signer.GasLimit != 21000 || signer.Context == ctx {
+		t.Errorf("signer modified: %+v", signer)
+	}
+}
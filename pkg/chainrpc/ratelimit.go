@@ -0,0 +1,206 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errNoChainID is returned by a rate-limited backend whose underlying backend cannot report its chain ID
+var errNoChainID = errors.New("backend does not report its chain ID")
+
+// RateLimiter is a token bucket bounding the request rate to one RPC endpoint
+// It is safe for concurrent use
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64 // Bucket capacity
+	tokens float64 // Tokens available; negative while requests are waiting
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rps requests per second with bursts of up to burst
+// A burst below 1 is treated as 1
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+// A request abandoned because ctx was done returns its token to the bucket
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// RateLimit wraps backend so each request first waits on limiter
+// A nil limiter returns backend unchanged
+func RateLimit(backend Backend, limiter *RateLimiter) Backend {
+	if limiter == nil {
+		return backend
+	}
+	return &rateLimitedBackend{Backend: backend, limiter: limiter}
+}
+
+// rateLimitedBackend is a Backend whose requests are paced by a RateLimiter
+type rateLimitedBackend struct {
+	Backend
+	limiter *RateLimiter
+}
+
+func (b *rateLimitedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.CodeAt(ctx, contract, blockNumber)
+}
+
+func (b *rateLimitedBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.CallContract(ctx, call, blockNumber)
+}
+
+func (b *rateLimitedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.HeaderByNumber(ctx, number)
+}
+
+func (b *rateLimitedBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.PendingCodeAt(ctx, account)
+}
+
+func (b *rateLimitedBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return b.Backend.PendingNonceAt(ctx, account)
+}
+
+func (b *rateLimitedBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.SuggestGasPrice(ctx)
+}
+
+func (b *rateLimitedBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.SuggestGasTipCap(ctx)
+}
+
+func (b *rateLimitedBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return b.Backend.EstimateGas(ctx, call)
+}
+
+func (b *rateLimitedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+func (b *rateLimitedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs waits only for the subscription request; pushed logs are not limited
+func (b *rateLimitedBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (b *rateLimitedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+// BlockNumber lets BlockNumber use the underlying backend's cheaper call when it has one
+func (b *rateLimitedBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	if reader, ok := b.Backend.(blockNumberReader); ok {
+		if err := b.limiter.Wait(ctx); err != nil {
+			return 0, err
+		}
+		return reader.BlockNumber(ctx)
+	}
+	return BlockNumber(ctx, b.Backend)
+}
+
+// ChainID reports errNoChainID when the underlying backend cannot report its chain ID
+func (b *rateLimitedBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	reader, ok := b.Backend.(chainIDReader)
+	if !ok {
+		return nil, errNoChainID
+	}
+	if err := b.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return reader.ChainID(ctx)
+}
+
+// Client exposes the underlying JSON-RPC client so batches still go out as one request
+func (b *rateLimitedBackend) Client(ctx context.Context) *rpc.Client {
+	return rpcClient(ctx, b.Backend)
+}
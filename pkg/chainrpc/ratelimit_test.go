@@ -0,0 +1,115 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		limiter  *RateLimiter
+		requests int
+		minTime  time.Duration
+		maxTime  time.Duration
+	}{
+		{name: "nil limiter", limiter: nil, requests: 100, maxTime: 50 * time.Millisecond},
+		{name: "zero rate is unlimited", limiter: NewRateLimiter(0, 1), requests: 100, maxTime: 50 * time.Millisecond},
+		{name: "within the burst", limiter: NewRateLimiter(10, 5), requests: 5, maxTime: 50 * time.Millisecond},
+		{name: "past the burst", limiter: NewRateLimiter(20, 2), requests: 4, minTime: 90 * time.Millisecond, maxTime: time.Second},
+		{name: "burst below one", limiter: NewRateLimiter(20, 0), requests: 2, minTime: 40 * time.Millisecond, maxTime: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				if err := tt.limiter.Wait(context.Background()); err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+			}
+			if elapsed := time.Since(start); elapsed < tt.minTime || elapsed > tt.maxTime {
+				t.Errorf("%d requests took %s, want between %s and %s", tt.requests, elapsed, tt.minTime, tt.maxTime)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The abandoned request gave its token back, so the next waits one interval, not two
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("bucket holds %.2f tokens after the canceled wait, want about 0", tokens)
+	}
+}
+
+// countingBackend counts the requests that reach it
+type countingBackend struct {
+	chainIDBackend
+	calls int
+}
+
+func (b *countingBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	b.calls++
+	return b.chainIDBackend.ChainID(ctx)
+}
+
+func TestRateLimit(t *testing.T) {
+	inner := &countingBackend{chainIDBackend: chainIDBackend{id: big.NewInt(1514)}}
+	if got := RateLimit(inner, nil); got != Backend(inner) {
+		t.Errorf("nil limiter wrapped the backend in %T", got)
+	}
+
+	tests := []struct {
+		name      string
+		backend   Backend
+		ctx       func() context.Context
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "passes through", backend: inner, ctx: context.Background, wantCalls: 1},
+		{
+			name:    "canceled while waiting",
+			backend: inner,
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+		{name: "no chain ID", backend: &feeBackend{}, ctx: context.Background, wantErr: errNoChainID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner.calls = 0
+			// A drained bucket makes every request wait
+			limiter := NewRateLimiter(100, 1)
+			limiter.tokens = 0
+
+			reader := RateLimit(tt.backend, limiter).(chainIDReader)
+			_, err := reader.ChainID(tt.ctx())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if inner.calls != tt.wantCalls {
+				t.Errorf("backend saw %d calls, want %d", inner.calls, tt.wantCalls)
+			}
+		})
+	}
+}
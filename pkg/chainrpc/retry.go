@@ -0,0 +1,143 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RetryPolicy controls how transient RPC failures are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first (<=1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay    time.Duration // Upper bound on the delay between attempts
+	Jitter      float64       // Fraction of each delay that is randomized (0..1)
+
+	// OnRetry, if set, is called with the failed attempt number (1-based) and its error
+	// before waiting to retry
+	OnRetry func(attempt int, err error)
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// NoRetry returns a policy that makes a single attempt
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// Do calls fn until it succeeds, fails with a non-transient error, or attempts run out
+// Waiting between attempts is interrupted by context cancellation
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(p.delay(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err = fn()
+		if err == nil || !IsTransient(err) {
+			return err
+		}
+
+		if p.OnRetry != nil && attempt+1 < attempts {
+			p.OnRetry(attempt+1, err)
+		}
+	}
+
+	return err
+}
+
+// WithRetryHook returns a copy of p that also calls hook before each retry
+// Any existing OnRetry hook still runs first
+func (p RetryPolicy) WithRetryHook(hook func(attempt int, err error)) RetryPolicy {
+	prev := p.OnRetry
+	p.OnRetry = func(attempt int, err error) {
+		if prev != nil {
+			prev(attempt, err)
+		}
+		hook(attempt, err)
+	}
+	return p
+}
+
+// delay returns the backoff before the given retry attempt (1-based)
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		spread := float64(d) * p.Jitter
+		d = time.Duration(float64(d) - spread + rand.Float64()*2*spread)
+	}
+
+	return d
+}
+
+// IsTransient reports whether err is a network, timeout, or rate-limit failure worth retrying
+// Contract reverts and other deterministic failures are never transient
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "execution reverted") || IsTooManyResults(err) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the conventional "limit exceeded" code used by public providers
+		return rpcErr.ErrorCode() == -32005
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	return strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "rate limit") ||
+		strings.Contains(msg, "timeout")
+}
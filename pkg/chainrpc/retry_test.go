@@ -0,0 +1,129 @@
+package chainrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "revert", err: errors.New("execution reverted: not owner"), want: false},
+		{name: "too many log results", err: errors.New("query returned more than 10000 results"), want: false},
+		{name: "http 429", err: rpc.HTTPError{StatusCode: 429}, want: true},
+		{name: "http 503", err: rpc.HTTPError{StatusCode: 503}, want: true},
+		{name: "http 400", err: rpc.HTTPError{StatusCode: 400}, want: false},
+		{name: "eof", err: fmt.Errorf("read: %w", io.EOF), want: true},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true},
+		{name: "rate limit message", err: errors.New("Rate limit exceeded"), want: true},
+		{name: "other", err: errors.New("invalid argument"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	transient := io.ErrUnexpectedEOF
+	permanent := errors.New("execution reverted")
+
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "first attempt succeeds", maxAttempts: 3, errs: []error{nil}, wantAttempts: 1},
+		{name: "transient then success", maxAttempts: 3, errs: []error{transient, transient, nil}, wantAttempts: 3},
+		{name: "attempts exhausted", maxAttempts: 3, errs: []error{transient, transient, transient, nil}, wantAttempts: 3, wantErr: transient},
+		{name: "permanent not retried", maxAttempts: 3, errs: []error{permanent, nil}, wantAttempts: 1, wantErr: permanent},
+		{name: "retries disabled", maxAttempts: 0, errs: []error{transient, nil}, wantAttempts: 1, wantErr: transient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retried []int
+			policy := RetryPolicy{
+				MaxAttempts: tt.maxAttempts,
+				BaseDelay:   time.Microsecond,
+				MaxDelay:    time.Millisecond,
+				OnRetry:     func(attempt int, err error) { retried = append(retried, attempt) },
+			}
+
+			attempts := 0
+			err := policy.Do(context.Background(), func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if wantRetries := tt.wantAttempts - 1; len(retried) != wantRetries {
+				t.Errorf("OnRetry called %d times, want %d", len(retried), wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}
+
+	attempts := 0
+	err := policy.Do(ctx, func() error {
+		attempts++
+		cancel()
+		return rpc.HTTPError{StatusCode: 503}
+	})
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("got %v after %d attempts, want context.Canceled after 1", err, attempts)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 80, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.delay(tt.attempt); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("jittered delay %s outside [50ms, 150ms]", got)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package chainrpc
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTimeout bounds RPC calls whose context carries no deadline
+const DefaultTimeout = 30 * time.Second
+
+// EnsureDeadline derives a context that expires after d unless ctx already has a deadline
+// A non-positive d leaves the context unbounded
+func EnsureDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
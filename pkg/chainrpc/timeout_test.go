@@ -0,0 +1,53 @@
+package chainrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEnsureDeadline(t *testing.T) {
+	existing, cancelExisting := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelExisting()
+	existingDeadline, _ := existing.Deadline()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		d            time.Duration
+		wantDeadline bool
+		wantWithin   time.Duration
+	}{
+		{name: "adds deadline", ctx: context.Background(), d: time.Minute, wantDeadline: true, wantWithin: time.Minute},
+		{name: "keeps existing deadline", ctx: existing, d: time.Minute, wantDeadline: true, wantWithin: time.Hour},
+		{name: "zero leaves unbounded", ctx: context.Background(), d: 0},
+		{name: "negative leaves unbounded", ctx: context.Background(), d: -time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := EnsureDeadline(tt.ctx, tt.d)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("has deadline = %v, want %v", ok, tt.wantDeadline)
+			}
+			if !ok {
+				return
+			}
+			if remaining := time.Until(deadline); remaining > tt.wantWithin || remaining < tt.wantWithin-time.Second {
+				t.Errorf("deadline in %s, want about %s", remaining, tt.wantWithin)
+			}
+			if tt.ctx == existing && !deadline.Equal(existingDeadline) {
+				t.Errorf("existing deadline replaced")
+			}
+		})
+	}
+
+	// The returned cancel releases the derived context
+	ctx, cancel := EnsureDeadline(context.Background(), 0)
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("cancel did not cancel the derived context")
+	}
+}
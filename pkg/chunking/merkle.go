@@ -0,0 +1,255 @@
+package chunking
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// DefaultChunkSize is the chunk size used when storing genomic files
+const DefaultChunkSize = 1 << 20
+
+// Hash prefixes keep leaf and interior hashes from colliding
+const (
+	leafPrefix byte = 0x00
+	nodePrefix byte = 0x01
+)
+
+// ErrIndexOutOfRange is returned by Proof for a chunk index past the last leaf
+var ErrIndexOutOfRange = errors.New("chunk index out of range")
+
+// Tree is a binary Merkle tree over SHA256 chunk hashes
+// An unpaired node at the end of a level is promoted to the next level unchanged
+type Tree struct {
+	levels [][][32]byte // levels[0] are the leaves, the last level holds the root
+}
+
+// ProofStep is one sibling hash on the path from a leaf to the root
+type ProofStep struct {
+	Hash [32]byte
+	Left bool // Sibling is on the left of the running hash
+}
+
+// Proof shows that a chunk belongs to a tree
+type Proof struct {
+	Index int
+	Steps []ProofStep
+}
+
+// ComputeMerkleRoot splits r into chunkSize chunks and returns the Merkle root and leaf count
+// The result is what BurnAndDelete takes as its merkleRoot and nodeCount arguments
+func ComputeMerkleRoot(r io.Reader, chunkSize int) (root [32]byte, leafCount *big.Int, err error) {
+	tree, err := BuildTree(r, chunkSize)
+	if err != nil {
+		return [32]byte{}, nil, err
+	}
+
+	return tree.Root(), big.NewInt(int64(tree.LeafCount())), nil
+}
+
+// BuildDeletionProof returns the Merkle root and leaf count of already-split content chunks
+// The construction matches ComputeMerkleRoot, so the result can be passed to BurnAndDelete
+// No chunks produces a single leaf for the empty chunk
+func BuildDeletionProof(chunks [][]byte) (merkleRoot [32]byte, leafCount *big.Int) {
+	leaves := make([][32]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		leaves = append(leaves, HashChunk(chunk))
+	}
+	if len(leaves) == 0 {
+		leaves = append(leaves, HashChunk(nil))
+	}
+
+	tree := NewTree(leaves)
+	return tree.Root(), big.NewInt(int64(tree.LeafCount()))
+}
+
+// VerifyDeletionProof reports whether a recorded merkleRoot and leaf count were built from chunks
+// leafCount is the value BurnAndDelete recorded as the proof's nodeCount
+func VerifyDeletionProof(chunks [][]byte, merkleRoot [32]byte, leafCount *big.Int) bool {
+	if leafCount == nil {
+		return false
+	}
+
+	root, count := BuildDeletionProof(chunks)
+	return root == merkleRoot && count.Cmp(leafCount) == 0
+}
+
+// BuildTree reads r in chunkSize chunks and builds the Merkle tree over them
+// Empty input produces a single leaf for the empty chunk
+func BuildTree(r io.Reader, chunkSize int) (*Tree, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+
+	var leaves [][32]byte
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			leaves = append(leaves, HashChunk(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk %d: %w", len(leaves), err)
+		}
+	}
+
+	if len(leaves) == 0 {
+		leaves = append(leaves, HashChunk(nil))
+	}
+
+	return NewTree(leaves), nil
+}
+
+// NewTree builds a Merkle tree from precomputed leaf hashes (see HashChunk)
+func NewTree(leaves [][32]byte) *Tree {
+	levels := [][][32]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Tree{levels: levels}
+}
+
+// Root returns the Merkle root
+func (t *Tree) Root() [32]byte {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		return HashChunk(nil)
+	}
+	return top[0]
+}
+
+// LeafCount returns the number of chunks in the tree
+func (t *Tree) LeafCount() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the inclusion proof for the chunk at index
+func (t *Tree) Proof(index int) (*Proof, error) {
+	if index < 0 || index >= t.LeafCount() {
+		return nil, fmt.Errorf("%w: %d (leaves: %d)", ErrIndexOutOfRange, index, t.LeafCount())
+	}
+
+	proof := &Proof{Index: index}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Steps = append(proof.Steps, ProofStep{
+				Hash: level[sibling],
+				Left: sibling < index,
+			})
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyChunk reports whether chunk is included under root according to proof
+func VerifyChunk(root [32]byte, chunk []byte, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+
+	hash := HashChunk(chunk)
+	for _, step := range proof.Steps {
+		if step.Left {
+			hash = hashNode(step.Hash, hash)
+		} else {
+			hash = hashNode(hash, step.Hash)
+		}
+	}
+
+	return hash == root
+}
+
+// promoted marks a level of an inclusion proof where the node had no sibling and moved up unchanged
+// No SHA256 node or leaf hash is all zeros, so it cannot be mistaken for a sibling
+var promoted [32]byte
+
+// InclusionProof returns the proof for the chunk at index in the flat form VerifyChunkInclusion takes
+// It holds one hash per level below the root, from the leaf upwards: the sibling at that level,
+// or the zero hash where the node was the unpaired last one and was promoted
+func (t *Tree) InclusionProof(index int) ([][32]byte, error) {
+	if index < 0 || index >= t.LeafCount() {
+		return nil, fmt.Errorf("%w: %d (leaves: %d)", ErrIndexOutOfRange, index, t.LeafCount())
+	}
+
+	proof := make([][32]byte, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		} else {
+			proof = append(proof, promoted)
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyChunkInclusion reports whether chunk is the leaf at index of the tree with the given root
+// proof is in the form returned by InclusionProof; the bits of index, lowest first, give the side
+// of the running hash at each level, and a zero hash is only accepted where that side is the left
+func VerifyChunkInclusion(root [32]byte, chunk []byte, proof [][32]byte, index int) bool {
+	if index < 0 {
+		return false
+	}
+
+	hash := HashChunk(chunk)
+	for _, sibling := range proof {
+		switch {
+		case sibling == promoted:
+			// Only the last node of a level can be unpaired, and it is then a left child
+			if index&1 == 1 {
+				return false
+			}
+		case index&1 == 1:
+			hash = hashNode(sibling, hash)
+		default:
+			hash = hashNode(hash, sibling)
+		}
+		index >>= 1
+	}
+
+	// Bits left over mean index is past the last leaf a proof of this height can cover
+	return index == 0 && hash == root
+}
+
+// HashChunk returns the leaf hash of a chunk
+func HashChunk(chunk []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunk)
+
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// hashNode returns the interior hash of two children
+func hashNode(left, right [32]byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left[:])
+	h.Write(right[:])
+
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
@@ -0,0 +1,290 @@
+package chunking
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// leaf and node recompute the tree hashes independently of the package
+func leaf(chunk string) [32]byte {
+	return sha256.Sum256(append([]byte{0x00}, chunk...))
+}
+
+func node(left, right [32]byte) [32]byte {
+	return sha256.Sum256(append(append([]byte{0x01}, left[:]...), right[:]...))
+}
+
+func TestComputeMerkleRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		chunkSize int
+		want      [32]byte
+		wantCount int64
+	}{
+		{name: "empty", content: "", chunkSize: 4, want: leaf(""), wantCount: 1},
+		{name: "single chunk", content: "ACGT", chunkSize: 4, want: leaf("ACGT"), wantCount: 1},
+		{name: "two chunks", content: "ACGTAC", chunkSize: 4, want: node(leaf("ACGT"), leaf("AC")), wantCount: 2},
+		{
+			name:      "odd leaf promoted",
+			content:   "ACGTTTGCA",
+			chunkSize: 3,
+			want:      node(node(leaf("ACG"), leaf("TTT")), leaf("GCA")),
+			wantCount: 3,
+		},
+		{
+			name:      "five chunks",
+			content:   "abcde",
+			chunkSize: 1,
+			want:      node(node(node(leaf("a"), leaf("b")), node(leaf("c"), leaf("d"))), leaf("e")),
+			wantCount: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, count, err := ComputeMerkleRoot(strings.NewReader(tt.content), tt.chunkSize)
+			if err != nil {
+				t.Fatalf("ComputeMerkleRoot failed: %v", err)
+			}
+			if root != tt.want {
+				t.Errorf("root = %x, want %x", root, tt.want)
+			}
+			if count.Int64() != tt.wantCount {
+				t.Errorf("leafCount = %s, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestComputeMerkleRootKnownVector(t *testing.T) {
+	root, _, err := ComputeMerkleRoot(bytes.NewReader(nil), DefaultChunkSize)
+	if err != nil {
+		t.Fatalf("ComputeMerkleRoot failed: %v", err)
+	}
+	// SHA256 of the single leaf prefix byte 0x00
+	if got := hex.EncodeToString(root[:]); got != "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d" {
+		t.Errorf("empty root = %s", got)
+	}
+}
+
+func TestComputeMerkleRootErrors(t *testing.T) {
+	if _, _, err := ComputeMerkleRoot(strings.NewReader("x"), 0); err == nil {
+		t.Error("expected an error for a zero chunk size")
+	}
+
+	broken := io.MultiReader(strings.NewReader("abcd"), errReader{})
+	if _, _, err := ComputeMerkleRoot(broken, 2); err == nil {
+		t.Error("expected the read error to be returned")
+	}
+}
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestProofVerifyChunk(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		chunks := make([]string, n)
+		for i := range chunks {
+			chunks[i] = string(rune('a' + i))
+		}
+		tree, err := BuildTree(strings.NewReader(strings.Join(chunks, "")), 1)
+		if err != nil {
+			t.Fatalf("BuildTree failed: %v", err)
+		}
+
+		for i, chunk := range chunks {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("%d leaves: Proof(%d) failed: %v", n, i, err)
+			}
+			if !VerifyChunk(tree.Root(), []byte(chunk), proof) {
+				t.Errorf("%d leaves: chunk %d not verified", n, i)
+			}
+			if VerifyChunk(tree.Root(), []byte("z"), proof) {
+				t.Errorf("%d leaves: wrong chunk verified at %d", n, i)
+			}
+			if n > 1 && VerifyChunk(HashChunk([]byte("z")), []byte(chunk), proof) {
+				t.Errorf("%d leaves: chunk %d verified against the wrong root", n, i)
+			}
+		}
+	}
+}
+
+func TestProofErrors(t *testing.T) {
+	tree := NewTree([][32]byte{leaf("a"), leaf("b")})
+	for _, index := range []int{-1, 2} {
+		if _, err := tree.Proof(index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Proof(%d) = %v, want %v", index, err, ErrIndexOutOfRange)
+		}
+	}
+	if VerifyChunk(tree.Root(), []byte("a"), nil) {
+		t.Error("nil proof verified")
+	}
+
+	// Swapping a step's side breaks the proof
+	proof, _ := tree.Proof(0)
+	proof.Steps[0].Left = !proof.Steps[0].Left
+	if VerifyChunk(tree.Root(), []byte("a"), proof) {
+		t.Error("proof with a flipped side verified")
+	}
+}
+
+func TestBuildDeletionProof(t *testing.T) {
+	chunks := func(parts ...string) [][]byte {
+		out := make([][]byte, len(parts))
+		for i, part := range parts {
+			out[i] = []byte(part)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		chunks    [][]byte
+		chunkSize int
+		want      [32]byte
+		wantCount int64
+	}{
+		{name: "no chunks", chunks: nil, chunkSize: 2, want: leaf(""), wantCount: 1},
+		{name: "one chunk", chunks: chunks("abcd"), chunkSize: 4, want: leaf("abcd"), wantCount: 1},
+		{name: "two chunks", chunks: chunks("ab", "cd"), chunkSize: 2, want: node(leaf("ab"), leaf("cd")), wantCount: 2},
+		{name: "odd chunk promoted", chunks: chunks("ab", "cd", "e"), chunkSize: 2, want: node(node(leaf("ab"), leaf("cd")), leaf("e")), wantCount: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, count := BuildDeletionProof(tt.chunks)
+			if root != tt.want || count.Int64() != tt.wantCount {
+				t.Fatalf("got %x with %s leaves, want %x with %d", root, count, tt.want, tt.wantCount)
+			}
+
+			// The same content streamed through ComputeMerkleRoot at the chunks' size gives the same proof
+			streamed, streamedCount, err := ComputeMerkleRoot(bytes.NewReader(bytes.Join(tt.chunks, nil)), tt.chunkSize)
+			if err != nil {
+				t.Fatalf("ComputeMerkleRoot failed: %v", err)
+			}
+			if streamed != root || streamedCount.Cmp(count) != 0 {
+				t.Errorf("ComputeMerkleRoot gave %x with %s leaves", streamed, streamedCount)
+			}
+
+			if !VerifyDeletionProof(tt.chunks, root, count) {
+				t.Error("built proof not verified")
+			}
+		})
+	}
+}
+
+func TestVerifyDeletionProof(t *testing.T) {
+	stored := [][]byte{[]byte("ab"), []byte("cd"), []byte("e")}
+	root, count := BuildDeletionProof(stored)
+
+	tests := []struct {
+		name   string
+		chunks [][]byte
+		root   [32]byte
+		count  *big.Int
+		want   bool
+	}{
+		{name: "matching", chunks: stored, root: root, count: count, want: true},
+		{name: "tampered chunk", chunks: [][]byte{[]byte("ab"), []byte("cX"), []byte("e")}, root: root, count: count},
+		{name: "reordered chunks", chunks: [][]byte{[]byte("cd"), []byte("ab"), []byte("e")}, root: root, count: count},
+		{name: "missing chunk", chunks: stored[:2], root: root, count: count},
+		{name: "wrong count", chunks: stored, root: root, count: big.NewInt(4)},
+		{name: "no count", chunks: stored, root: root},
+		{name: "other root", chunks: stored, root: leaf("ab"), count: count},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyDeletionProof(tt.chunks, tt.root, tt.count); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChunkInclusion(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		chunks := make([][]byte, n)
+		for i := range chunks {
+			chunks[i] = []byte{byte('a' + i)}
+		}
+		root, _ := BuildDeletionProof(chunks)
+		tree, err := BuildTree(bytes.NewReader(bytes.Join(chunks, nil)), 1)
+		if err != nil {
+			t.Fatalf("BuildTree failed: %v", err)
+		}
+
+		for i, chunk := range chunks {
+			proof, err := tree.InclusionProof(i)
+			if err != nil {
+				t.Fatalf("%d leaves: InclusionProof(%d) failed: %v", n, i, err)
+			}
+
+			tampered := append([][32]byte(nil), proof...)
+			if len(tampered) > 0 {
+				tampered[len(tampered)-1][0] ^= 0xFF
+			}
+
+			tests := []struct {
+				name  string
+				chunk []byte
+				proof [][32]byte
+				index int
+				want  bool
+			}{
+				{name: "valid", chunk: chunk, proof: proof, index: i, want: true},
+				{name: "wrong chunk", chunk: []byte("z"), proof: proof, index: i},
+				{name: "tampered element", chunk: chunk, proof: tampered, index: i, want: n == 1},
+				{name: "wrong index", chunk: chunk, proof: proof, index: i ^ 1},
+				{name: "index past the proof", chunk: chunk, proof: proof, index: i + 1<<len(proof)},
+				{name: "negative index", chunk: chunk, proof: proof, index: -1},
+			}
+			for _, tt := range tests {
+				if got := VerifyChunkInclusion(root, tt.chunk, tt.proof, tt.index); got != tt.want {
+					t.Errorf("%d leaves, chunk %d, %s: got %v, want %v", n, i, tt.name, got, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyChunkInclusionPromotion(t *testing.T) {
+	// With three leaves, c is promoted past the first level unpaired
+	tree := NewTree([][32]byte{leaf("a"), leaf("b"), leaf("c")})
+	proof, err := tree.InclusionProof(2)
+	if err != nil {
+		t.Fatalf("InclusionProof failed: %v", err)
+	}
+	if len(proof) != 2 || proof[0] != ([32]byte{}) {
+		t.Fatalf("got proof %x, want the zero hash then ab", proof)
+	}
+	if !VerifyChunkInclusion(tree.Root(), []byte("c"), proof, 2) {
+		t.Error("promoted chunk not verified")
+	}
+
+	// A marker cannot stand in for a left sibling, or for a real right one
+	a, _ := tree.InclusionProof(0)
+	b, _ := tree.InclusionProof(1)
+	a[0], b[0] = [32]byte{}, [32]byte{}
+	if VerifyChunkInclusion(tree.Root(), []byte("b"), b, 1) {
+		t.Error("right child verified with a promotion marker")
+	}
+	if VerifyChunkInclusion(tree.Root(), []byte("a"), a, 0) {
+		t.Error("left child verified with its sibling replaced by a promotion marker")
+	}
+
+	for _, index := range []int{-1, 3} {
+		if _, err := tree.InclusionProof(index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("InclusionProof(%d) = %v, want %v", index, err, ErrIndexOutOfRange)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccessDecision decides whether wallet may access the content behind b
+// Access requires active consent on the token and the wallet holding either consent or ownership
+// When access is denied, reason explains why in human-readable form
+func (c *ConsentChecker) AccessDecision(ctx context.Context, b *biocid.BioCID, wallet common.Address) (allowed bool, reason string, err error) {
+	if b == nil {
+		return false, "", fmt.Errorf("biocid is required")
+	}
+	nftRef := b.NFTRef()
+
+	state, err := c.GetConsentState(ctx, nftRef)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get consent state: %w", err)
+	}
+
+	switch state {
+	case ConsentActive:
+	case ConsentDeleted:
+		return false, fmt.Sprintf("token %s has been burned and its content deleted", nftRef), nil
+	case ConsentRevoked:
+		return false, fmt.Sprintf("consent for token %s has been revoked", nftRef), nil
+	default:
+		return false, fmt.Sprintf("consent for token %s is %s, not active", nftRef, state), nil
+	}
+
+	granted, err := c.CheckConsent(ctx, nftRef, wallet)
+	if err != nil {
+		return false, "", err
+	}
+	if granted {
+		return true, "", nil
+	}
+
+	owns, err := c.holdsToken(ctx, nftRef, wallet)
+	if err != nil {
+		return false, "", err
+	}
+	if owns {
+		return true, "", nil
+	}
+
+	return false, fmt.Sprintf("wallet %s neither holds consent for nor owns token %s", wallet.Hex(), nftRef), nil
+}
+
+// holdsToken reports whether wallet owns an ERC721 token or holds a balance of an ERC1155 token
+func (c *ConsentChecker) holdsToken(ctx context.Context, nftRef biocid.NFTReference, wallet common.Address) (bool, error) {
+	owner, err := c.GetOwner(ctx, nftRef)
+	if err == nil {
+		return owner == wallet, nil
+	}
+	if !errors.Is(err, ErrNoSingleOwner) {
+		return false, fmt.Errorf("failed to get owner: %w", err)
+	}
+
+	balance, err := c.GetBalance(ctx, nftRef, wallet)
+	if err != nil {
+		return false, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance.Sign() > 0, nil
+}
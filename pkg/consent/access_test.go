@@ -0,0 +1,81 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAccessDecision(t *testing.T) {
+	holder := common.HexToAddress("0x00000000000000000000000000000000000000D4")
+	withState := func(tokenID int64, state ConsentState) consentMetadata {
+		meta := activeMetadata(tokenID)
+		meta.State = uint8(state)
+		return meta
+	}
+
+	// The registry is an ERC1155 collection, so ownership means a nonzero balance
+	reg := newSimConsent()
+	reg.On("supportsInterface", interfaceERC721).Returns(false)
+	reg.On("supportsInterface", interfaceERC1155).Returns(true)
+	reg.On("getConsentMetadata", big.NewInt(1)).Returns(activeMetadata(1))
+	reg.On("getConsentMetadata", big.NewInt(3)).Returns(withState(3, ConsentRevoked))
+	reg.On("getConsentMetadata", big.NewInt(4)).Returns(withState(4, ConsentDeleted))
+	reg.On("getConsentMetadata", big.NewInt(5)).Returns(withState(5, ConsentPending))
+	reg.On("checkConsent").Returns(false)
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(3), wallet).Returns(true)
+	reg.On("balanceOf").Returns(big.NewInt(0))
+	reg.On("balanceOf", holder, big.NewInt(1)).Returns(big.NewInt(1))
+	c, _ := newSimChecker(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name       string
+		tokenID    string
+		wallet     common.Address
+		want       bool
+		wantReason string
+		wantErr    bool
+	}{
+		{name: "consent", tokenID: "1", wallet: wallet, want: true},
+		{name: "ownership", tokenID: "1", wallet: holder, want: true},
+		{name: "no access", tokenID: "1", wallet: stranger, wantReason: "neither holds consent for nor owns"},
+		{name: "revoked", tokenID: "3", wallet: wallet, wantReason: "revoked"},
+		{name: "deleted", tokenID: "4", wallet: wallet, wantReason: "content deleted"},
+		{name: "pending", tokenID: "5", wallet: wallet, wantReason: "pending, not active"},
+		{name: "unreadable state", tokenID: "9", wallet: wallet, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &biocid.BioCID{Version: "v1", Chain: simChain, Collection: simCollection.Hex(), TokenID: tt.tokenID}
+			allowed, reason, err := c.AccessDecision(context.Background(), b, tt.wallet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AccessDecision failed: %v", err)
+			}
+			if allowed != tt.want {
+				t.Errorf("allowed = %v, want %v", allowed, tt.want)
+			}
+			if tt.want && reason != "" {
+				t.Errorf("allowed with reason %q", reason)
+			}
+			if !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("reason %q does not mention %q", reason, tt.wantReason)
+			}
+		})
+	}
+
+	if _, _, err := c.AccessDecision(context.Background(), nil, wallet); err == nil {
+		t.Error("expected an error for a nil BioCID")
+	}
+}
@@ -0,0 +1,241 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchError collects per-wallet failures from CheckConsentBatch
+type BatchError struct {
+	Errors map[common.Address]error
+}
+
+// Error implements the error interface
+func (e *BatchError) Error() string {
+	wallets := make([]string, 0, len(e.Errors))
+	for wallet := range e.Errors {
+		wallets = append(wallets, wallet.Hex())
+	}
+	sort.Strings(wallets)
+
+	return fmt.Sprintf("consent check failed for %d wallet(s): %s", len(wallets), strings.Join(wallets, ", "))
+}
+
+// CheckConsentBatch checks consent for several wallets in a single JSON-RPC batch where supported
+// With WithConsentCache enabled, cached wallets are answered without a call and fresh results are cached
+// Denied wallets are checked individually for delegated access when WithDelegatedConsent is enabled
+// Wallets whose call fails are left out of the result and reported in a *BatchError;
+// the map still holds every wallet that was checked successfully
+func (c *ConsentChecker) CheckConsentBatch(
+	ctx context.Context,
+	nftRef biocid.NFTReference,
+	wallets []common.Address,
+) (map[common.Address]bool, error) {
+	if len(wallets) == 0 {
+		return make(map[common.Address]bool), nil
+	}
+
+	if !common.IsHexAddress(nftRef.Collection) {
+		return nil, fmt.Errorf("%w: %s", biocid.ErrInvalidAddress, nftRef.Collection)
+	}
+	collection := common.HexToAddress(nftRef.Collection)
+
+	tokenID, err := biocid.ParseTokenID(nftRef.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]consentQuery[common.Address], len(wallets))
+	for i, wallet := range wallets {
+		queries[i] = consentQuery[common.Address]{key: wallet, nftRef: nftRef, tokenID: tokenID, wallet: wallet}
+	}
+	return checkConsentQueries(ctx, c, nftRef.Chain, collection, queries, nil, func(failed map[common.Address]error) error {
+		return &BatchError{Errors: failed}
+	})
+}
+
+// TokenBatchError collects per-token failures from BatchCheckTokens
+type TokenBatchError struct {
+	Errors map[string]error
+}
+
+// Error implements the error interface
+func (e *TokenBatchError) Error() string {
+	tokenIDs := make([]string, 0, len(e.Errors))
+	for tokenID := range e.Errors {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Strings(tokenIDs)
+
+	return fmt.Sprintf("consent check failed for %d token(s): %s", len(tokenIDs), strings.Join(tokenIDs, ", "))
+}
+
+// BatchCheckTokens checks wallet's consent for several tokens of one collection in a single JSON-RPC batch
+// where supported; the result is keyed by the token IDs as given
+// With WithConsentCache enabled, cached tokens are answered without a call and fresh results are cached
+// Denied tokens are checked individually for delegated access when WithDelegatedConsent is enabled
+// Tokens whose ID is invalid or whose call fails are left out of the result and reported in a
+// *TokenBatchError; the map still holds every token that was checked successfully
+func (c *ConsentChecker) BatchCheckTokens(
+	ctx context.Context,
+	chain biocid.Chain, collection string,
+	wallet common.Address,
+	tokenIDs []string,
+) (map[string]bool, error) {
+	if len(tokenIDs) == 0 {
+		return make(map[string]bool), nil
+	}
+
+	if !common.IsHexAddress(collection) {
+		return nil, fmt.Errorf("%w: %s", biocid.ErrInvalidAddress, collection)
+	}
+
+	failed := make(map[string]error)
+	var queries []consentQuery[string]
+	for _, tokenID := range tokenIDs {
+		id, err := biocid.ParseTokenID(tokenID)
+		if err != nil {
+			failed[tokenID] = err
+			continue
+		}
+
+		queries = append(queries, consentQuery[string]{
+			key:     tokenID,
+			nftRef:  biocid.NFTReference{Chain: chain, Collection: collection, TokenID: tokenID},
+			tokenID: id,
+			wallet:  wallet,
+		})
+	}
+	return checkConsentQueries(ctx, c, chain, common.HexToAddress(collection), queries, failed, func(failed map[string]error) error {
+		return &TokenBatchError{Errors: failed}
+	})
+}
+
+// consentQuery is one checkConsent call of a batch, reported under key
+type consentQuery[K comparable] struct {
+	key     K
+	nftRef  biocid.NFTReference
+	tokenID *big.Int
+	wallet  common.Address
+}
+
+// checkConsentQueries answers queries from the consent cache where possible and checks the rest
+// against collection in one JSON-RPC batch, falling back to delegated access like CheckConsent
+// Keys whose call fails are left out of the result and added to failed, which may already hold
+// keys rejected by the caller; when any failed, newErr builds the returned error from them
+func checkConsentQueries[K comparable](
+	ctx context.Context,
+	c *ConsentChecker,
+	chain biocid.Chain,
+	collection common.Address,
+	queries []consentQuery[K],
+	failed map[K]error,
+	newErr func(failed map[K]error) error,
+) (map[K]bool, error) {
+	results := make(map[K]bool, len(queries))
+	if failed == nil {
+		failed = make(map[K]error)
+	}
+
+	var pending []consentQuery[K]
+	var calldata [][]byte
+	for _, q := range queries {
+		if granted, ok := c.cachedConsent(q.nftRef, q.wallet); ok {
+			results[q.key] = granted
+			continue
+		}
+
+		data, err := consentABI.Pack("checkConsent", q.tokenID, q.wallet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode checkConsent: %w", err)
+		}
+		pending = append(pending, q)
+		calldata = append(calldata, data)
+	}
+
+	if len(calldata) > 0 {
+		raw, errs, err := c.batchCheckConsent(ctx, chain, collection, calldata)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, q := range pending {
+			if errs[i] != nil {
+				failed[q.key] = errs[i]
+				continue
+			}
+
+			granted, err := decodeCheckConsent(raw[i])
+			if err != nil {
+				failed[q.key] = err
+				continue
+			}
+
+			if !granted && c.delegated {
+				if granted, err = c.checkDelegatedAccess(ctx, q.nftRef, q.wallet); err != nil {
+					failed[q.key] = fmt.Errorf("failed to check delegated access: %w", err)
+					continue
+				}
+			}
+			results[q.key] = granted
+			c.cacheConsent(q.nftRef, q.wallet, granted)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, newErr(failed)
+	}
+	return results, nil
+}
+
+// batchCheckConsent sends checkConsent calldata to collection in one batch, retrying transient failures
+func (c *ConsentChecker) batchCheckConsent(ctx context.Context, chain biocid.Chain, collection common.Address, calldata [][]byte) ([][]byte, []error, error) {
+	return c.batchCall(ctx, chain, collection, "checkConsent", calldata)
+}
+
+// batchCall sends calldata for method to collection in one batch, retrying transient failures
+func (c *ConsentChecker) batchCall(ctx context.Context, chain biocid.Chain, collection common.Address, method string, calldata [][]byte) ([][]byte, []error, error) {
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	callCtx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	op := method + " batch"
+	var raw [][]byte
+	var errs []error
+	start := time.Now()
+	err = c.retryFor(op).Do(callCtx, func() error {
+		raw, errs, err = chainrpc.BatchCallContract(callCtx, client, collection, calldata)
+		return err
+	})
+	chainrpc.ObserveCall(c.logger, c.metrics, op, start, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to batch %s: %w", method, err)
+	}
+	return raw, errs, nil
+}
+
+// decodeCheckConsent decodes the return data of a checkConsent call
+func decodeCheckConsent(raw []byte) (bool, error) {
+	out, err := consentABI.Unpack("checkConsent", raw)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode checkConsent: %w", err)
+	}
+
+	granted, ok := out[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected checkConsent result type %T", out[0])
+	}
+	return granted, nil
+}
@@ -0,0 +1,232 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCheckConsentBatch(t *testing.T) {
+	broken := common.HexToAddress("0x00000000000000000000000000000000000000C3")
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(1), stranger).Returns(false)
+	reg.On("checkConsent", big.NewInt(1), broken).Reverts()
+	c, _ := newSimChecker(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name       string
+		ref        biocid.NFTReference
+		wallets    []common.Address
+		want       map[common.Address]bool
+		wantFailed []common.Address
+		wantErr    bool
+	}{
+		{name: "empty", ref: simRef("1"), want: map[common.Address]bool{}},
+		{
+			name:    "all checked",
+			ref:     simRef("1"),
+			wallets: []common.Address{wallet, stranger},
+			want:    map[common.Address]bool{wallet: true, stranger: false},
+		},
+		{
+			name:       "one reverts",
+			ref:        simRef("1"),
+			wallets:    []common.Address{wallet, broken, stranger},
+			want:       map[common.Address]bool{wallet: true, stranger: false},
+			wantFailed: []common.Address{broken},
+		},
+		{name: "invalid token ID", ref: simRef("-1"), wallets: []common.Address{wallet}, wantErr: true},
+		{name: "invalid collection", ref: biocid.NFTReference{Chain: simChain, Collection: "nope", TokenID: "1"}, wallets: []common.Address{wallet}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CheckConsentBatch(context.Background(), tt.ref, tt.wallets)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			var batchErr *BatchError
+			if len(tt.wantFailed) > 0 {
+				if !errors.As(err, &batchErr) {
+					t.Fatalf("got %v, want a *BatchError", err)
+				}
+				if len(batchErr.Errors) != len(tt.wantFailed) {
+					t.Errorf("got %d failures, want %d", len(batchErr.Errors), len(tt.wantFailed))
+				}
+				for _, w := range tt.wantFailed {
+					if batchErr.Errors[w] == nil {
+						t.Errorf("missing failure for %s", w.Hex())
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("CheckConsentBatch failed: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for w, granted := range tt.want {
+				if g, ok := got[w]; !ok || g != granted {
+					t.Errorf("%s: got %v (present %v), want %v", w.Hex(), g, ok, granted)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchCheckTokens(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(2), wallet).Returns(false)
+	reg.On("checkConsent", big.NewInt(3), wallet).Reverts()
+	reg.On("checkConsent", big.NewInt(4), wallet).Returns(true)
+	c, _ := newSimChecker(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name       string
+		collection string
+		tokenIDs   []string
+		want       map[string]bool
+		wantFailed []string
+		wantErr    bool
+	}{
+		{name: "empty", collection: simCollection.Hex(), want: map[string]bool{}},
+		{
+			name:       "all checked",
+			collection: simCollection.Hex(),
+			tokenIDs:   []string{"1", "2", "4"},
+			want:       map[string]bool{"1": true, "2": false, "4": true},
+		},
+		{
+			name:       "mixed results",
+			collection: simCollection.Hex(),
+			tokenIDs:   []string{"1", "3", "2", "-1", "4"},
+			want:       map[string]bool{"1": true, "2": false, "4": true},
+			wantFailed: []string{"-1", "3"},
+		},
+		{
+			name:       "keyed as given",
+			collection: simCollection.Hex(),
+			tokenIDs:   []string{"01", "2"},
+			want:       map[string]bool{"01": true, "2": false},
+		},
+		{name: "invalid collection", collection: "nope", tokenIDs: []string{"1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.BatchCheckTokens(context.Background(), simChain, tt.collection, wallet, tt.tokenIDs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			var batchErr *TokenBatchError
+			if len(tt.wantFailed) > 0 {
+				if !errors.As(err, &batchErr) {
+					t.Fatalf("got %v, want a *TokenBatchError", err)
+				}
+				if len(batchErr.Errors) != len(tt.wantFailed) {
+					t.Errorf("got %d failures, want %d", len(batchErr.Errors), len(tt.wantFailed))
+				}
+				for _, tokenID := range tt.wantFailed {
+					if batchErr.Errors[tokenID] == nil {
+						t.Errorf("missing failure for token %s", tokenID)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("BatchCheckTokens failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchConsentCache(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(2), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(1), stranger).Returns(false)
+	c, chain := newSimChecker(t, []*simchain.Contract{reg}, WithConsentCache(time.Minute, 0))
+	ctx := context.Background()
+
+	if _, err := c.CheckConsentBatch(ctx, simRef("1"), []common.Address{wallet, stranger}); err != nil {
+		t.Fatalf("CheckConsentBatch failed: %v", err)
+	}
+	if _, err := c.CheckConsent(ctx, simRef("2"), wallet); err != nil {
+		t.Fatalf("CheckConsent failed: %v", err)
+	}
+
+	// Flip every answer on-chain; cached results must still be served
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(false)
+	reg.On("checkConsent", big.NewInt(2), wallet).Returns(false)
+	reg.On("checkConsent", big.NewInt(1), stranger).Returns(true)
+	reg.On("checkConsent", big.NewInt(3), wallet).Returns(true)
+	chain.Apply(t, reg)
+
+	tests := []struct {
+		name string
+		run  func() (map[string]bool, error)
+		want map[string]bool
+	}{
+		{
+			name: "single check served from batch results",
+			run: func() (map[string]bool, error) {
+				granted, err := c.CheckConsent(ctx, simRef("1"), stranger)
+				return map[string]bool{"1": granted}, err
+			},
+			want: map[string]bool{"1": false},
+		},
+		{
+			name: "wallet batch",
+			run: func() (map[string]bool, error) {
+				got, err := c.CheckConsentBatch(ctx, simRef("1"), []common.Address{wallet, stranger})
+				return map[string]bool{"wallet": got[wallet], "stranger": got[stranger]}, err
+			},
+			want: map[string]bool{"wallet": true, "stranger": false},
+		},
+		{
+			name: "token batch mixes hits and misses",
+			run: func() (map[string]bool, error) {
+				return c.BatchCheckTokens(ctx, simChain, simCollection.Hex(), wallet, []string{"1", "2", "3"})
+			},
+			want: map[string]bool{"1": true, "2": true, "3": true},
+		},
+		{
+			name: "token batch results stored",
+			run: func() (map[string]bool, error) {
+				reg.On("checkConsent", big.NewInt(3), wallet).Returns(false)
+				chain.Apply(t, reg)
+				granted, err := c.CheckConsent(ctx, simRef("3"), wallet)
+				return map[string]bool{"3": granted}, err
+			},
+			want: map[string]bool{"3": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run()
+			if err != nil {
+				t.Fatalf("check failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package consent
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// consentCache is a bounded LRU cache of CheckConsent results with a TTL
+type consentCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	order   *list.List                     // Front is most recently used
+	entries map[string]*list.Element       // cache key => element holding *cacheEntry
+	byToken map[string]map[string]struct{} // token key => cache keys for that token
+	now     func() time.Time
+}
+
+// cacheEntry is a cached CheckConsent result
+type cacheEntry struct {
+	key      string
+	tokenKey string
+	granted  bool
+	expires  time.Time
+}
+
+// newConsentCache creates a cache; a non-positive maxEntries leaves it unbounded
+func newConsentCache(ttl time.Duration, maxEntries int) *consentCache {
+	return &consentCache{
+		ttl:     ttl,
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		byToken: make(map[string]map[string]struct{}),
+		now:     time.Now,
+	}
+}
+
+// tokenCacheKey identifies a token across wallets
+func tokenCacheKey(nftRef biocid.NFTReference) string {
+	return strings.ToLower(nftRef.Chain.String()) + "/" + strings.ToLower(nftRef.Collection) + "/" + nftRef.TokenID
+}
+
+// get returns a cached result if present and not expired
+func (cc *consentCache) get(nftRef biocid.NFTReference, wallet common.Address) (bool, bool) {
+	key := tokenCacheKey(nftRef) + "/" + wallet.Hex()
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	elem, ok := cc.entries[key]
+	if !ok {
+		return false, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !cc.now().Before(entry.expires) {
+		cc.remove(elem)
+		return false, false
+	}
+
+	cc.order.MoveToFront(elem)
+	return entry.granted, true
+}
+
+// put stores a result, evicting the least recently used entry when full
+func (cc *consentCache) put(nftRef biocid.NFTReference, wallet common.Address, granted bool) {
+	tokenKey := tokenCacheKey(nftRef)
+	key := tokenKey + "/" + wallet.Hex()
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if elem, ok := cc.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.granted = granted
+		entry.expires = cc.now().Add(cc.ttl)
+		cc.order.MoveToFront(elem)
+		return
+	}
+
+	entry := &cacheEntry{
+		key:      key,
+		tokenKey: tokenKey,
+		granted:  granted,
+		expires:  cc.now().Add(cc.ttl),
+	}
+	cc.entries[key] = cc.order.PushFront(entry)
+
+	if cc.byToken[tokenKey] == nil {
+		cc.byToken[tokenKey] = make(map[string]struct{})
+	}
+	cc.byToken[tokenKey][key] = struct{}{}
+
+	for cc.max > 0 && cc.order.Len() > cc.max {
+		cc.remove(cc.order.Back())
+	}
+}
+
+// purgeToken drops every cached result for a token
+func (cc *consentCache) purgeToken(nftRef biocid.NFTReference) {
+	tokenKey := tokenCacheKey(nftRef)
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for key := range cc.byToken[tokenKey] {
+		if elem, ok := cc.entries[key]; ok {
+			cc.remove(elem)
+		}
+	}
+}
+
+// remove deletes an element; the caller holds cc.mu
+func (cc *consentCache) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	cc.order.Remove(elem)
+	delete(cc.entries, entry.key)
+
+	if keys := cc.byToken[entry.tokenKey]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(cc.byToken, entry.tokenKey)
+		}
+	}
+}
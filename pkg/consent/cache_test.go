@@ -0,0 +1,194 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestConsentCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	tests := []struct {
+		name string
+		run  func(cc *consentCache) bool // Reports whether token 1 for wallet is still cached
+		want bool
+	}{
+		{name: "miss", run: func(cc *consentCache) bool { return false }, want: false},
+		{
+			name: "hit",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				granted, ok := cc.get(simRef("1"), wallet)
+				return ok && granted
+			},
+			want: true,
+		},
+		{
+			name: "other wallet misses",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), stranger, true)
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: false,
+		},
+		{
+			name: "collection case ignored",
+			run: func(cc *consentCache) bool {
+				ref := simRef("1")
+				ref.Collection = strings.ToLower(ref.Collection)
+				cc.put(ref, wallet, true)
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: true,
+		},
+		{
+			name: "expired",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				cc.now = func() time.Time { return now.Add(time.Minute) }
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: false,
+		},
+		{
+			name: "least recently used evicted",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				cc.put(simRef("2"), wallet, true)
+				cc.put(simRef("3"), wallet, true)
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: false,
+		},
+		{
+			name: "recent use survives eviction",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				cc.put(simRef("2"), wallet, true)
+				cc.get(simRef("1"), wallet)
+				cc.put(simRef("3"), wallet, true)
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: true,
+		},
+		{
+			name: "token purged",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				cc.put(simRef("1"), stranger, false)
+				cc.purgeToken(simRef("1"))
+				_, ok := cc.get(simRef("1"), wallet)
+				_, strangerOK := cc.get(simRef("1"), stranger)
+				return ok || strangerOK || len(cc.byToken) != 0
+			},
+			want: false,
+		},
+		{
+			name: "other token survives purge",
+			run: func(cc *consentCache) bool {
+				cc.put(simRef("1"), wallet, true)
+				cc.purgeToken(simRef("2"))
+				_, ok := cc.get(simRef("1"), wallet)
+				return ok
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := newConsentCache(time.Minute, 2)
+			cc.now = clock
+			if got := tt.run(cc); got != tt.want {
+				t.Errorf("cached = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsentCacheRevocation(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("revokeConsent", big.NewInt(1)).
+		Emits("ConsentRevoked", big.NewInt(1), wallet, big.NewInt(1700000000)).
+		Then(reg.Next("checkConsent", big.NewInt(1), wallet).Returns(false))
+	c, chain := newSimChecker(t, []*simchain.Contract{reg},
+		WithConsentCache(time.Hour, 0), WithPollInterval(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	states := make(chan ConsentState, 1)
+	if _, err := c.WatchConsentEvents(ctx, simRef("1"), func(state ConsentState) { states <- state }); err != nil {
+		t.Fatalf("WatchConsentEvents failed: %v", err)
+	}
+
+	if granted, err := c.CheckConsent(ctx, simRef("1"), wallet); err != nil || !granted {
+		t.Fatalf("before revocation: got %v, %v", granted, err)
+	}
+
+	transactSim(t, chain, "revokeConsent", big.NewInt(1))
+	if state, ok := receive(t, states); !ok || state != ConsentRevoked {
+		t.Fatalf("got %s (received %v), want %s", state, ok, ConsentRevoked)
+	}
+
+	// The cached grant is an hour from expiry, so only the purge can make this false
+	if granted, err := c.CheckConsent(ctx, simRef("1"), wallet); err != nil || granted {
+		t.Errorf("after revocation: got %v, %v", granted, err)
+	}
+}
+
+// countingSink counts IncCounter calls by metric name
+type countingSink struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (s *countingSink) IncCounter(name string, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[name]++
+}
+
+func (s *countingSink) ObserveHistogram(string, float64, map[string]string) {}
+
+func TestCheckConsentCachedMetrics(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(1), stranger).Returns(false)
+	sink := &countingSink{counters: make(map[string]int)}
+	c, _ := newSimChecker(t, []*simchain.Contract{reg}, WithConsentCache(time.Hour, 0), WithMetrics(sink))
+
+	// The second round is answered from the cache and still counts the denial
+	ctx := context.Background()
+	for round := 0; round < 2; round++ {
+		for _, w := range []common.Address{wallet, stranger} {
+			if _, err := c.CheckConsent(ctx, simRef("1"), w); err != nil {
+				t.Fatalf("CheckConsent failed: %v", err)
+			}
+		}
+	}
+
+	want := map[string]int{
+		chainrpc.MetricCacheMisses:   2,
+		chainrpc.MetricCacheHits:     2,
+		chainrpc.MetricConsentDenied: 2,
+	}
+	for name, n := range want {
+		if got := sink.counters[name]; got != n {
+			t.Errorf("%s = %d, want %d", name, got, n)
+		}
+	}
+}
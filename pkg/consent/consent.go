@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/chunking"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -23,215 +28,510 @@ const (
 
 // ConsentChecker verifies consent status on-chain
 type ConsentChecker struct {
-	client   *ethclient.Client
-	chainRPC map[string]string // chain name => RPC URL
+	mu          sync.Mutex
+	clients     map[biocid.Chain]*ethclient.Client // chain name => cached client
+	backends    map[biocid.Chain]chainrpc.Backend  // chain name => injected backend
+	subURLs     map[biocid.Chain]string            // chain name => websocket URL for subscriptions
+	subClients  map[biocid.Chain]*ethclient.Client // chain name => cached subscription client
+	chains      *biocid.ChainRegistry              // Known chains and their RPC endpoints
+	retry       chainrpc.RetryPolicy
+	timeout     time.Duration            // Deadline applied to RPC calls without one
+	polling     time.Duration            // Log polling interval when subscriptions are unavailable
+	standards   map[string]TokenStandard // chain/collection => detected standard
+	reconcile   uint64                   // Event lookback in blocks for GetConsentStateAt; 0 disables
+	checkRevoke bool                     // Confirm RevokeConsent took effect once mined
+	delegated   bool                     // Grant access to operators approved by the token owner
+	cache       *consentCache            // Optional CheckConsent result cache
+	fees        chainrpc.FeeStrategy
+	chainFees   map[biocid.Chain]chainrpc.FeeStrategy  // chain name => fee strategy override
+	customTypes bool                                   // Accept data types without a canonical form
+	limiters    map[biocid.Chain]*chainrpc.RateLimiter // chain name => request rate limiter
+	failovers   map[biocid.Chain]*chainrpc.Failover    // chain name => configured RPC endpoints
+	urlErrs     map[biocid.Chain]error                 // chain name => rejected WithRPCEndpoints URLs, reported on use
+	logger      chainrpc.Logger
+	metrics     chainrpc.MetricsSink
 }
 
 // NewConsentChecker creates a new consent checker
-func NewConsentChecker() *ConsentChecker {
-	return &ConsentChecker{
-		chainRPC: map[string]string{
-			"story":     "https://rpc.story.foundation",
-			"avalanche": "https://api.avax.network/ext/bc/C/rpc",
-			"ethereum":  "https://eth.llamarpc.com",
-		},
+func NewConsentChecker(opts ...Option) *ConsentChecker {
+	c := &ConsentChecker{
+		clients:    make(map[biocid.Chain]*ethclient.Client),
+		backends:   make(map[biocid.Chain]chainrpc.Backend),
+		subURLs:    make(map[biocid.Chain]string),
+		subClients: make(map[biocid.Chain]*ethclient.Client),
+		chains:     biocid.DefaultChains,
+		retry:      chainrpc.DefaultRetryPolicy(),
+		timeout:    chainrpc.DefaultTimeout,
+		polling:    defaultPollInterval,
+		standards:  make(map[string]TokenStandard),
+		chainFees:  make(map[biocid.Chain]chainrpc.FeeStrategy),
+		limiters:   make(map[biocid.Chain]*chainrpc.RateLimiter),
+		failovers:  make(map[biocid.Chain]*chainrpc.Failover),
+		urlErrs:    make(map[biocid.Chain]error),
+		logger:     chainrpc.NopLogger(),
+		metrics:    chainrpc.NopMetrics(),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // CheckConsent verifies if a wallet has active consent for an NFT
+// With WithDelegatedConsent enabled, operators approved by the token's owner also pass
 func (c *ConsentChecker) CheckConsent(ctx context.Context, nftRef biocid.NFTReference, wallet common.Address) (bool, error) {
-	// Connect to appropriate chain
-	client, err := c.getClient(nftRef.Chain)
-	if err != nil {
-		return false, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+	if granted, ok := c.cachedConsent(nftRef, wallet); ok {
+		c.observeConsent(nftRef, granted)
+		return granted, nil
 	}
 
-	// Get contract instance
-	contractAddr := common.HexToAddress(nftRef.Collection)
-
-	// TODO: Load ABI and create contract binding
-	// For now, we'll use a simple call
+	// Get contract instance on the appropriate chain
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return false, err
+	}
 
 	// Check if wallet owns the NFT or has permission
-	hasAccess, err := c.checkOnChainAccess(ctx, client, contractAddr, nftRef.TokenID, wallet)
+	hasAccess, err := c.checkOnChainAccess(ctx, contract, nftRef.TokenID, wallet)
 	if err != nil {
+		c.logger.Error("consent check failed", "nft", nftRef.String(), "wallet", wallet.Hex(), "error", err)
 		return false, fmt.Errorf("failed to check on-chain access: %w", err)
 	}
 
+	if !hasAccess && c.delegated {
+		hasAccess, err = c.checkDelegatedAccess(ctx, nftRef, wallet)
+		if err != nil {
+			c.logger.Error("delegated consent check failed", "nft", nftRef.String(), "wallet", wallet.Hex(), "error", err)
+			return false, fmt.Errorf("failed to check delegated access: %w", err)
+		}
+	}
+
+	c.cacheConsent(nftRef, wallet, hasAccess)
+	c.observeConsent(nftRef, hasAccess)
+
 	return hasAccess, nil
 }
 
+// observeConsent records the outcome of a CheckConsent call, whether or not it was cached
+func (c *ConsentChecker) observeConsent(nftRef biocid.NFTReference, granted bool) {
+	if !granted {
+		c.metrics.IncCounter(chainrpc.MetricConsentDenied, map[string]string{"chain": nftRef.Chain.String()})
+	}
+}
+
 // GetConsentState retrieves the current state of consent for an NFT
 func (c *ConsentChecker) GetConsentState(ctx context.Context, nftRef biocid.NFTReference) (ConsentState, error) {
-	client, err := c.getClient(nftRef.Chain)
+	state, _, err := c.GetConsentStateAt(ctx, nftRef)
+	return state, err
+}
+
+// GetConsentStateAt retrieves the consent state and the block at which it was determined
+// With WithEventReconciliation the contract view is checked against recent consent events
+// and the newest source wins
+func (c *ConsentChecker) GetConsentStateAt(ctx context.Context, nftRef biocid.NFTReference) (ConsentState, uint64, error) {
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
 	if err != nil {
-		return ConsentPending, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+		return ConsentPending, 0, err
 	}
 
-	contractAddr := common.HexToAddress(nftRef.Collection)
-
-	// TODO: Call contract to get consent state
-	// For now, return placeholder
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return ConsentPending, 0, err
+	}
 
-	_ = client
-	_ = contractAddr
+	client, err := c.getClient(ctx, nftRef.Chain)
+	if err != nil {
+		return ConsentPending, 0, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+	}
 
-	return ConsentActive, nil
-}
+	head, err := c.blockNumber(ctx, client)
+	if err != nil {
+		return ConsentPending, 0, err
+	}
 
-// WatchConsentEvents listens for consent revocation events
-func (c *ConsentChecker) WatchConsentEvents(ctx context.Context, nftRef biocid.NFTReference, callback func(ConsentState)) error {
-	client, err := c.getClient(nftRef.Chain)
+	out, err := c.callAt(ctx, contract, new(big.Int).SetUint64(head), "getConsentMetadata", tokenID)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+		return ConsentPending, 0, err
 	}
 
-	// TODO: Subscribe to contract events
-	// Watch for ConsentRevoked, ContentDeleted events
+	meta := *abi.ConvertType(out[0], new(consentMetadata)).(*consentMetadata)
+	state := ConsentState(meta.State)
+	if !state.valid() {
+		return ConsentPending, 0, fmt.Errorf("invalid consent state %d for token %s", meta.State, tokenID)
+	}
 
-	_ = client
-	_ = callback
+	if c.reconcile == 0 {
+		return state, head, nil
+	}
 
-	return nil
+	return c.reconcileConsentState(ctx, client, common.HexToAddress(nftRef.Collection), tokenID, state, head)
 }
 
 // VerifyDeletion verifies that content has been deleted on-chain
 func (c *ConsentChecker) VerifyDeletion(ctx context.Context, nftRef biocid.NFTReference) (bool, int, error) {
-	client, err := c.getClient(nftRef.Chain)
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+		return false, 0, err
 	}
 
-	contractAddr := common.HexToAddress(nftRef.Collection)
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return false, 0, err
+	}
 
-	// TODO: Call contract to check deletion proof
-	// Return: (isDeleted, nodeCount, error)
+	out, err := c.call(ctx, contract, "isDeleted", tokenID)
+	if err != nil {
+		return false, 0, err
+	}
 
-	_ = client
-	_ = contractAddr
+	deleted := *abi.ConvertType(out[0], new(bool)).(*bool)
+	nodeCount := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	if !nodeCount.IsInt64() {
+		return false, 0, fmt.Errorf("node count out of range for token %s: %s", tokenID, nodeCount)
+	}
 
-	return false, 0, nil
+	return deleted, int(nodeCount.Int64()), nil
 }
 
-// getClient returns an ethclient for the specified chain
-func (c *ConsentChecker) getClient(chain string) (*ethclient.Client, error) {
-	rpcURL, ok := c.chainRPC[chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", chain)
+// VerifyDeletionProof reports whether the deletion proof recorded for nftRef was built from chunks
+// Returns false without error when the token has not been deleted
+func (c *ConsentChecker) VerifyDeletionProof(ctx context.Context, nftRef biocid.NFTReference, chunks [][]byte) (bool, error) {
+	deleted, _, err := c.VerifyDeletion(ctx, nftRef)
+	if err != nil || !deleted {
+		return false, err
 	}
 
-	if c.client != nil {
-		return c.client, nil
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return false, err
 	}
 
-	client, err := ethclient.Dial(rpcURL)
+	tokenID, err := nftRef.TokenIDBig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		return false, err
 	}
 
-	c.client = client
-	return client, nil
+	out, err := c.call(ctx, contract, "getDeletionProof", tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	proof := *abi.ConvertType(out[0], new(deletionProof)).(*deletionProof)
+	return chunking.VerifyDeletionProof(chunks, proof.MerkleRoot, proof.NodeCount), nil
 }
 
-// checkOnChainAccess checks if wallet has access to NFT
-func (c *ConsentChecker) checkOnChainAccess(ctx context.Context, client *ethclient.Client, contract common.Address, tokenID string, wallet common.Address) (bool, error) {
-	// Convert tokenID to big.Int
-	tokenIDBig := new(big.Int)
-	tokenIDBig.SetString(tokenID, 10)
+// getClient returns the backend for the specified chain
+// Backends injected with WithBackend take precedence over dialing the chain's RPC URL
+// Dialing is abandoned when ctx is done, and happens without holding c.mu so a slow
+// endpoint does not hold up calls to other chains
+func (c *ConsentChecker) getClient(ctx context.Context, chain biocid.Chain) (chainrpc.Backend, error) {
+	backend, rpcURL, err := c.cachedClient(chain)
+	if backend != nil || err != nil {
+		return backend, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", chainrpc.ErrRPCConnect, err)
+	}
 
-	// TODO: Call checkConsent(tokenId, wallet) on contract
-	// For now, return placeholder
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", chainrpc.ErrRPCConnect, err)
+	}
 
-	_ = ctx
-	_ = contract
-	_ = tokenIDBig
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return true, nil
+	// A concurrent call may have dialed the chain first; keep its client
+	limiter := c.limiters[chain]
+	if existing, ok := c.clients[chain]; ok {
+		client.Close()
+		return chainrpc.RateLimit(existing, limiter), nil
+	}
+	c.clients[chain] = client
+	return chainrpc.RateLimit(client, limiter), nil
 }
 
-// GetOwner returns the owner of an NFT
-func (c *ConsentChecker) GetOwner(ctx context.Context, nftRef biocid.NFTReference) (common.Address, error) {
-	client, err := c.getClient(nftRef.Chain)
-	if err != nil {
-		return common.Address{}, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+// cachedClient returns chain's backend if one is injected, configured or already dialed,
+// otherwise the RPC URL to dial
+func (c *ConsentChecker) cachedClient(chain biocid.Chain) (chainrpc.Backend, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limiter := c.limiters[chain]
+
+	if backend, ok := c.backends[chain]; ok {
+		return chainrpc.RateLimit(backend, limiter), "", nil
 	}
 
-	contractAddr := common.HexToAddress(nftRef.Collection)
+	if err, ok := c.urlErrs[chain]; ok {
+		return nil, "", err
+	}
 
-	// Convert tokenID to big.Int
-	tokenIDBig := new(big.Int)
-	tokenIDBig.SetString(nftRef.TokenID, 10)
+	if failover, ok := c.failovers[chain]; ok {
+		return chainrpc.RateLimit(failover, limiter), "", nil
+	}
+
+	if client, ok := c.clients[chain]; ok {
+		return chainrpc.RateLimit(client, limiter), "", nil
+	}
+
+	info, ok := c.chains.Lookup(chain.String())
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", biocid.ErrUnsupportedChain, chain)
+	}
+	return nil, info.RPCURL, nil
+}
+
+// Close closes all dialed RPC clients
+// Backends injected with WithBackend are owned by the caller and left open
+func (c *ConsentChecker) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// TODO: Call ownerOf or balanceOf on ERC1155
-	// For now, return zero address
+	for chain, client := range c.clients {
+		client.Close()
+		delete(c.clients, chain)
+	}
 
-	_ = client
-	_ = contractAddr
-	_ = tokenIDBig
+	// Failovers stay configured and redial on next use
+	for _, failover := range c.failovers {
+		failover.Close()
+	}
 
-	return common.Address{}, nil
+	for chain, client := range c.subClients {
+		client.Close()
+		delete(c.subClients, chain)
+	}
+}
+
+// checkOnChainAccess checks if wallet has access to NFT
+func (c *ConsentChecker) checkOnChainAccess(ctx context.Context, contract *bind.BoundContract, tokenID string, wallet common.Address) (bool, error) {
+	// Convert tokenID to big.Int
+	tokenIDBig, err := biocid.ParseTokenID(tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := c.call(ctx, contract, "checkConsent", tokenIDBig, wallet)
+	if err != nil {
+		return false, err
+	}
+
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
 }
 
 // ConsentOptions for creating new consents
 type ConsentOptions struct {
-	ContentHash []byte
-	DataType    string
-	DataSize    uint64
-	BioCID      string
+	ContentHash    biocid.ContentHash
+	DataType       string
+	DataSize       uint64
+	BioCID         string
+	AllowDuplicate bool // Mint even if the signer already holds a live consent for ContentHash
 }
 
 // CreateConsent mints a new NFT and grants consent on-chain
-func (c *ConsentChecker) CreateConsent(ctx context.Context, chain string, collection common.Address, opts ConsentOptions, signer *bind.TransactOpts) (string, error) {
-	client, err := c.getClient(chain)
+// Unless opts.AllowDuplicate is set, an existing pending or active token owned by the signer
+// with the same ContentHash is returned instead, so retried requests do not double-mint
+func (c *ConsentChecker) CreateConsent(ctx context.Context, chain biocid.Chain, collection common.Address, opts ConsentOptions, signer *bind.TransactOpts) (string, error) {
+	if signer == nil {
+		return "", fmt.Errorf("signer is required")
+	}
+
+	if opts.ContentHash.IsZero() {
+		return "", fmt.Errorf("content hash is required")
+	}
+
+	dataType, err := c.normalizeDataType(opts.DataType)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to %s: %w", chain, err)
+		return "", err
+	}
+	opts.DataType = dataType
+
+	if err := c.checkChainID(ctx, chain); err != nil {
+		return "", err
 	}
 
-	// TODO: Call mintAndGrantConsent on contract
-	// Return tokenID as string
+	if !opts.AllowDuplicate {
+		existing, err := c.findConsentByContent(ctx, chain, collection, signer.From, opts.ContentHash)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up existing consent: %w", err)
+		}
+		if existing != nil {
+			return existing.String(), nil
+		}
+	}
 
-	_ = client
-	_ = collection
-	_ = opts
-	_ = signer
+	tokenID, err := c.mintConsent(ctx, chain, collection, opts.ContentHash, opts, signer)
+	if err != nil {
+		return "", err
+	}
 
-	return "1", nil
+	return tokenID.String(), nil
 }
 
 // RevokeConsent revokes consent for an NFT on-chain
+// With WithRevocationCheck enabled it also waits for the transaction to be mined and
+// confirms the token reads back as revoked, returning ErrRevocationNotApplied otherwise
 func (c *ConsentChecker) RevokeConsent(ctx context.Context, nftRef biocid.NFTReference, signer *bind.TransactOpts) error {
-	client, err := c.getClient(nftRef.Chain)
+	if signer == nil {
+		return fmt.Errorf("signer is required")
+	}
+
+	tokenID, err := nftRef.TokenIDBig()
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+		return err
 	}
 
-	contractAddr := common.HexToAddress(nftRef.Collection)
+	if err := c.checkChainID(ctx, nftRef.Chain); err != nil {
+		return err
+	}
+
+	tx, err := c.transact(ctx, nftRef, signer, "revokeConsent", tokenID)
+	if err != nil {
+		return err
+	}
+
+	c.invalidate(nftRef)
+
+	if !c.checkRevoke {
+		return nil
+	}
+
+	if _, err := c.waitMined(ctx, nftRef.Chain, tx); err != nil {
+		return err
+	}
 
-	// TODO: Call revokeConsent(tokenId) on contract
+	// Drop anything cached while the transaction was pending
+	c.invalidate(nftRef)
 
-	_ = client
-	_ = contractAddr
-	_ = signer
+	state, err := c.GetConsentState(ctx, nftRef)
+	if err != nil {
+		return fmt.Errorf("failed to confirm revocation: %w", err)
+	}
+	if state != ConsentRevoked {
+		return fmt.Errorf("%w: token %s is %s after transaction %s",
+			ErrRevocationNotApplied, nftRef, state, tx.Hash().Hex())
+	}
 
 	return nil
 }
 
 // BurnAndDelete burns NFT and triggers deletion on-chain
+// merkleRoot and nodeCount are normally produced by chunking.BuildDeletionProof
 func (c *ConsentChecker) BurnAndDelete(ctx context.Context, nftRef biocid.NFTReference, merkleRoot [32]byte, nodeCount *big.Int, signer *bind.TransactOpts) error {
-	client, err := c.getClient(nftRef.Chain)
+	if signer == nil {
+		return fmt.Errorf("signer is required")
+	}
+
+	if nodeCount == nil || nodeCount.Sign() <= 0 {
+		return fmt.Errorf("node count must be positive")
+	}
+
+	tokenID, err := nftRef.TokenIDBig()
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+		return err
 	}
 
-	contractAddr := common.HexToAddress(nftRef.Collection)
+	if err := c.checkChainID(ctx, nftRef.Chain); err != nil {
+		return err
+	}
+
+	if _, err := c.transact(ctx, nftRef, signer, "burnAndDelete", tokenID, merkleRoot, nodeCount); err != nil {
+		return err
+	}
+
+	c.invalidate(nftRef)
+
+	return nil
+}
+
+// checkChainID verifies the chain's backend reports the chain ID registered for it
+func (c *ConsentChecker) checkChainID(ctx context.Context, chain biocid.Chain) error {
+	info, ok := c.chains.Lookup(chain.String())
+	if !ok {
+		return nil
+	}
 
-	// TODO: Call burnAndDelete(tokenId, merkleRoot, nodeCount) on contract
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
 
-	_ = client
-	_ = contractAddr
-	_ = merkleRoot
-	_ = nodeCount
-	_ = signer
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
 
+	if err := chainrpc.CheckChainID(ctx, client, info.ChainID); err != nil {
+		return fmt.Errorf("%s: %w", chain, err)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// cachedConsent returns a cached CheckConsent result, counting the hit or miss
+func (c *ConsentChecker) cachedConsent(nftRef biocid.NFTReference, wallet common.Address) (bool, bool) {
+	if c.cache == nil {
+		return false, false
+	}
+
+	if granted, ok := c.cache.get(nftRef, wallet); ok {
+		c.metrics.IncCounter(chainrpc.MetricCacheHits, nil)
+		c.logger.Debug("consent cache hit", "nft", nftRef.String(), "wallet", wallet.Hex())
+		return granted, true
+	}
+	c.metrics.IncCounter(chainrpc.MetricCacheMisses, nil)
+	c.logger.Debug("consent cache miss", "nft", nftRef.String(), "wallet", wallet.Hex())
+	return false, false
+}
+
+// cacheConsent stores a CheckConsent result when caching is enabled
+func (c *ConsentChecker) cacheConsent(nftRef biocid.NFTReference, wallet common.Address, granted bool) {
+	if c.cache != nil {
+		c.cache.put(nftRef, wallet, granted)
+	}
+}
+
+// invalidate drops cached consent results for a token
+func (c *ConsentChecker) invalidate(nftRef biocid.NFTReference) {
+	if c.cache != nil {
+		c.cache.purgeToken(nftRef)
+	}
+}
+
+// HealthCheck checks every configured chain's RPC concurrently
+// Each chain in the registry or injected with WithBackend maps to nil when it is reachable
+// and reports the registered chain ID, or to the error encountered
+func (c *ConsentChecker) HealthCheck(ctx context.Context) map[string]error {
+	return chainrpc.HealthCheckAll(ctx, c.configuredChains(), func(ctx context.Context, chain string) error {
+		client, err := c.getClient(ctx, biocid.Chain(chain))
+		if err != nil {
+			return err
+		}
+
+		var expected *big.Int
+		if info, ok := c.chains.Lookup(chain); ok {
+			expected = info.ChainID
+		}
+
+		ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+		defer cancel()
+
+		return chainrpc.CheckHealth(ctx, client, expected)
+	})
+}
+
+// configuredChains returns the registry's chains plus any chains with injected backends
+func (c *ConsentChecker) configuredChains() []string {
+	chains := c.chains.Names()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for chain := range c.backends {
+		if _, ok := c.chains.Lookup(chain.String()); !ok {
+			chains = append(chains, chain.String())
+		}
+	}
+	return chains
+}
@@ -0,0 +1,466 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/chunking"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	wallet   = common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	stranger = common.HexToAddress("0x00000000000000000000000000000000000000B2")
+)
+
+func TestCheckConsent(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(1), stranger).Returns(false)
+	reg.On("checkConsent", big.NewInt(2), wallet).Reverts()
+	c, _ := newSimChecker(t, []*simchain.Contract{reg})
+
+	tests := []struct {
+		name    string
+		ref     biocid.NFTReference
+		wallet  common.Address
+		want    bool
+		wantErr bool
+	}{
+		{name: "granted", ref: simRef("1"), wallet: wallet, want: true},
+		{name: "denied", ref: simRef("1"), wallet: stranger, want: false},
+		{name: "reverts", ref: simRef("2"), wallet: wallet, wantErr: true},
+		{name: "invalid token ID", ref: simRef("0x1"), wallet: wallet, wantErr: true},
+		{name: "invalid collection", ref: biocid.NFTReference{Chain: simChain, Collection: "nope", TokenID: "1"}, wallet: wallet, wantErr: true},
+		{name: "unknown chain", ref: biocid.NFTReference{Chain: "nowhere", Collection: simCollection.Hex(), TokenID: "1"}, wallet: wallet, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CheckConsent(context.Background(), tt.ref, tt.wallet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckConsent failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConsentErrors(t *testing.T) {
+	chains := biocid.NewChainRegistry()
+	chains.Register("broken", "unsupported-scheme://rpc", big.NewInt(1))
+	c := NewConsentChecker(WithChainRegistry(chains))
+	t.Cleanup(c.Close)
+
+	tests := []struct {
+		name string
+		ref  biocid.NFTReference
+		want error
+	}{
+		{name: "unsupported chain", ref: biocid.NFTReference{Chain: "nowhere", Collection: simCollection.Hex(), TokenID: "1"}, want: biocid.ErrUnsupportedChain},
+		{name: "dial fails", ref: biocid.NFTReference{Chain: "broken", Collection: simCollection.Hex(), TokenID: "1"}, want: chainrpc.ErrRPCConnect},
+		{name: "invalid collection", ref: biocid.NFTReference{Chain: "broken", Collection: "nope", TokenID: "1"}, want: biocid.ErrInvalidAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.CheckConsent(context.Background(), tt.ref, wallet)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// logEvent is one event recorded by captureLogger
+type logEvent struct {
+	level   string
+	msg     string
+	keyvals []interface{}
+}
+
+// captureLogger records every event it receives
+type captureLogger struct {
+	mu     sync.Mutex
+	events []logEvent
+}
+
+func (l *captureLogger) log(level, msg string, keyvals []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, logEvent{level: level, msg: msg, keyvals: keyvals})
+}
+
+func (l *captureLogger) Debug(msg string, keyvals ...interface{}) { l.log("debug", msg, keyvals) }
+func (l *captureLogger) Info(msg string, keyvals ...interface{})  { l.log("info", msg, keyvals) }
+func (l *captureLogger) Error(msg string, keyvals ...interface{}) { l.log("error", msg, keyvals) }
+
+// find returns the first event with msg, or nil
+func (l *captureLogger) find(msg string) *logEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.events {
+		if l.events[i].msg == msg {
+			return &l.events[i]
+		}
+	}
+	return nil
+}
+
+// value returns the value logged under key
+func (e *logEvent) value(key string) interface{} {
+	for i := 0; i+1 < len(e.keyvals); i += 2 {
+		if e.keyvals[i] == key {
+			return e.keyvals[i+1]
+		}
+	}
+	return nil
+}
+
+func TestCheckConsentLogging(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+	reg.On("checkConsent", big.NewInt(2), wallet).Reverts()
+
+	tests := []struct {
+		name      string
+		tokenID   string
+		wantLevel string
+		wantMsgs  []string
+	}{
+		{name: "success", tokenID: "1", wantLevel: "debug", wantMsgs: []string{"rpc call"}},
+		{name: "rpc failure", tokenID: "2", wantLevel: "error", wantMsgs: []string{"rpc call failed", "consent check failed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &captureLogger{}
+			c, _ := newSimChecker(t, []*simchain.Contract{reg}, WithLogger(logger))
+			c.CheckConsent(context.Background(), simRef(tt.tokenID), wallet)
+
+			for _, msg := range tt.wantMsgs {
+				event := logger.find(msg)
+				if event == nil {
+					t.Fatalf("no %q event in %+v", msg, logger.events)
+				}
+				if event.level != tt.wantLevel {
+					t.Errorf("%q logged at %s, want %s", msg, event.level, tt.wantLevel)
+				}
+				if tt.wantLevel == "error" && event.value("error") == nil {
+					t.Errorf("%q has no error value: %v", msg, event.keyvals)
+				}
+			}
+			if event := logger.find(tt.wantMsgs[0]); event != nil && event.value("method") != "checkConsent" {
+				t.Errorf("method = %v, want checkConsent", event.value("method"))
+			}
+		})
+	}
+}
+
+func TestBurnAndDeleteProof(t *testing.T) {
+	stored := [][]byte{[]byte("##fileformat=VCFv4.2\n"), []byte("chr1\t100\t.\tA\tG\n")}
+	root, count := chunking.BuildDeletionProof(stored)
+
+	reg := newSimConsent()
+	reg.On("isDeleted", big.NewInt(1)).Returns(false, new(big.Int))
+	reg.On("burnAndDelete", big.NewInt(1), root, count).Then(
+		reg.Next("isDeleted", big.NewInt(1)).Returns(true, count),
+		reg.Next("getDeletionProof", big.NewInt(1)).Returns(deletionProof{
+			TokenId:    big.NewInt(1),
+			DeletedAt:  big.NewInt(1700000000),
+			MerkleRoot: root,
+			NodeCount:  count,
+			Verifiers:  []common.Address{},
+		}),
+	)
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+	ctx := context.Background()
+
+	if ok, err := c.VerifyDeletionProof(ctx, simRef("1"), stored); ok || err != nil {
+		t.Fatalf("before deletion got %v, %v; want false without error", ok, err)
+	}
+
+	if err := c.BurnAndDelete(ctx, simRef("1"), root, nil, signer); err == nil {
+		t.Error("expected a missing node count to be rejected")
+	}
+	if err := c.BurnAndDelete(ctx, simRef("1"), root, count, signer); err != nil {
+		t.Fatalf("BurnAndDelete failed: %v", err)
+	}
+	chain.Backend.Commit()
+
+	deleted, nodes, err := c.VerifyDeletion(ctx, simRef("1"))
+	if err != nil || !deleted || int64(nodes) != count.Int64() {
+		t.Fatalf("VerifyDeletion = %v, %d, %v; want deleted with %s nodes", deleted, nodes, err, count)
+	}
+
+	tests := []struct {
+		name   string
+		chunks [][]byte
+		want   bool
+	}{
+		{name: "stored chunks", chunks: stored, want: true},
+		{name: "other content", chunks: [][]byte{[]byte("##fileformat=VCFv4.2\n")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := c.VerifyDeletionProof(ctx, simRef("1"), tt.chunks)
+			if err != nil {
+				t.Fatalf("VerifyDeletionProof failed: %v", err)
+			}
+			if ok != tt.want {
+				t.Errorf("got %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConsentAfterRevoke(t *testing.T) {
+	addr, bound, chain := deployMockConsent(t)
+	owner := chain.Address(0)
+	ref := biocid.NFTReference{Chain: simChain, Collection: addr.Hex(), TokenID: "1"}
+
+	c := NewConsentChecker(
+		WithBackend(simChain, chain.Miner()),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+		WithConsentCache(time.Hour, 0),
+	)
+	defer c.Close()
+
+	// The bound instance sees the same contract the checker does
+	var out []interface{}
+	if err := bound.Call(&bind.CallOpts{}, &out, "checkConsent", big.NewInt(1), owner); err != nil || !out[0].(bool) {
+		t.Fatalf("bound checkConsent = %v, %v; want true", out, err)
+	}
+
+	ctx := context.Background()
+	steps := []struct {
+		name      string
+		act       func() error
+		wallet    common.Address
+		want      bool
+		wantState ConsentState
+	}{
+		{name: "owner before revoking", wallet: owner, want: true, wantState: ConsentActive},
+		{name: "stranger before revoking", wallet: stranger, want: false, wantState: ConsentActive},
+		{
+			name:      "owner after revoking",
+			act:       func() error { return c.RevokeConsent(ctx, ref, chain.Auth(t, 0)) },
+			wallet:    owner,
+			want:      false,
+			wantState: ConsentRevoked,
+		},
+	}
+	for _, step := range steps {
+		if step.act != nil {
+			if err := step.act(); err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+		}
+
+		// The cached result from before the revocation must not survive it
+		got, err := c.CheckConsent(ctx, ref, step.wallet)
+		if err != nil {
+			t.Fatalf("%s: CheckConsent failed: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: CheckConsent = %v, want %v", step.name, got, step.want)
+		}
+
+		state, err := c.GetConsentState(ctx, ref)
+		if err != nil {
+			t.Fatalf("%s: GetConsentState failed: %v", step.name, err)
+		}
+		if state != step.wantState {
+			t.Errorf("%s: state = %s, want %s", step.name, state, step.wantState)
+		}
+	}
+}
+
+func TestWithRPCEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		chain   biocid.Chain
+		wantErr bool
+	}{
+		{name: "valid", opts: []Option{WithRPCEndpoints("story", "https://a.example.org", "https://b.example.org")}, chain: "story"},
+		{name: "no URLs", opts: []Option{WithRPCEndpoints("story")}, chain: "story", wantErr: true},
+		{name: "unsupported scheme", opts: []Option{WithRPCEndpoints("story", "https://a.example.org", "ftp://b.example.org")}, chain: "story", wantErr: true},
+		{
+			name:    "invalid chain beside a valid one",
+			opts:    []Option{WithRPCEndpoints("story", "https://a.example.org"), WithRPCEndpoints("sepolia", "")},
+			chain:   "sepolia",
+			wantErr: true,
+		},
+		{
+			name:  "valid chain beside an invalid one",
+			opts:  []Option{WithRPCEndpoints("story", "https://a.example.org"), WithRPCEndpoints("sepolia", "")},
+			chain: "story",
+		},
+		{
+			name:  "invalid URLs replaced",
+			opts:  []Option{WithRPCEndpoints("story", ""), WithRPCEndpoints("story", "https://a.example.org")},
+			chain: "story",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConsentChecker(tt.opts...)
+			defer c.Close()
+
+			// Invalid URLs surface on first use rather than from the constructor
+			_, err := c.getClient(context.Background(), tt.chain)
+			if tt.wantErr {
+				if !errors.Is(err, chainrpc.ErrInvalidEndpoint) {
+					t.Fatalf("got %v, want %v", err, chainrpc.ErrInvalidEndpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getClient failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestRevokeConsent(t *testing.T) {
+	revoked := activeMetadata(1)
+	revoked.State = uint8(ConsentRevoked)
+
+	// Token 1 is revoked once mined, token 2's revocation leaves it active, and token 3 reverts
+	reg := newSimConsent()
+	reg.On("getConsentMetadata", big.NewInt(1)).Returns(activeMetadata(1))
+	reg.On("getConsentMetadata", big.NewInt(2)).Returns(activeMetadata(2))
+	reg.On("revokeConsent", big.NewInt(1)).Then(reg.Next("getConsentMetadata", big.NewInt(1)).Returns(revoked))
+	reg.On("revokeConsent", big.NewInt(2))
+	reg.On("revokeConsent", big.NewInt(3)).Reverts()
+
+	tests := []struct {
+		name     string
+		check    bool
+		tokenID  string
+		noSigner bool
+		wantErr  bool
+		wantIs   error
+	}{
+		{name: "unconfirmed", tokenID: "2"},
+		{name: "confirmed", check: true, tokenID: "1"},
+		{name: "not applied", check: true, tokenID: "2", wantErr: true, wantIs: ErrRevocationNotApplied},
+		{name: "reverted", check: true, tokenID: "3", wantErr: true},
+		{name: "no signer", tokenID: "1", noSigner: true, wantErr: true},
+		{name: "bad token ID", tokenID: "one", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, chain := newSimChecker(t, []*simchain.Contract{reg}, WithRevocationCheck(tt.check))
+			signer := chain.Auth(t, 0)
+			if tt.noSigner {
+				signer = nil
+			}
+
+			err := c.RevokeConsent(context.Background(), simRef(tt.tokenID), signer)
+			if tt.wantErr {
+				if err == nil || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Fatalf("got %v, want an error matching %v", err, tt.wantIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RevokeConsent failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetClientContext(t *testing.T) {
+	// A websocket endpoint that accepts connections but never completes the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	chains := biocid.NewChainRegistry()
+	chains.Register("lazy", "http://127.0.0.1:1", big.NewInt(1))
+	chains.Register("stalled", "ws://"+ln.Addr().String(), big.NewInt(2))
+	c := NewConsentChecker(WithChainRegistry(chains))
+	defer c.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		chain   biocid.Chain
+		wantErr bool
+		wantIs  error // Checked when set; a dial cut short by ctx may report a plain i/o timeout
+	}{
+		{name: "canceled before dialing", ctx: canceled, chain: "lazy", wantErr: true, wantIs: context.Canceled},
+		{name: "deadline while dialing", ctx: short, chain: "stalled", wantErr: true},
+		{name: "live context", ctx: context.Background(), chain: "lazy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := c.getClient(tt.ctx, tt.chain)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("getClient failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, chainrpc.ErrRPCConnect) || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+				t.Fatalf("got %v, want %v wrapping %v", err, chainrpc.ErrRPCConnect, tt.wantIs)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("gave up after %s, past the context's deadline", elapsed)
+			}
+		})
+	}
+
+	// A stalled dial does not hold up calls to other chains
+	stalled, cancelStalled := context.WithTimeout(context.Background(), time.Second)
+	defer cancelStalled()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.getClient(stalled, "stalled")
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := c.getClient(context.Background(), "lazy"); err != nil {
+		t.Fatalf("getClient failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %s on another chain's dial", elapsed)
+	}
+	<-done
+}
@@ -0,0 +1,101 @@
+package consent
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed ConsentRegistry.abi.json
+var consentABIJSON string
+
+// consentABI is the parsed ConsentRegistry contract ABI
+var consentABI = mustParseABI(consentABIJSON)
+
+// mustParseABI parses an embedded contract ABI
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(fmt.Sprintf("consent: invalid embedded ABI: %v", err))
+	}
+	return parsed
+}
+
+// collection returns a binding to the ConsentRegistry collection on the given chain
+func (c *ConsentChecker) collection(ctx context.Context, chain biocid.Chain, collection string) (*bind.BoundContract, error) {
+	return c.bindContract(ctx, chain, collection, consentABI)
+}
+
+// bindContract returns a binding to a collection contract using the given ABI
+func (c *ConsentChecker) bindContract(ctx context.Context, chain biocid.Chain, collection string, contractABI abi.ABI) (*bind.BoundContract, error) {
+	if !common.IsHexAddress(collection) {
+		return nil, fmt.Errorf("%w: %s", biocid.ErrInvalidAddress, collection)
+	}
+
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	return bind.NewBoundContract(common.HexToAddress(collection), contractABI, client, client, client), nil
+}
+
+// consentMetadata mirrors the ConsentRegistry.ConsentMetadata tuple
+type consentMetadata struct {
+	Owner       common.Address
+	TokenId     *big.Int
+	State       uint8
+	CreatedAt   *big.Int
+	RevokedAt   *big.Int
+	ContentHash [32]byte
+	DataType    string
+	DataSize    *big.Int
+	BioCID      [32]byte
+}
+
+// deletionProof mirrors the ConsentRegistry.DeletionProof tuple
+type deletionProof struct {
+	TokenId    *big.Int
+	DeletedAt  *big.Int
+	MerkleRoot [32]byte
+	NodeCount  *big.Int
+	Verifiers  []common.Address
+}
+
+// call invokes a read-only collection method, retrying transient RPC failures
+func (c *ConsentChecker) call(ctx context.Context, contract *bind.BoundContract, method string, args ...interface{}) ([]interface{}, error) {
+	return c.callAt(ctx, contract, nil, method, args...)
+}
+
+// callAt invokes a read-only collection method against a specific block (nil for latest)
+func (c *ConsentChecker) callAt(ctx context.Context, contract *bind.BoundContract, block *big.Int, method string, args ...interface{}) ([]interface{}, error) {
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var out []interface{}
+	err := c.retryFor(method).Do(ctx, func() error {
+		out = nil
+		return contract.Call(&bind.CallOpts{Context: ctx, BlockNumber: block}, &out, method, args...)
+	})
+	chainrpc.ObserveCall(c.logger, c.metrics, method, start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	return out, nil
+}
+
+// retryFor returns the checker's retry policy, logging and counting each retry of op
+func (c *ConsentChecker) retryFor(op string) chainrpc.RetryPolicy {
+	return c.retry.WithRetryHook(chainrpc.RetryHook(c.logger, c.metrics, op))
+}
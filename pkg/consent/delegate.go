@@ -0,0 +1,64 @@
+package consent
+
+import (
+	"context"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// checkDelegatedAccess reports whether wallet is an operator approved by the owner of a token
+// whose consent is active; ERC721 collections also accept the token's single approved address
+func (c *ConsentChecker) checkDelegatedAccess(ctx context.Context, nftRef biocid.NFTReference, wallet common.Address) (bool, error) {
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return false, err
+	}
+
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := c.call(ctx, contract, "getConsentMetadata", tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	meta := *abi.ConvertType(out[0], new(consentMetadata)).(*consentMetadata)
+	if ConsentState(meta.State) != ConsentActive {
+		return false, nil
+	}
+
+	standard, err := c.DetectStandard(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return false, err
+	}
+
+	views, err := c.bindContract(ctx, nftRef.Chain, nftRef.Collection, tokenStandardsABI)
+	if err != nil {
+		return false, err
+	}
+
+	owner := meta.Owner
+	if standard == StandardERC721 {
+		out, err := c.call(ctx, views, "getApproved", tokenID)
+		if err != nil {
+			return false, err
+		}
+		if *abi.ConvertType(out[0], new(common.Address)).(*common.Address) == wallet {
+			return true, nil
+		}
+
+		if owner, err = c.GetOwner(ctx, nftRef); err != nil {
+			return false, err
+		}
+	}
+
+	out, err = c.call(ctx, views, "isApprovedForAll", owner, wallet)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
+}
@@ -0,0 +1,92 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc721Consent is the address of a scripted ConsentRegistry variant that is an ERC721 collection
+var erc721Consent = common.HexToAddress("0x00000000000000000000000000000000000C0721")
+
+// withTokenViews returns consentABI extended with the ERC721 views ConsentRegistry lacks
+func withTokenViews() abi.ABI {
+	merged := consentABI
+	merged.Methods = make(map[string]abi.Method, len(consentABI.Methods)+len(tokenStandardsABI.Methods))
+	for name, method := range consentABI.Methods {
+		merged.Methods[name] = method
+	}
+	for name, method := range tokenStandardsABI.Methods {
+		if _, ok := merged.Methods[name]; !ok {
+			merged.Methods[name] = method
+		}
+	}
+	return merged
+}
+
+func TestDelegatedConsent(t *testing.T) {
+	operator := common.HexToAddress("0x00000000000000000000000000000000000000E5")
+	approved := common.HexToAddress("0x00000000000000000000000000000000000000E6")
+	revoked := activeMetadata(2)
+	revoked.State = uint8(ConsentRevoked)
+
+	// Token 1 is active and token 2 revoked; wallet owns both and approved operator for all of them
+	script := func(reg *simchain.Contract) {
+		reg.On("checkConsent").Returns(false)
+		reg.On("checkConsent", big.NewInt(1), wallet).Returns(true)
+		reg.On("getConsentMetadata", big.NewInt(1)).Returns(activeMetadata(1))
+		reg.On("getConsentMetadata", big.NewInt(2)).Returns(revoked)
+		reg.On("isApprovedForAll").Returns(false)
+		reg.On("isApprovedForAll", wallet, operator).Returns(true)
+	}
+
+	erc1155 := newSimConsent()
+	script(erc1155)
+	erc1155.On("supportsInterface", interfaceERC721).Returns(false)
+	erc1155.On("supportsInterface", interfaceERC1155).Returns(true)
+
+	erc721 := simchain.NewContract(erc721Consent, withTokenViews())
+	script(erc721)
+	erc721.On("supportsInterface", interfaceERC721).Returns(true)
+	erc721.On("ownerOf").Returns(wallet)
+	erc721.On("getApproved").Returns(common.Address{})
+	erc721.On("getApproved", big.NewInt(1)).Returns(approved)
+
+	tests := []struct {
+		name       string
+		delegated  bool
+		collection common.Address
+		tokenID    string
+		wallet     common.Address
+		want       bool
+	}{
+		{name: "owner", delegated: true, collection: simCollection, tokenID: "1", wallet: wallet, want: true},
+		{name: "operator", delegated: true, collection: simCollection, tokenID: "1", wallet: operator, want: true},
+		{name: "operator without delegation", collection: simCollection, tokenID: "1", wallet: operator},
+		{name: "operator on revoked consent", delegated: true, collection: simCollection, tokenID: "2", wallet: operator},
+		{name: "stranger", delegated: true, collection: simCollection, tokenID: "1", wallet: stranger},
+		{name: "ERC721 operator", delegated: true, collection: erc721Consent, tokenID: "1", wallet: operator, want: true},
+		{name: "ERC721 approved address", delegated: true, collection: erc721Consent, tokenID: "1", wallet: approved, want: true},
+		{name: "ERC721 approved address without delegation", collection: erc721Consent, tokenID: "1", wallet: approved},
+		{name: "ERC721 stranger", delegated: true, collection: erc721Consent, tokenID: "1", wallet: stranger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newSimChecker(t, []*simchain.Contract{erc1155, erc721}, WithDelegatedConsent(tt.delegated))
+			ref := refIn(tt.collection)
+			ref.TokenID = tt.tokenID
+
+			got, err := c.CheckConsent(context.Background(), ref, tt.wallet)
+			if err != nil {
+				t.Fatalf("CheckConsent failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package consent
+
+import "errors"
+
+var (
+	// ErrUnsupportedStandard is returned when a collection is neither ERC721 nor ERC1155
+	ErrUnsupportedStandard = errors.New("unsupported token standard")
+
+	// ErrNoSingleOwner is returned by GetOwner for ERC1155 collections
+	ErrNoSingleOwner = errors.New("token standard has no single owner")
+
+	// ErrConsentRevoked is returned when consent for a token has been revoked
+	ErrConsentRevoked = errors.New("consent revoked")
+
+	// ErrConsentDeleted is returned when a token's content has been burned and deleted
+	ErrConsentDeleted = errors.New("consent deleted")
+
+	// ErrRevocationNotApplied is returned when a mined revocation leaves the token's consent unrevoked
+	ErrRevocationNotApplied = errors.New("revocation not applied")
+)
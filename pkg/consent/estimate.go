@@ -0,0 +1,66 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EstimateCreateConsent dry-runs the mint performed by CreateConsent as sent by from
+// The duplicate check is skipped, so this always estimates a fresh mint
+func (c *ConsentChecker) EstimateCreateConsent(ctx context.Context, chain biocid.Chain, collection common.Address, from common.Address, opts ConsentOptions) (*chainrpc.GasEstimate, error) {
+	if opts.ContentHash.IsZero() {
+		return nil, fmt.Errorf("content hash is required")
+	}
+
+	dataType, err := c.normalizeDataType(opts.DataType)
+	if err != nil {
+		return nil, err
+	}
+	opts.DataType = dataType
+
+	bioCID, err := bioCIDBytes(opts.BioCID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.estimate(ctx, chain, collection, from, "mintAndGrantConsent",
+		[32]byte(opts.ContentHash), opts.DataType, new(big.Int).SetUint64(opts.DataSize), bioCID)
+}
+
+// EstimateRevokeConsent dry-runs RevokeConsent as sent by from
+func (c *ConsentChecker) EstimateRevokeConsent(ctx context.Context, nftRef biocid.NFTReference, from common.Address) (*chainrpc.GasEstimate, error) {
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.estimate(ctx, nftRef.Chain, common.HexToAddress(nftRef.Collection), from, "revokeConsent", tokenID)
+}
+
+// EstimateBurnAndDelete dry-runs BurnAndDelete as sent by from
+func (c *ConsentChecker) EstimateBurnAndDelete(ctx context.Context, nftRef biocid.NFTReference, merkleRoot [32]byte, nodeCount *big.Int, from common.Address) (*chainrpc.GasEstimate, error) {
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.estimate(ctx, nftRef.Chain, common.HexToAddress(nftRef.Collection), from, "burnAndDelete", tokenID, merkleRoot, nodeCount)
+}
+
+// estimate packs a collection write and estimates its gas without signing or sending it
+func (c *ConsentChecker) estimate(ctx context.Context, chain biocid.Chain, collection common.Address, from common.Address, method string, args ...interface{}) (*chainrpc.GasEstimate, error) {
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	return chainrpc.EstimateGas(ctx, client, from, collection, consentABI, method, args...)
+}
@@ -0,0 +1,60 @@
+package consent
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+func TestEstimateRevokeConsent(t *testing.T) {
+	reg := newSimConsent()
+	reg.On("revokeConsent", big.NewInt(1))
+	reg.On("revokeConsent", big.NewInt(2)).Reverts()
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+	from := chain.Address(0)
+
+	tests := []struct {
+		name    string
+		tokenID string
+		wantErr bool
+	}{
+		{name: "estimated", tokenID: "1"},
+		{name: "reverts", tokenID: "2", wantErr: true},
+		{name: "invalid token ID", tokenID: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			before, err := chain.Backend.PendingNonceAt(ctx, from)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+
+			est, err := c.EstimateRevokeConsent(ctx, simRef(tt.tokenID), from)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("EstimateRevokeConsent failed: %v", err)
+				}
+				want, _ := consentABI.Pack("revokeConsent", big.NewInt(1))
+				if !bytes.Equal(est.Data, want) || est.To != simCollection || est.Gas == 0 {
+					t.Errorf("unexpected estimate %+v", est)
+				}
+			}
+
+			after, err := chain.Backend.PendingNonceAt(ctx, from)
+			if err != nil {
+				t.Fatalf("PendingNonceAt failed: %v", err)
+			}
+			if after != before {
+				t.Errorf("nonce moved from %d to %d, want no transaction", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ListConsentedTokens returns the IDs of tokens in collection that wallet currently has consent for,
+// reconstructed from the collection's whole event history
+// Use ListConsentedTokensFrom to bound the scan
+func (c *ConsentChecker) ListConsentedTokens(ctx context.Context, chain biocid.Chain, collection, wallet common.Address) ([]string, error) {
+	return c.ListConsentedTokensFrom(ctx, chain, collection, wallet, 0)
+}
+
+// ListConsentedTokensFrom returns the IDs of tokens in collection that wallet has consent for,
+// replaying consent and permission events from fromBlock to the chain head
+// A wallet has consent on a token whose consent is active if it minted the token or was granted
+// permission and not since revoked; fromBlock must precede the mints of interest, since a token
+// first seen through a reactivation treats the reactivating wallet as its owner
+func (c *ConsentChecker) ListConsentedTokensFrom(
+	ctx context.Context,
+	chain biocid.Chain,
+	collection common.Address,
+	wallet common.Address,
+	fromBlock uint64,
+) ([]string, error) {
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	head, err := c.blockNumber(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if head < fromBlock {
+		return []string{}, nil
+	}
+
+	granted := consentABI.Events["ConsentGranted"].ID
+	revoked := consentABI.Events["ConsentRevoked"].ID
+	deleted := consentABI.Events["ContentDeleted"].ID
+	permitted := consentABI.Events["PermissionGranted"].ID
+	unpermitted := consentABI.Events["PermissionRevoked"].ID
+
+	// Any token the wallet can have consent on was minted by it or shared with it
+	candidates, err := c.scanCollectionLogs(ctx, client, collection, fromBlock, head, [][]common.Hash{
+		{granted, permitted},
+		nil,
+		{common.BytesToHash(wallet.Bytes())},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenTopics []common.Hash
+	seen := make(map[common.Hash]bool)
+	for _, log := range candidates {
+		if log.Removed || len(log.Topics) < 2 || seen[log.Topics[1]] {
+			continue
+		}
+		seen[log.Topics[1]] = true
+		tokenTopics = append(tokenTopics, log.Topics[1])
+	}
+	if len(tokenTopics) == 0 {
+		return []string{}, nil
+	}
+
+	history, err := c.scanCollectionLogs(ctx, client, collection, fromBlock, head, [][]common.Hash{
+		{granted, revoked, deleted, permitted, unpermitted},
+		tokenTopics,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type holding struct {
+		owner     common.Address
+		hasOwner  bool
+		active    bool
+		deleted   bool
+		permitted bool
+	}
+
+	holdings := make(map[common.Hash]*holding)
+	for _, log := range history {
+		if log.Removed || len(log.Topics) < 2 {
+			continue
+		}
+
+		h, ok := holdings[log.Topics[1]]
+		if !ok {
+			h = &holding{}
+			holdings[log.Topics[1]] = h
+		}
+
+		switch log.Topics[0] {
+		case granted:
+			if len(log.Topics) >= 3 && !h.hasOwner {
+				h.owner = common.BytesToAddress(log.Topics[2].Bytes())
+				h.hasOwner = true
+			}
+			h.active = !h.deleted
+		case revoked:
+			h.active = false
+		case deleted:
+			h.active = false
+			h.deleted = true
+		case permitted, unpermitted:
+			if len(log.Topics) >= 3 && common.BytesToAddress(log.Topics[2].Bytes()) == wallet {
+				h.permitted = log.Topics[0] == permitted
+			}
+		}
+	}
+
+	var tokenIDs []*big.Int
+	for topic, h := range holdings {
+		if h.active && ((h.hasOwner && h.owner == wallet) || h.permitted) {
+			tokenIDs = append(tokenIDs, topic.Big())
+		}
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool { return tokenIDs[i].Cmp(tokenIDs[j]) < 0 })
+
+	out := make([]string, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		out[i] = tokenID.String()
+	}
+	return out, nil
+}
+
+// scanCollectionLogs collects collection logs between fromBlock and toBlock inclusive, in chain order
+func (c *ConsentChecker) scanCollectionLogs(
+	ctx context.Context,
+	client chainrpc.Backend,
+	collection common.Address,
+	fromBlock uint64,
+	toBlock uint64,
+	topics [][]common.Hash,
+) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{collection},
+		Topics:    topics,
+	}
+
+	filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		var logs []types.Log
+		err := c.retryFor("eth_getLogs").Do(ctx, func() error {
+			var err error
+			logs, err = c.filterLogs(ctx, client, q)
+			return err
+		})
+		return logs, err
+	}
+
+	var logs []types.Log
+	err := chainrpc.ScanLogs(ctx, filter, query, chainrpc.DefaultLogChunk, func(chunk []types.Log) error {
+		logs = append(logs, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,82 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestListConsentedTokens(t *testing.T) {
+	granted := func(id int64, owner common.Address) []interface{} {
+		return []interface{}{big.NewInt(id), owner, [32]byte{byte(id)}, "vcf", [32]byte{}}
+	}
+
+	// Each scripted call replays one step of the collection's history
+	reg := newSimConsent()
+	mint := reg.On("mintAndGrantConsent", [32]byte{1}, "vcf", big.NewInt(0), [32]byte{})
+	for _, g := range [][]interface{}{granted(1, wallet), granted(2, wallet), granted(3, stranger), granted(4, stranger), granted(5, wallet)} {
+		mint.Emits("ConsentGranted", g...)
+	}
+	reg.On("grantPermission", big.NewInt(3), wallet).
+		Emits("PermissionGranted", big.NewInt(3), wallet, stranger).
+		Emits("PermissionGranted", big.NewInt(4), wallet, stranger)
+	reg.On("revokeConsent", big.NewInt(2)).
+		Emits("ConsentRevoked", big.NewInt(2), wallet, big.NewInt(1700000000)).
+		Emits("PermissionRevoked", big.NewInt(4), wallet, stranger).
+		Emits("ContentDeleted", big.NewInt(5), [32]byte{0xaa}, big.NewInt(4))
+	// Token 2 is reactivated; a grant after deletion leaves token 5 deleted
+	reg.On("mintAndGrantConsent", [32]byte{2}, "vcf", big.NewInt(0), [32]byte{}).
+		Emits("ConsentGranted", granted(2, wallet)...).
+		Emits("ConsentGranted", granted(5, wallet)...)
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+
+	head := func() uint64 {
+		t.Helper()
+		header, err := chain.Backend.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("HeaderByNumber failed: %v", err)
+		}
+		return header.Number.Uint64()
+	}
+
+	transactSim(t, chain, "mintAndGrantConsent", [32]byte{1}, "vcf", big.NewInt(0), [32]byte{})
+	transactSim(t, chain, "grantPermission", big.NewInt(3), wallet)
+	transactSim(t, chain, "revokeConsent", big.NewInt(2))
+	revokedAt := head()
+	transactSim(t, chain, "mintAndGrantConsent", [32]byte{2}, "vcf", big.NewInt(0), [32]byte{})
+
+	tests := []struct {
+		name       string
+		collection common.Address
+		wallet     common.Address
+		fromBlock  uint64
+		want       []string
+	}{
+		{name: "owned, reactivated and permitted", collection: simCollection, wallet: wallet, want: []string{"1", "2", "3"}},
+		{name: "owner of shared tokens", collection: simCollection, wallet: stranger, want: []string{"3", "4"}},
+		{name: "bounded scan", collection: simCollection, wallet: wallet, fromBlock: revokedAt, want: []string{"2"}},
+		{name: "past the head", collection: simCollection, wallet: wallet, fromBlock: head() + 10, want: []string{}},
+		{name: "no history", collection: simCollection, wallet: common.HexToAddress("0xC3"), want: []string{}},
+		{name: "other collection", collection: common.HexToAddress("0xC0FFEE"), wallet: wallet, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ListConsentedTokensFrom(context.Background(), simChain, tt.collection, tt.wallet, tt.fromBlock)
+			if err != nil {
+				t.Fatalf("ListConsentedTokensFrom failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	all, err := c.ListConsentedTokens(context.Background(), simChain, simCollection, wallet)
+	if err != nil || !reflect.DeepEqual(all, []string{"1", "2", "3"}) {
+		t.Errorf("ListConsentedTokens = %v, %v", all, err)
+	}
+}
@@ -0,0 +1,170 @@
+package consent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+)
+
+// Option configures a ConsentChecker
+type Option func(*ConsentChecker)
+
+// WithChainRegistry sets the registry used to resolve chain names to RPC endpoints
+func WithChainRegistry(chains *biocid.ChainRegistry) Option {
+	return func(c *ConsentChecker) {
+		if chains != nil {
+			c.chains = chains
+		}
+	}
+}
+
+// WithRetryPolicy sets how transient RPC failures are retried
+func WithRetryPolicy(policy chainrpc.RetryPolicy) Option {
+	return func(c *ConsentChecker) {
+		c.retry = policy
+	}
+}
+
+// WithPollInterval sets how often consent events are polled when subscriptions are unavailable
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *ConsentChecker) {
+		if interval > 0 {
+			c.polling = interval
+		}
+	}
+}
+
+// WithDefaultTimeout sets the deadline applied to RPC calls whose context has none
+// A non-positive duration disables the default deadline
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *ConsentChecker) {
+		c.timeout = d
+	}
+}
+
+// WithEventReconciliation makes GetConsentStateAt reconcile the contract view against
+// consent events from the last lookback blocks, guarding against lagging RPC nodes
+func WithEventReconciliation(lookback uint64) Option {
+	return func(c *ConsentChecker) {
+		c.reconcile = lookback
+	}
+}
+
+// WithRevocationCheck makes RevokeConsent wait for its transaction to be mined and
+// confirm the consent state reads back as revoked before returning
+func WithRevocationCheck(enabled bool) Option {
+	return func(c *ConsentChecker) {
+		c.checkRevoke = enabled
+	}
+}
+
+// WithDelegatedConsent makes CheckConsent also grant access to operators the token's owner
+// approved with setApprovalForAll, or on ERC721 collections with approve
+// Consent on the token must still be active
+func WithDelegatedConsent(enabled bool) Option {
+	return func(c *ConsentChecker) {
+		c.delegated = enabled
+	}
+}
+
+// WithFeeStrategy sets how write methods price transactions on every chain
+func WithFeeStrategy(strategy chainrpc.FeeStrategy) Option {
+	return func(c *ConsentChecker) {
+		c.fees = strategy
+	}
+}
+
+// WithChainFeeStrategy overrides the fee strategy for a single chain
+func WithChainFeeStrategy(chain biocid.Chain, strategy chainrpc.FeeStrategy) Option {
+	return func(c *ConsentChecker) {
+		c.chainFees[chain] = strategy
+	}
+}
+
+// WithCustomDataTypes makes CreateConsent accept data types without a canonical form
+// By default such types are rejected with datatype.ErrUnknownDataType
+func WithCustomDataTypes(allow bool) Option {
+	return func(c *ConsentChecker) {
+		c.customTypes = allow
+	}
+}
+
+// WithRPCEndpoints sends chain's requests to urls instead of its registered RPC URL
+// Endpoints are tried in order; the one in use is kept until it fails to dial or returns
+// chainrpc.DefaultFailoverThreshold transient errors in a row, then the next one takes over
+// Backends injected with WithBackend take precedence; if a URL is invalid, chain's calls fail
+// with chainrpc.ErrInvalidEndpoint
+func WithRPCEndpoints(chain biocid.Chain, urls ...string) Option {
+	return func(c *ConsentChecker) {
+		if old, ok := c.failovers[chain]; ok {
+			old.Close()
+			delete(c.failovers, chain)
+		}
+		delete(c.urlErrs, chain)
+
+		failover, err := chainrpc.NewFailover(urls, 0)
+		if err != nil {
+			c.urlErrs[chain] = fmt.Errorf("RPC endpoints for %s: %w", chain, err)
+			return
+		}
+		c.failovers[chain] = failover
+	}
+}
+
+// WithRateLimit paces requests to chain's RPC endpoint to rps per second, with bursts of up to burst
+// Waiting requests give up when their context is done; a non-positive rps removes the limit
+func WithRateLimit(chain biocid.Chain, rps float64, burst int) Option {
+	return func(c *ConsentChecker) {
+		if rps <= 0 {
+			delete(c.limiters, chain)
+			return
+		}
+		c.limiters[chain] = chainrpc.NewRateLimiter(rps, burst)
+	}
+}
+
+// WithBackend uses backend for chain instead of dialing its RPC URL
+// Useful for simulated backends in tests; the caller remains responsible for closing it
+func WithBackend(chain biocid.Chain, backend chainrpc.Backend) Option {
+	return func(c *ConsentChecker) {
+		c.backends[chain] = backend
+	}
+}
+
+// WithSubscriptionURL sets a websocket endpoint used by WatchConsentEvents for chain
+// Without one, chains with HTTP RPC URLs are watched by polling logs
+func WithSubscriptionURL(chain biocid.Chain, wsURL string) Option {
+	return func(c *ConsentChecker) {
+		c.subURLs[chain] = wsURL
+	}
+}
+
+// WithConsentCache caches CheckConsent results for ttl, keeping at most maxEntries
+// Entries for a token are purged as soon as WatchConsentEvents observes its revocation
+func WithConsentCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *ConsentChecker) {
+		if ttl > 0 {
+			c.cache = newConsentCache(ttl, maxEntries)
+		}
+	}
+}
+
+// WithLogger sets the logger receiving RPC, retry, and cache events
+func WithLogger(logger chainrpc.Logger) Option {
+	return func(c *ConsentChecker) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithMetrics sets the sink receiving RPC latency, retry, and consent counters
+func WithMetrics(metrics chainrpc.MetricsSink) Option {
+	return func(c *ConsentChecker) {
+		if metrics != nil {
+			c.metrics = metrics
+		}
+	}
+}
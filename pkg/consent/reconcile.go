@@ -0,0 +1,81 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// reconcileConsentState checks a view result taken at viewBlock against recent consent events
+// An event mined after viewBlock overrides the view; otherwise the view stands
+func (c *ConsentChecker) reconcileConsentState(
+	ctx context.Context,
+	client chainrpc.Backend,
+	collection common.Address,
+	tokenID *big.Int,
+	view ConsentState,
+	viewBlock uint64,
+) (ConsentState, uint64, error) {
+	from := uint64(0)
+	if viewBlock > c.reconcile {
+		from = viewBlock - c.reconcile
+	}
+
+	logs, err := c.filterLogs(ctx, client, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		Addresses: []common.Address{collection},
+		Topics: [][]common.Hash{
+			{
+				consentABI.Events["ConsentGranted"].ID,
+				consentABI.Events["ConsentRevoked"].ID,
+				consentABI.Events["ContentDeleted"].ID,
+			},
+			{common.BigToHash(tokenID)},
+		},
+	})
+	if err != nil {
+		return ConsentPending, 0, err
+	}
+
+	state, block := view, viewBlock
+	var latest *logPos
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) == 0 || log.BlockNumber <= viewBlock {
+			continue
+		}
+
+		var next ConsentState
+		switch log.Topics[0] {
+		case consentABI.Events["ConsentGranted"].ID:
+			next = ConsentActive
+		case consentABI.Events["ConsentRevoked"].ID:
+			next = ConsentRevoked
+		case consentABI.Events["ContentDeleted"].ID:
+			next = ConsentDeleted
+		default:
+			continue
+		}
+
+		pos := &logPos{block: log.BlockNumber, index: log.Index}
+		if latest == nil || pos.after(latest) {
+			latest = pos
+			state, block = next, log.BlockNumber
+		}
+	}
+
+	return state, block, nil
+}
+
+// logPos orders logs by block number and log index
+type logPos struct {
+	block uint64
+	index uint
+}
+
+// after reports whether p was emitted after q
+func (p *logPos) after(q *logPos) bool {
+	return p.block > q.block || (p.block == q.block && p.index > q.index)
+}
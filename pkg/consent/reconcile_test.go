@@ -0,0 +1,94 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// laggingBackend reports a stale chain head, like an RPC node that has not caught up
+// Calls are answered from the latest state, which the scripted view does not change
+type laggingBackend struct {
+	*backends.SimulatedBackend
+	head uint64
+}
+
+func (b *laggingBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = new(big.Int).SetUint64(b.head)
+	}
+	return b.SimulatedBackend.HeaderByNumber(ctx, number)
+}
+
+func (b *laggingBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	return b.SimulatedBackend.CallContract(ctx, msg, nil)
+}
+
+// activeMetadata is the getConsentMetadata tuple of an active token
+func activeMetadata(tokenID int64) consentMetadata {
+	return consentMetadata{
+		Owner:     wallet,
+		TokenId:   big.NewInt(tokenID),
+		State:     uint8(ConsentActive),
+		CreatedAt: big.NewInt(1700000000),
+		RevokedAt: new(big.Int),
+		DataType:  "vcf",
+		DataSize:  big.NewInt(1024),
+	}
+}
+
+func TestGetConsentStateReconciled(t *testing.T) {
+	reg := newWatchedConsent()
+	reg.On("getConsentMetadata", big.NewInt(1)).Returns(activeMetadata(1))
+	reg.On("getConsentMetadata", big.NewInt(2)).Returns(activeMetadata(2))
+	_, chain := newSimChecker(t, []*simchain.Contract{reg})
+
+	stale := chain.Backend.Blockchain().CurrentBlock().Number.Uint64()
+	transactSim(t, chain, "revokeConsent", big.NewInt(1))
+	revoked := chain.Backend.Blockchain().CurrentBlock().Number.Uint64()
+	transactSim(t, chain, "revokeConsent", big.NewInt(2))
+	transactSim(t, chain, "burnAndDelete", big.NewInt(2), [32]byte{0xaa}, big.NewInt(4))
+	deleted := chain.Backend.Blockchain().CurrentBlock().Number.Uint64()
+	head := deleted
+
+	tests := []struct {
+		name      string
+		token     string
+		head      uint64
+		lookback  uint64
+		want      ConsentState
+		wantBlock uint64
+	}{
+		{name: "view only", token: "1", head: stale, want: ConsentActive, wantBlock: stale},
+		{name: "revoke after view", token: "1", head: stale, lookback: 100, want: ConsentRevoked, wantBlock: revoked},
+		{name: "newest event wins", token: "2", head: stale, lookback: 100, want: ConsentDeleted, wantBlock: deleted},
+		{name: "events before view ignored", token: "1", head: head, lookback: 100, want: ConsentActive, wantBlock: head},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{
+				WithBackend(simChain, &laggingBackend{SimulatedBackend: chain.Backend, head: tt.head}),
+				WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+			}
+			if tt.lookback > 0 {
+				opts = append(opts, WithEventReconciliation(tt.lookback))
+			}
+			c := NewConsentChecker(opts...)
+			defer c.Close()
+
+			state, block, err := c.GetConsentStateAt(context.Background(), simRef(tt.token))
+			if err != nil {
+				t.Fatalf("GetConsentStateAt failed: %v", err)
+			}
+			if state != tt.want || block != tt.wantBlock {
+				t.Errorf("got %s at block %d, want %s at block %d", state, block, tt.want, tt.wantBlock)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Session binds a ConsentChecker to one signer so write methods can be called without passing it
+// Each call signs with a fresh copy of the signer, so nonces and fees are looked up again rather
+// than reused; calls on one session are serialized
+type Session struct {
+	mu      sync.Mutex
+	checker *ConsentChecker
+	signer  *bind.TransactOpts
+}
+
+// NewSession returns a session sending transactions from signer through c
+func NewSession(c *ConsentChecker, signer *bind.TransactOpts) (*Session, error) {
+	if c == nil {
+		return nil, fmt.Errorf("checker is required")
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("signer is required")
+	}
+	return &Session{checker: c, signer: signer}, nil
+}
+
+// From returns the address transactions are sent from
+func (s *Session) From() common.Address {
+	return s.signer.From
+}
+
+// CreateConsent mints a consent NFT from the session's signer
+func (s *Session) CreateConsent(ctx context.Context, chain biocid.Chain, collection common.Address, opts ConsentOptions) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checker.CreateConsent(ctx, chain, collection, opts, chainrpc.FreshTransactOpts(ctx, s.signer))
+}
+
+// RevokeConsent revokes consent on nftRef from the session's signer
+func (s *Session) RevokeConsent(ctx context.Context, nftRef biocid.NFTReference) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checker.RevokeConsent(ctx, nftRef, chainrpc.FreshTransactOpts(ctx, s.signer))
+}
@@ -0,0 +1,75 @@
+package consent
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestNewSession(t *testing.T) {
+	c, chain := newSimChecker(t, nil)
+	signer := chain.Auth(t, 0)
+
+	tests := []struct {
+		name    string
+		c       *ConsentChecker
+		signer  *bind.TransactOpts
+		wantErr bool
+	}{
+		{name: "valid", c: c, signer: signer},
+		{name: "no checker", signer: signer, wantErr: true},
+		{name: "no signer", c: c, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSession(tt.c, tt.signer)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSession failed: %v", err)
+			}
+			if s.From() != signer.From {
+				t.Errorf("From = %s, want %s", s.From().Hex(), signer.From.Hex())
+			}
+		})
+	}
+}
+
+func TestSessionRevokeConsent(t *testing.T) {
+	addr, _, chain := deployMockConsent(t)
+	ref := biocid.NFTReference{Chain: simChain, Collection: addr.Hex(), TokenID: "1"}
+
+	c := NewConsentChecker(
+		WithBackend(simChain, chain.Miner()),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	)
+	defer c.Close()
+
+	// A stale nonce pinned on the signer is dropped for each session call
+	signer := chain.Auth(t, 0)
+	signer.Nonce = big.NewInt(0)
+
+	s, err := NewSession(c, signer)
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.RevokeConsent(ctx, ref); err != nil {
+		t.Fatalf("RevokeConsent failed: %v", err)
+	}
+	if granted, err := c.CheckConsent(ctx, ref, chain.Address(0)); err != nil || granted {
+		t.Errorf("after revoking: got %v, %v", granted, err)
+	}
+	if signer.Nonce.Sign() != 0 {
+		t.Errorf("signer nonce changed to %s", signer.Nonce)
+	}
+}
@@ -0,0 +1,93 @@
+package consent
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// simChain is the chain name simulated-backend tests register their backend under
+const simChain = "story"
+
+// simCollection is the address of the scripted ConsentRegistry
+var simCollection = common.HexToAddress("0x00000000000000000000000000000000000C0150")
+
+// newSimConsent returns a scripted ConsentRegistry
+func newSimConsent() *simchain.Contract {
+	return simchain.NewContract(simCollection, consentABI)
+}
+
+// newSimChecker starts a simulated chain holding the given contracts and returns a checker using it
+func newSimChecker(t testing.TB, contracts []*simchain.Contract, opts ...Option) (*ConsentChecker, *simchain.Chain) {
+	t.Helper()
+
+	chain := simchain.New(t, contracts...)
+	opts = append([]Option{
+		WithBackend(simChain, chain.Miner()),
+		WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	}, opts...)
+
+	c := NewConsentChecker(opts...)
+	t.Cleanup(c.Close)
+	return c, chain
+}
+
+// simRef returns the NFT reference of a token in the scripted collection
+func simRef(tokenID string) biocid.NFTReference {
+	return biocid.NFTReference{Chain: simChain, Collection: simCollection.Hex(), TokenID: tokenID}
+}
+
+// transactSim sends a consent registry transaction from account 0 and mines it
+func transactSim(t testing.TB, chain *simchain.Chain, method string, args ...interface{}) {
+	t.Helper()
+
+	bound := bind.NewBoundContract(simCollection, consentABI, chain.Backend, chain.Backend, chain.Backend)
+	if _, err := bound.Transact(chain.Auth(t, 0), method, args...); err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	chain.Backend.Commit()
+}
+
+// deployMockConsent deploys a ConsentRegistry mock on a fresh simulated chain and returns its
+// address and a bound instance
+// Token 1 belongs to account 0 with active consent, and only the owner passes checkConsent;
+// once revokeConsent(1) is mined, checkConsent fails and getConsentMetadata reports it revoked
+func deployMockConsent(t testing.TB) (common.Address, *bind.BoundContract, *simchain.Chain) {
+	t.Helper()
+
+	reg := newSimConsent()
+	chain := simchain.New(t, reg)
+	owner := chain.Address(0)
+
+	active := consentMetadata{
+		Owner:     owner,
+		TokenId:   big.NewInt(1),
+		State:     uint8(ConsentActive),
+		CreatedAt: big.NewInt(1700000000),
+		RevokedAt: new(big.Int),
+		DataType:  "vcf",
+		DataSize:  big.NewInt(1024),
+	}
+	revoked := active
+	revoked.State = uint8(ConsentRevoked)
+	revoked.RevokedAt = big.NewInt(1700000100)
+
+	reg.On("checkConsent").Returns(false)
+	reg.On("checkConsent", big.NewInt(1), owner).Returns(true)
+	reg.On("getConsentMetadata", big.NewInt(1)).Returns(active)
+	reg.On("revokeConsent", big.NewInt(1)).
+		Emits("ConsentRevoked", big.NewInt(1), owner, revoked.RevokedAt).
+		Then(
+			reg.Next("checkConsent", big.NewInt(1), owner).Returns(false),
+			reg.Next("getConsentMetadata", big.NewInt(1)).Returns(revoked),
+		)
+	chain.Apply(t, reg)
+
+	bound := bind.NewBoundContract(simCollection, consentABI, chain.Backend, chain.Backend, chain.Backend)
+	return simCollection, bound, chain
+}
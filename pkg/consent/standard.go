@@ -0,0 +1,191 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenStandard identifies the NFT standard implemented by a collection
+type TokenStandard int
+
+const (
+	StandardUnknown TokenStandard = iota
+	StandardERC721
+	StandardERC1155
+)
+
+// ERC165 interface IDs
+var (
+	interfaceERC721  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	interfaceERC1155 = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+// tokenStandardsABI covers the ERC165/ERC721/ERC1155 views used for ownership and approval checks
+var tokenStandardsABI = mustParseABI(`[
+	{"type":"function","name":"supportsInterface","stateMutability":"view","inputs":[{"name":"interfaceId","type":"bytes4"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"ownerOf","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOfBatch","stateMutability":"view","inputs":[{"name":"accounts","type":"address[]"},{"name":"ids","type":"uint256[]"}],"outputs":[{"name":"","type":"uint256[]"}]},
+	{"type":"function","name":"isApprovedForAll","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"operator","type":"address"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"getApproved","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]}
+]`)
+
+// DetectStandard reports whether a collection is ERC721 or ERC1155 via ERC165
+// Results are cached per chain and collection
+func (c *ConsentChecker) DetectStandard(ctx context.Context, chain biocid.Chain, collection string) (TokenStandard, error) {
+	key := chain.String() + "/" + strings.ToLower(collection)
+
+	c.mu.Lock()
+	standard, ok := c.standards[key]
+	c.mu.Unlock()
+	if ok {
+		return standard, nil
+	}
+
+	contract, err := c.bindContract(ctx, chain, collection, tokenStandardsABI)
+	if err != nil {
+		return StandardUnknown, err
+	}
+
+	for _, candidate := range []struct {
+		id       [4]byte
+		standard TokenStandard
+	}{
+		{interfaceERC721, StandardERC721},
+		{interfaceERC1155, StandardERC1155},
+	} {
+		out, err := c.call(ctx, contract, "supportsInterface", candidate.id)
+		if err != nil {
+			return StandardUnknown, err
+		}
+
+		if *abi.ConvertType(out[0], new(bool)).(*bool) {
+			standard = candidate.standard
+			break
+		}
+	}
+
+	if standard == StandardUnknown {
+		return StandardUnknown, fmt.Errorf("%w: %s", ErrUnsupportedStandard, collection)
+	}
+
+	c.mu.Lock()
+	c.standards[key] = standard
+	c.mu.Unlock()
+
+	return standard, nil
+}
+
+// GetOwner returns the owner of an NFT
+// Only ERC721 collections have a single owner; use GetBalance for ERC1155
+func (c *ConsentChecker) GetOwner(ctx context.Context, nftRef biocid.NFTReference) (common.Address, error) {
+	// Convert tokenID to big.Int
+	tokenIDBig, err := nftRef.TokenIDBig()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	standard, err := c.DetectStandard(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if standard != StandardERC721 {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrNoSingleOwner, nftRef.Collection)
+	}
+
+	contract, err := c.bindContract(ctx, nftRef.Chain, nftRef.Collection, tokenStandardsABI)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	out, err := c.call(ctx, contract, "ownerOf", tokenIDBig)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return *abi.ConvertType(out[0], new(common.Address)).(*common.Address), nil
+}
+
+// GetBalance returns how many units of an ERC1155 token a wallet holds
+func (c *ConsentChecker) GetBalance(ctx context.Context, nftRef biocid.NFTReference, wallet common.Address) (*big.Int, error) {
+	tokenIDBig, err := nftRef.TokenIDBig()
+	if err != nil {
+		return nil, err
+	}
+
+	standard, err := c.DetectStandard(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if standard != StandardERC1155 {
+		return nil, fmt.Errorf("%w: balanceOf requires ERC1155: %s", ErrUnsupportedStandard, nftRef.Collection)
+	}
+
+	contract, err := c.bindContract(ctx, nftRef.Chain, nftRef.Collection, tokenStandardsABI)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.call(ctx, contract, "balanceOf", wallet, tokenIDBig)
+	if err != nil {
+		return nil, err
+	}
+
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// CheckConsentAny reports whether wallet holds a nonzero balance of any of tokenIDs
+// in the ERC1155 collection of nftRef, using a single balanceOfBatch call
+// An empty tokenIDs list checks nftRef.TokenID alone
+func (c *ConsentChecker) CheckConsentAny(ctx context.Context, nftRef biocid.NFTReference, tokenIDs []string, wallet common.Address) (bool, error) {
+	if len(tokenIDs) == 0 {
+		tokenIDs = []string{nftRef.TokenID}
+	}
+
+	ids := make([]*big.Int, len(tokenIDs))
+	accounts := make([]common.Address, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		id, err := biocid.ParseTokenID(tokenID)
+		if err != nil {
+			return false, err
+		}
+		ids[i] = id
+		accounts[i] = wallet
+	}
+
+	standard, err := c.DetectStandard(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return false, err
+	}
+
+	if standard != StandardERC1155 {
+		return false, fmt.Errorf("%w: balanceOfBatch requires ERC1155: %s", ErrUnsupportedStandard, nftRef.Collection)
+	}
+
+	contract, err := c.bindContract(ctx, nftRef.Chain, nftRef.Collection, tokenStandardsABI)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := c.call(ctx, contract, "balanceOfBatch", accounts, ids)
+	if err != nil {
+		return false, err
+	}
+
+	balances := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+	for _, balance := range balances {
+		if balance != nil && balance.Sign() > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,164 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	erc721Collection  = common.HexToAddress("0x0000000000000000000000000000000000000721")
+	erc1155Collection = common.HexToAddress("0x0000000000000000000000000000000000001155")
+	plainCollection   = common.HexToAddress("0x0000000000000000000000000000000000000165")
+)
+
+// newStandardCollections scripts an ERC721, an ERC1155, and a collection implementing neither
+func newStandardCollections() []*simchain.Contract {
+	erc721 := simchain.NewContract(erc721Collection, tokenStandardsABI)
+	erc721.On("supportsInterface", interfaceERC721).Returns(true)
+	erc721.On("ownerOf", big.NewInt(1)).Returns(wallet)
+
+	erc1155 := simchain.NewContract(erc1155Collection, tokenStandardsABI)
+	erc1155.On("supportsInterface", interfaceERC721).Returns(false)
+	erc1155.On("supportsInterface", interfaceERC1155).Returns(true)
+	erc1155.On("balanceOf", wallet, big.NewInt(1)).Returns(big.NewInt(3))
+	erc1155.On("balanceOf").Returns(big.NewInt(0))
+	ids := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	erc1155.On("balanceOfBatch", []common.Address{wallet}, []*big.Int{big.NewInt(1)}).
+		Returns([]*big.Int{big.NewInt(3)})
+	erc1155.On("balanceOfBatch", []common.Address{wallet, wallet, wallet}, ids).
+		Returns([]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(2)})
+	erc1155.On("balanceOfBatch", []common.Address{stranger, stranger, stranger}, ids).
+		Returns([]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)})
+
+	plain := simchain.NewContract(plainCollection, tokenStandardsABI)
+	plain.On("supportsInterface").Returns(false)
+
+	return []*simchain.Contract{erc721, erc1155, plain}
+}
+
+// refIn returns the NFT reference of token 1 in collection
+func refIn(collection common.Address) biocid.NFTReference {
+	return biocid.NFTReference{Chain: simChain, Collection: collection.Hex(), TokenID: "1"}
+}
+
+func TestDetectStandard(t *testing.T) {
+	c, _ := newSimChecker(t, newStandardCollections())
+
+	tests := []struct {
+		name       string
+		collection common.Address
+		want       TokenStandard
+		wantErr    error
+	}{
+		{name: "erc721", collection: erc721Collection, want: StandardERC721},
+		{name: "erc1155", collection: erc1155Collection, want: StandardERC1155},
+		{name: "neither", collection: plainCollection, wantErr: ErrUnsupportedStandard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.DetectStandard(context.Background(), simChain, tt.collection.Hex())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectStandard failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(c.standards) != 2 {
+		t.Errorf("cached %d standards, want 2", len(c.standards))
+	}
+}
+
+func TestGetOwnerAndBalance(t *testing.T) {
+	c, _ := newSimChecker(t, newStandardCollections())
+	ctx := context.Background()
+
+	owner, err := c.GetOwner(ctx, refIn(erc721Collection))
+	if err != nil || owner != wallet {
+		t.Errorf("GetOwner(erc721) = %s, %v; want %s", owner.Hex(), err, wallet.Hex())
+	}
+	if _, err := c.GetOwner(ctx, refIn(erc1155Collection)); !errors.Is(err, ErrNoSingleOwner) {
+		t.Errorf("GetOwner(erc1155): got %v, want %v", err, ErrNoSingleOwner)
+	}
+
+	balance, err := c.GetBalance(ctx, refIn(erc1155Collection), wallet)
+	if err != nil || balance.Int64() != 3 {
+		t.Errorf("GetBalance(erc1155) = %v, %v; want 3", balance, err)
+	}
+	if _, err := c.GetBalance(ctx, refIn(erc721Collection), wallet); !errors.Is(err, ErrUnsupportedStandard) {
+		t.Errorf("GetBalance(erc721): got %v, want %v", err, ErrUnsupportedStandard)
+	}
+
+	tests := []struct {
+		name       string
+		collection common.Address
+		wallet     common.Address
+		want       bool
+	}{
+		{name: "erc721 owner", collection: erc721Collection, wallet: wallet, want: true},
+		{name: "erc721 non-owner", collection: erc721Collection, wallet: stranger},
+		{name: "erc1155 holder", collection: erc1155Collection, wallet: wallet, want: true},
+		{name: "erc1155 non-holder", collection: erc1155Collection, wallet: stranger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.holdsToken(ctx, refIn(tt.collection), tt.wallet)
+			if err != nil {
+				t.Fatalf("holdsToken failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConsentAny(t *testing.T) {
+	c, _ := newSimChecker(t, newStandardCollections())
+
+	tests := []struct {
+		name       string
+		collection common.Address
+		tokenIDs   []string
+		wallet     common.Address
+		want       bool
+		wantErr    error
+	}{
+		{name: "holds one of several", collection: erc1155Collection, tokenIDs: []string{"1", "2", "3"}, wallet: wallet, want: true},
+		{name: "holds none", collection: erc1155Collection, tokenIDs: []string{"1", "2", "3"}, wallet: stranger},
+		{name: "defaults to the reference token", collection: erc1155Collection, wallet: wallet, want: true},
+		{name: "erc721", collection: erc721Collection, tokenIDs: []string{"1"}, wallet: wallet, wantErr: ErrUnsupportedStandard},
+		{name: "invalid token ID", collection: erc1155Collection, tokenIDs: []string{"1", "x"}, wallet: wallet, wantErr: biocid.ErrInvalidTokenID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CheckConsentAny(context.Background(), refIn(tt.collection), tt.tokenIDs, tt.wallet)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckConsentAny failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
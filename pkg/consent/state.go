@@ -0,0 +1,62 @@
+package consent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// consentStateNames maps each ConsentState to its lowercase name
+var consentStateNames = [...]string{
+	ConsentPending: "pending",
+	ConsentActive:  "active",
+	ConsentRevoked: "revoked",
+	ConsentDeleted: "deleted",
+}
+
+// String returns the lowercase name of the state, or "unknown" when out of range
+func (s ConsentState) String() string {
+	if !s.valid() {
+		return "unknown"
+	}
+	return consentStateNames[s]
+}
+
+// valid reports whether s is one of the defined consent states
+func (s ConsentState) valid() bool {
+	return s >= 0 && int(s) < len(consentStateNames)
+}
+
+// ParseConsentState parses a state name as produced by String
+func ParseConsentState(name string) (ConsentState, error) {
+	for i, n := range consentStateNames {
+		if strings.EqualFold(name, n) {
+			return ConsentState(i), nil
+		}
+	}
+	return ConsentPending, fmt.Errorf("invalid consent state: %q", name)
+}
+
+// MarshalJSON encodes the state as its lowercase name
+func (s ConsentState) MarshalJSON() ([]byte, error) {
+	if !s.valid() {
+		return nil, fmt.Errorf("invalid consent state: %d", int(s))
+	}
+	return json.Marshal(consentStateNames[s])
+}
+
+// UnmarshalJSON decodes a lowercase state name
+func (s *ConsentState) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("consent state must be a JSON string: %w", err)
+	}
+
+	state, err := ParseConsentState(name)
+	if err != nil {
+		return err
+	}
+
+	*s = state
+	return nil
+}
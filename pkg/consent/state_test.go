@@ -0,0 +1,60 @@
+package consent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConsentStateNames(t *testing.T) {
+	tests := []struct {
+		state ConsentState
+		name  string
+	}{
+		{ConsentPending, "pending"},
+		{ConsentActive, "active"},
+		{ConsentRevoked, "revoked"},
+		{ConsentDeleted, "deleted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.String(); got != tt.name {
+				t.Errorf("String() = %q, want %q", got, tt.name)
+			}
+
+			parsed, err := ParseConsentState(tt.name)
+			if err != nil || parsed != tt.state {
+				t.Errorf("ParseConsentState(%q) = %v, %v", tt.name, parsed, err)
+			}
+
+			data, err := json.Marshal(tt.state)
+			if err != nil || string(data) != `"`+tt.name+`"` {
+				t.Fatalf("MarshalJSON = %s, %v", data, err)
+			}
+
+			var decoded ConsentState
+			if err := json.Unmarshal(data, &decoded); err != nil || decoded != tt.state {
+				t.Errorf("UnmarshalJSON(%s) = %v, %v", data, decoded, err)
+			}
+		})
+	}
+}
+
+func TestConsentStateInvalid(t *testing.T) {
+	invalid := ConsentState(7)
+	if got := invalid.String(); got != "unknown" {
+		t.Errorf("String() = %q, want unknown", got)
+	}
+	if _, err := json.Marshal(invalid); err == nil {
+		t.Error("expected MarshalJSON to reject an out-of-range state")
+	}
+	if parsed, err := ParseConsentState("ACTIVE"); err != nil || parsed != ConsentActive {
+		t.Errorf("ParseConsentState is case-insensitive: got %v, %v", parsed, err)
+	}
+
+	for _, input := range []string{`"paused"`, `1`, `null`, `""`} {
+		var s ConsentState
+		if err := json.Unmarshal([]byte(input), &s); err == nil {
+			t.Errorf("UnmarshalJSON(%s) = %v, want error", input, s)
+		}
+	}
+}
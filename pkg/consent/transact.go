@@ -0,0 +1,221 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/datatype"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// mintConsent sends mintAndGrantConsent and returns the token ID from the ConsentGranted log
+func (c *ConsentChecker) mintConsent(
+	ctx context.Context,
+	chain biocid.Chain,
+	collection common.Address,
+	contentHash biocid.ContentHash,
+	opts ConsentOptions,
+	signer *bind.TransactOpts,
+) (*big.Int, error) {
+	bioCID, err := bioCIDBytes(opts.BioCID)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := c.collection(ctx, chain, collection.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	txOpts := *signer
+	if txOpts.Context == nil {
+		txOpts.Context = ctx
+	}
+
+	if err := c.applyFees(ctx, chain, &txOpts); err != nil {
+		return nil, err
+	}
+
+	tx, err := contract.Transact(&txOpts, "mintAndGrantConsent",
+		[32]byte(contentHash), opts.DataType, new(big.Int).SetUint64(opts.DataSize), bioCID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send mintAndGrantConsent: %w", err)
+	}
+
+	receipt, err := c.waitMined(ctx, chain, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := consentABI.Events["ConsentGranted"].ID
+	for _, log := range receipt.Logs {
+		if log.Address == collection && len(log.Topics) >= 2 && log.Topics[0] == topic {
+			return log.Topics[1].Big(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no ConsentGranted event in transaction %s", tx.Hash().Hex())
+}
+
+// transact sends a state-changing call to the collection holding nftRef without waiting for it to be mined
+func (c *ConsentChecker) transact(ctx context.Context, nftRef biocid.NFTReference, signer *bind.TransactOpts, method string, args ...interface{}) (*types.Transaction, error) {
+	contract, err := c.collection(ctx, nftRef.Chain, nftRef.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	txOpts := *signer
+	if txOpts.Context == nil {
+		txOpts.Context = ctx
+	}
+
+	if err := c.applyFees(ctx, nftRef.Chain, &txOpts); err != nil {
+		return nil, err
+	}
+
+	tx, err := contract.Transact(&txOpts, method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	c.logger.Info("sent transaction", "chain", nftRef.Chain, "method", method, "tx", tx.Hash().Hex())
+	return tx, nil
+}
+
+// waitMined blocks until tx is mined and fails if it reverted
+func (c *ConsentChecker) waitMined(ctx context.Context, chain biocid.Chain, tx *types.Transaction) (*types.Receipt, error) {
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for transaction %s: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+
+	return receipt, nil
+}
+
+// normalizeDataType maps dataType to its canonical form before it is stored on-chain
+func (c *ConsentChecker) normalizeDataType(dataType string) (string, error) {
+	return datatype.Normalizer{AllowCustom: c.customTypes}.Normalize(dataType)
+}
+
+// applyFees prices opts with the fee strategy configured for chain
+func (c *ConsentChecker) applyFees(ctx context.Context, chain biocid.Chain, opts *bind.TransactOpts) error {
+	strategy, ok := c.chainFees[chain]
+	if !ok {
+		strategy = c.fees
+	}
+
+	client, err := c.getClient(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", chain, err)
+	}
+
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	if err := strategy.Apply(ctx, client, opts); err != nil {
+		return fmt.Errorf("failed to set fees on %s: %w", chain, err)
+	}
+	return nil
+}
+
+// findConsentByContent returns a token owned by owner with the given content hash
+// whose consent is still pending or active, or nil if there is none
+func (c *ConsentChecker) findConsentByContent(
+	ctx context.Context,
+	chain biocid.Chain,
+	collection common.Address,
+	owner common.Address,
+	contentHash biocid.ContentHash,
+) (*big.Int, error) {
+	contract, err := c.collection(ctx, chain, collection.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.call(ctx, contract, "getOwnerTokens", owner)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenIDs := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+
+	// Fetch every token's metadata in one round trip rather than one eth_call per token
+	calldata := make([][]byte, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		if calldata[i], err = consentABI.Pack("getConsentMetadata", tokenID); err != nil {
+			return nil, fmt.Errorf("failed to pack getConsentMetadata: %w", err)
+		}
+	}
+
+	raw, errs, err := c.batchCall(ctx, chain, collection, "getConsentMetadata", calldata)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tokenID := range tokenIDs {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to call getConsentMetadata for token %s: %w", tokenID, errs[i])
+		}
+
+		out, err := consentABI.Unpack("getConsentMetadata", raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode getConsentMetadata: %w", err)
+		}
+
+		meta := *abi.ConvertType(out[0], new(consentMetadata)).(*consentMetadata)
+		if biocid.ContentHash(meta.ContentHash) != contentHash {
+			continue
+		}
+
+		switch ConsentState(meta.State) {
+		case ConsentPending, ConsentActive:
+			return tokenID, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// bioCIDBytes converts a BioCID option to the bytes32 stored on-chain
+// Accepts a biocid:// string (hashed with BioCID.Digest), 0x-prefixed 32-byte hex, or empty
+func bioCIDBytes(s string) ([32]byte, error) {
+	var out [32]byte
+
+	switch {
+	case s == "":
+		return out, nil
+	case strings.HasPrefix(s, "biocid://"):
+		b, err := biocid.ParseBioCID(s)
+		if err != nil {
+			return out, err
+		}
+		return b.Digest(), nil
+	}
+
+	raw, err := hexutil.Decode(s)
+	if err != nil || len(raw) != 32 {
+		return out, fmt.Errorf("%w: bioCID must be a biocid:// string or 32-byte hex", biocid.ErrInvalidBioCID)
+	}
+
+	copy(out[:], raw)
+	return out, nil
+}
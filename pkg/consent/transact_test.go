@@ -0,0 +1,156 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/datatype"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+)
+
+func TestCreateConsentIdempotent(t *testing.T) {
+	reg := newSimConsent()
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+	owner := signer.From
+
+	landed := biocid.ContentHash{0xAA}
+	revoked := activeMetadata(5)
+	revoked.Owner, revoked.ContentHash, revoked.State = owner, landed, uint8(ConsentRevoked)
+	other := activeMetadata(6)
+	other.Owner, other.ContentHash = owner, biocid.ContentHash{0xBB}
+	minted := activeMetadata(7)
+	minted.Owner, minted.ContentHash = owner, landed
+
+	// Token 5 holds the same content but is revoked, so only the mint below makes it live
+	reg.On("getOwnerTokens", owner).Returns([]*big.Int{big.NewInt(5), big.NewInt(6)})
+	reg.On("getConsentMetadata", big.NewInt(5)).Returns(revoked)
+	reg.On("getConsentMetadata", big.NewInt(6)).Returns(other)
+	reg.On("mintAndGrantConsent").
+		Emits("ConsentGranted", big.NewInt(7), owner, [32]byte(landed), "vcf", [32]byte{}).
+		Then(
+			reg.Next("getOwnerTokens", owner).Returns([]*big.Int{big.NewInt(5), big.NewInt(6), big.NewInt(7)}),
+			reg.Next("getConsentMetadata", big.NewInt(7)).Returns(minted),
+		)
+	chain.Apply(t, reg)
+
+	create := func(opts ConsentOptions) (string, bool) {
+		t.Helper()
+		ctx := context.Background()
+		before, err := chain.Backend.PendingNonceAt(ctx, owner)
+		if err != nil {
+			t.Fatalf("PendingNonceAt failed: %v", err)
+		}
+
+		tokenID, err := c.CreateConsent(ctx, simChain, simCollection, opts, signer)
+		if err != nil {
+			t.Fatalf("CreateConsent failed: %v", err)
+		}
+
+		after, err := chain.Backend.PendingNonceAt(ctx, owner)
+		if err != nil {
+			t.Fatalf("PendingNonceAt failed: %v", err)
+		}
+		return tokenID, after > before
+	}
+
+	tests := []struct {
+		name     string
+		opts     ConsentOptions
+		want     string
+		wantSent bool
+	}{
+		{name: "first attempt mints", opts: ConsentOptions{ContentHash: landed, DataType: "vcf"}, want: "7", wantSent: true},
+		{name: "retry returns the landed token", opts: ConsentOptions{ContentHash: landed, DataType: "vcf"}, want: "7"},
+		{name: "live token for other content", opts: ConsentOptions{ContentHash: biocid.ContentHash{0xBB}, DataType: "vcf"}, want: "6"},
+		{name: "duplicate allowed", opts: ConsentOptions{ContentHash: landed, DataType: "vcf", AllowDuplicate: true}, want: "7", wantSent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, sent := create(tt.opts)
+			if got != tt.want {
+				t.Errorf("token = %s, want %s", got, tt.want)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("transaction sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestCreateConsentLookupFails(t *testing.T) {
+	reg := newSimConsent()
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+
+	reg.On("getOwnerTokens", signer.From).Returns([]*big.Int{big.NewInt(5)})
+	reg.On("getConsentMetadata", big.NewInt(5)).Reverts()
+	chain.Apply(t, reg)
+
+	ctx := context.Background()
+	before, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+
+	// A failed lookup must not fall through to minting a possible duplicate
+	opts := ConsentOptions{ContentHash: biocid.ContentHash{0xAA}, DataType: "vcf"}
+	if _, err := c.CreateConsent(ctx, simChain, simCollection, opts, signer); err == nil {
+		t.Fatal("expected the lookup failure to be returned")
+	}
+
+	after, err := chain.Backend.PendingNonceAt(ctx, signer.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt failed: %v", err)
+	}
+	if after != before {
+		t.Errorf("nonce moved from %d to %d, want no transaction", before, after)
+	}
+}
+
+func TestCreateConsentNormalizesDataType(t *testing.T) {
+	reg := newSimConsent()
+	c, chain := newSimChecker(t, []*simchain.Contract{reg})
+	signer := chain.Auth(t, 0)
+	owner := signer.From
+
+	minted := activeMetadata(7)
+	minted.Owner, minted.ContentHash = owner, biocid.ContentHash{0xAA}
+
+	// Only a mint of the canonical type is scripted; anything else reverts
+	reg.On("getOwnerTokens", owner).Returns([]*big.Int{})
+	reg.On("mintAndGrantConsent", [32]byte{0xAA}, "vcf", big.NewInt(0), [32]byte{}).
+		Emits("ConsentGranted", big.NewInt(7), owner, [32]byte{0xAA}, "vcf", [32]byte{}).
+		Then(reg.Next("getConsentMetadata", big.NewInt(7)).Returns(minted))
+	chain.Apply(t, reg)
+
+	tests := []struct {
+		name     string
+		dataType string
+		wantErr  error
+	}{
+		{name: "alias", dataType: " VCF "},
+		{name: "unknown", dataType: "hl7", wantErr: datatype.ErrUnknownDataType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ConsentOptions{ContentHash: biocid.ContentHash{0xAA}, DataType: tt.dataType, AllowDuplicate: true}
+			tokenID, err := c.CreateConsent(context.Background(), simChain, simCollection, opts, signer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateConsent failed: %v", err)
+			}
+			if tokenID != "7" {
+				t.Errorf("got token %s, want 7", tokenID)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultPollInterval is how often logs are polled when subscriptions are unavailable
+const defaultPollInterval = 15 * time.Second
+
+// WatchConsentEvents listens for consent revocation events
+// The callback runs for each ConsentRevoked/ContentDeleted event until ctx is cancelled or
+// the subscription is unsubscribed; failures are delivered on the subscription's Err channel
+// Subscriptions use the chain's WithSubscriptionURL endpoint, or its RPC URL when that is a
+// websocket; HTTP-only chains and endpoints without log subscriptions are polled instead
+func (c *ConsentChecker) WatchConsentEvents(ctx context.Context, nftRef biocid.NFTReference, callback func(ConsentState)) (ethereum.Subscription, error) {
+	if !common.IsHexAddress(nftRef.Collection) {
+		return nil, fmt.Errorf("%w: %s", biocid.ErrInvalidAddress, nftRef.Collection)
+	}
+
+	tokenID, err := nftRef.TokenIDBig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.getClient(ctx, nftRef.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", nftRef.Chain, err)
+	}
+
+	// Revocations purge cached CheckConsent results before the caller is notified
+	notify := callback
+	callback = func(state ConsentState) {
+		c.invalidate(nftRef)
+		notify(state)
+	}
+
+	query := consentEventQuery(common.HexToAddress(nftRef.Collection), tokenID)
+
+	subClient, err := c.getSubscriptionClient(ctx, nftRef.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if subClient == nil {
+		return c.pollConsentEvents(ctx, client, query, callback)
+	}
+
+	logs := make(chan types.Log)
+	sub, err := subClient.SubscribeFilterLogs(ctx, query, logs)
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return c.pollConsentEvents(ctx, client, query, callback)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to consent events: %w", err)
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case log := <-logs:
+				if state, ok := decodeConsentEvent(log); ok {
+					callback(state)
+				}
+			case err := <-sub.Err():
+				return err
+			case <-ctx.Done():
+				return nil
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// SubscribeConsent delivers consent state changes for nftRef on a channel
+// Both channels are closed when ctx is cancelled or the underlying watch fails; a failure,
+// including one setting up the watch, is sent on the error channel first
+// Delivery blocks: no event is dropped, and a consumer that stops reading holds up the watch
+// until it resumes or ctx is cancelled
+func (c *ConsentChecker) SubscribeConsent(ctx context.Context, nftRef biocid.NFTReference) (<-chan ConsentState, <-chan error) {
+	states := make(chan ConsentState)
+	errs := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub, err := c.WatchConsentEvents(ctx, nftRef, func(state ConsentState) {
+		select {
+		case states <- state:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		cancel()
+		errs <- err
+		close(states)
+		close(errs)
+		return states, errs
+	}
+
+	go func() {
+		defer cancel()
+		defer close(errs)
+		defer close(states)
+
+		select {
+		case err := <-sub.Err():
+			if err != nil {
+				errs <- err
+			}
+		case <-ctx.Done():
+		}
+
+		// Unsubscribe waits for the watch to stop, so nothing sends on states after it returns
+		sub.Unsubscribe()
+	}()
+
+	return states, errs
+}
+
+// getSubscriptionClient returns a backend able to subscribe to logs on chain
+// It returns nil when the chain only has an HTTP endpoint
+func (c *ConsentChecker) getSubscriptionClient(ctx context.Context, chain biocid.Chain) (chainrpc.Backend, error) {
+	c.mu.Lock()
+	backend, injected := c.backends[chain]
+	client, cached := c.subClients[chain]
+	wsURL := c.subURLs[chain]
+	limiter := c.limiters[chain]
+	failover, failsOver := c.failovers[chain]
+	c.mu.Unlock()
+
+	switch {
+	case injected:
+		return chainrpc.RateLimit(backend, limiter), nil
+	case cached:
+		return chainrpc.RateLimit(client, limiter), nil
+	case wsURL == "" && failsOver:
+		if isWebSocketURL(failover.URL()) {
+			return c.getClient(ctx, chain)
+		}
+		return nil, nil
+	case wsURL == "":
+		info, ok := c.chains.Lookup(chain.String())
+		if ok && isWebSocketURL(info.RPCURL) {
+			return c.getClient(ctx, chain)
+		}
+		return nil, nil
+	}
+
+	client, err := ethclient.DialContext(ctx, wsURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", chainrpc.ErrRPCConnect, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.subClients[chain]; ok {
+		client.Close()
+		return chainrpc.RateLimit(existing, limiter), nil
+	}
+	c.subClients[chain] = client
+	return chainrpc.RateLimit(client, limiter), nil
+}
+
+// isWebSocketURL reports whether url uses the ws or wss scheme
+func isWebSocketURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// pollConsentEvents emulates a log subscription by periodically calling FilterLogs
+func (c *ConsentChecker) pollConsentEvents(ctx context.Context, client chainrpc.Backend, query ethereum.FilterQuery, callback func(ConsentState)) (ethereum.Subscription, error) {
+	head, err := c.blockNumber(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	from := head + 1
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(c.polling)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			case <-quit:
+				return nil
+			}
+
+			head, err := c.blockNumber(ctx, client)
+			if err != nil {
+				return err
+			}
+			if head < from {
+				continue
+			}
+
+			q := query
+			q.FromBlock = new(big.Int).SetUint64(from)
+			q.ToBlock = new(big.Int).SetUint64(head)
+
+			filter := func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+				return c.filterLogs(ctx, client, q)
+			}
+			err = chainrpc.ScanLogs(ctx, filter, q, chainrpc.DefaultLogChunk, func(logs []types.Log) error {
+				for _, log := range logs {
+					if state, ok := decodeConsentEvent(log); ok {
+						callback(state)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			from = head + 1
+		}
+	}), nil
+}
+
+// consentEventQuery filters ConsentRevoked and ContentDeleted logs for one token
+func consentEventQuery(collection common.Address, tokenID *big.Int) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{collection},
+		Topics: [][]common.Hash{
+			{consentABI.Events["ConsentRevoked"].ID, consentABI.Events["ContentDeleted"].ID},
+			{common.BigToHash(tokenID)},
+		},
+	}
+}
+
+// decodeConsentEvent maps a consent event log to the state it transitions to
+func decodeConsentEvent(log types.Log) (ConsentState, bool) {
+	if log.Removed || len(log.Topics) == 0 {
+		return ConsentPending, false
+	}
+
+	switch log.Topics[0] {
+	case consentABI.Events["ConsentRevoked"].ID:
+		return ConsentRevoked, true
+	case consentABI.Events["ContentDeleted"].ID:
+		return ConsentDeleted, true
+	}
+
+	return ConsentPending, false
+}
+
+// blockNumber returns the current head block within the configured timeout
+func (c *ConsentChecker) blockNumber(ctx context.Context, client chainrpc.Backend) (uint64, error) {
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	head, err := chainrpc.BlockNumber(ctx, client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return head, nil
+}
+
+// filterLogs runs a log query within the configured timeout
+func (c *ConsentChecker) filterLogs(ctx context.Context, client chainrpc.Backend, q ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := chainrpc.EnsureDeadline(ctx, c.timeout)
+	defer cancel()
+
+	logs, err := client.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter consent events: %w", err)
+	}
+	return logs, nil
+}
@@ -0,0 +1,237 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newWatchedConsent scripts revocation and deletion events for tokens 1 and 2
+func newWatchedConsent() *simchain.Contract {
+	reg := newSimConsent()
+	for _, id := range []int64{1, 2} {
+		reg.On("revokeConsent", big.NewInt(id)).Emits("ConsentRevoked", big.NewInt(id), wallet, big.NewInt(1700000000))
+		reg.On("burnAndDelete", big.NewInt(id), [32]byte{0xaa}, big.NewInt(4)).Emits("ContentDeleted", big.NewInt(id), [32]byte{0xaa}, big.NewInt(4))
+	}
+	return reg
+}
+
+// receive waits for the next state delivered on states
+func receive(t *testing.T, states <-chan ConsentState) (ConsentState, bool) {
+	t.Helper()
+
+	select {
+	case state := <-states:
+		return state, true
+	case <-time.After(5 * time.Second):
+		return ConsentPending, false
+	}
+}
+
+func TestWatchConsentEvents(t *testing.T) {
+	modes := []struct {
+		name  string
+		watch func(ctx context.Context, c *ConsentChecker, chain *simchain.Chain, callback func(ConsentState)) error
+	}{
+		{
+			name: "subscription",
+			watch: func(ctx context.Context, c *ConsentChecker, chain *simchain.Chain, callback func(ConsentState)) error {
+				_, err := c.WatchConsentEvents(ctx, simRef("1"), callback)
+				return err
+			},
+		},
+		{
+			name: "polling",
+			watch: func(ctx context.Context, c *ConsentChecker, chain *simchain.Chain, callback func(ConsentState)) error {
+				query := consentEventQuery(simCollection, big.NewInt(1))
+				_, err := c.pollConsentEvents(ctx, chain.Backend, query, callback)
+				return err
+			},
+		},
+	}
+
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			c, chain := newSimChecker(t, []*simchain.Contract{newWatchedConsent()}, WithPollInterval(10*time.Millisecond))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			states := make(chan ConsentState, 4)
+			if err := mode.watch(ctx, c, chain, func(state ConsentState) { states <- state }); err != nil {
+				t.Fatalf("watch failed: %v", err)
+			}
+
+			// Events for other tokens are filtered out
+			transactSim(t, chain, "revokeConsent", big.NewInt(2))
+			transactSim(t, chain, "revokeConsent", big.NewInt(1))
+			transactSim(t, chain, "burnAndDelete", big.NewInt(1), [32]byte{0xaa}, big.NewInt(4))
+
+			for _, want := range []ConsentState{ConsentRevoked, ConsentDeleted} {
+				got, ok := receive(t, states)
+				if !ok {
+					t.Fatalf("timed out waiting for %s", want)
+				}
+				if got != want {
+					t.Errorf("got %s, want %s", got, want)
+				}
+			}
+
+			select {
+			case state := <-states:
+				t.Errorf("unexpected extra event %s", state)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestDecodeConsentEvent(t *testing.T) {
+	revoked := consentABI.Events["ConsentRevoked"].ID
+	deleted := consentABI.Events["ContentDeleted"].ID
+	granted := consentABI.Events["ConsentGranted"].ID
+
+	tests := []struct {
+		name   string
+		log    types.Log
+		want   ConsentState
+		wantOK bool
+	}{
+		{name: "revoked", log: types.Log{Topics: []common.Hash{revoked}}, want: ConsentRevoked, wantOK: true},
+		{name: "deleted", log: types.Log{Topics: []common.Hash{deleted}}, want: ConsentDeleted, wantOK: true},
+		{name: "other event", log: types.Log{Topics: []common.Hash{granted}}},
+		{name: "no topics", log: types.Log{}},
+		{name: "reorged out", log: types.Log{Topics: []common.Hash{revoked}, Removed: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := decodeConsentEvent(tt.log)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("got %s, %v; want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// newWebSocketServer serves an empty JSON-RPC server over WebSocket and returns its ws:// URL
+func newWebSocketServer(t *testing.T) string {
+	t.Helper()
+
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(httpServer.Close)
+	return "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+func TestSubscriptionClientSelection(t *testing.T) {
+	wsURL := newWebSocketServer(t)
+
+	tests := []struct {
+		name       string
+		rpcURL     string
+		subURL     string
+		wantClient bool
+		wantErr    error
+	}{
+		{name: "http only polls", rpcURL: "https://rpc.example"},
+		{name: "websocket rpc subscribes", rpcURL: wsURL, wantClient: true},
+		{name: "subscription URL preferred", rpcURL: "https://rpc.example", subURL: wsURL, wantClient: true},
+		{name: "subscription URL unreachable", rpcURL: "https://rpc.example", subURL: "ws://127.0.0.1:1", wantErr: chainrpc.ErrRPCConnect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chains := biocid.NewChainRegistry()
+			chains.Register("local", tt.rpcURL, big.NewInt(1))
+			opts := []Option{WithChainRegistry(chains)}
+			if tt.subURL != "" {
+				opts = append(opts, WithSubscriptionURL("local", tt.subURL))
+			}
+			c := NewConsentChecker(opts...)
+			defer c.Close()
+
+			client, err := c.getSubscriptionClient(context.Background(), "local")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getSubscriptionClient failed: %v", err)
+			}
+			if (client != nil) != tt.wantClient {
+				t.Errorf("got client %v, want one: %v", client, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestSubscribeConsent(t *testing.T) {
+	c, chain := newSimChecker(t, []*simchain.Contract{newWatchedConsent()})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	states, errs := c.SubscribeConsent(ctx, simRef("1"))
+
+	// Nobody reads until all events are mined; blocking delivery keeps them all, in order
+	transactSim(t, chain, "revokeConsent", big.NewInt(2))
+	transactSim(t, chain, "revokeConsent", big.NewInt(1))
+	transactSim(t, chain, "burnAndDelete", big.NewInt(1), [32]byte{0xaa}, big.NewInt(4))
+	time.Sleep(50 * time.Millisecond)
+
+	for _, want := range []ConsentState{ConsentRevoked, ConsentDeleted} {
+		got, ok := receive(t, states)
+		if !ok {
+			t.Fatalf("timed out waiting for %s", want)
+		}
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	cancel()
+	select {
+	case state, ok := <-states:
+		if ok {
+			t.Errorf("got %s after cancel, want the channel closed", state)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("states channel still open after cancel")
+	}
+	select {
+	case err, ok := <-errs:
+		if ok {
+			t.Errorf("got error %v after cancel, want the channel closed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error channel still open after cancel")
+	}
+}
+
+func TestSubscribeConsentSetupFails(t *testing.T) {
+	c, _ := newSimChecker(t, nil)
+	ref := simRef("1")
+	ref.Collection = "not-an-address"
+
+	states, errs := c.SubscribeConsent(context.Background(), ref)
+	if err := <-errs; !errors.Is(err, biocid.ErrInvalidAddress) {
+		t.Errorf("got %v, want %v", err, biocid.ErrInvalidAddress)
+	}
+	if _, ok := <-errs; ok {
+		t.Error("error channel left open")
+	}
+	if _, ok := <-states; ok {
+		t.Error("states channel left open")
+	}
+}
@@ -0,0 +1,148 @@
+// Package datatype defines the canonical data type names stored with BioIP assets and consents
+package datatype
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Canonical data types
+const (
+	VCF    = "vcf"
+	BAM    = "bam"
+	CRAM   = "cram"
+	SAM    = "sam"
+	FASTQ  = "fastq"
+	FASTA  = "fasta"
+	BED    = "bed"
+	GFF    = "gff"
+	CSV    = "csv"
+	TSV    = "tsv"
+	SQLite = "sqlite"
+	PDF    = "pdf"
+	JSON   = "json"
+)
+
+// ErrUnknownDataType is returned for data types with no canonical form when custom types are not allowed
+var ErrUnknownDataType = errors.New("unknown data type")
+
+// aliases maps lowercase names, file extensions, and MIME types to canonical data types
+var aliases = map[string]string{
+	"vcf":        VCF,
+	"vcf.gz":     VCF,
+	"text/vcf":   VCF,
+	"text/x-vcf": VCF,
+	"text/vcard": VCF, // Common mislabelling of VCF files
+
+	"bam":               BAM,
+	"application/x-bam": BAM,
+
+	"cram":               CRAM,
+	"application/x-cram": CRAM,
+
+	"sam":        SAM,
+	"text/x-sam": SAM,
+
+	"fastq":        FASTQ,
+	"fq":           FASTQ,
+	"fastq.gz":     FASTQ,
+	"fq.gz":        FASTQ,
+	"text/x-fastq": FASTQ,
+
+	"fasta":        FASTA,
+	"fa":           FASTA,
+	"fna":          FASTA,
+	"text/x-fasta": FASTA,
+
+	"bed":        BED,
+	"text/x-bed": BED,
+
+	"gff":         GFF,
+	"gff3":        GFF,
+	"text/x-gff3": GFF,
+
+	"csv":      CSV,
+	"text/csv": CSV,
+
+	"tsv":                       TSV,
+	"text/tab-separated-values": TSV,
+
+	"sqlite":                  SQLite,
+	"sqlite3":                 SQLite,
+	"application/vnd.sqlite3": SQLite,
+
+	"pdf":             PDF,
+	"application/pdf": PDF,
+
+	"json":             JSON,
+	"application/json": JSON,
+}
+
+// mimeTypes maps canonical data types to the MIME type served for them
+var mimeTypes = map[string]string{
+	VCF:    "text/x-vcf",
+	BAM:    "application/x-bam",
+	CRAM:   "application/x-cram",
+	SAM:    "text/x-sam",
+	FASTQ:  "text/x-fastq",
+	FASTA:  "text/x-fasta",
+	BED:    "text/x-bed",
+	GFF:    "text/x-gff3",
+	CSV:    "text/csv",
+	TSV:    "text/tab-separated-values",
+	SQLite: "application/vnd.sqlite3",
+	PDF:    "application/pdf",
+	JSON:   "application/json",
+}
+
+// Normalizer maps data type aliases to canonical forms
+type Normalizer struct {
+	AllowCustom bool // Accept unknown types, lowercased and trimmed, instead of failing
+}
+
+// NormalizeDataType maps s to its canonical data type, rejecting unknown types
+func NormalizeDataType(s string) (string, error) {
+	return Normalizer{}.Normalize(s)
+}
+
+// Normalize maps s to its canonical data type
+// Matching ignores case and surrounding whitespace, and accepts MIME types and file extensions
+func (n Normalizer) Normalize(s string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if key == "" {
+		return "", fmt.Errorf("data type is required")
+	}
+
+	if canonical, ok := aliases[key]; ok {
+		return canonical, nil
+	}
+
+	if !n.AllowCustom {
+		return "", fmt.Errorf("%w: %q", ErrUnknownDataType, s)
+	}
+	return key, nil
+}
+
+// MIMEType returns the MIME type for a canonical data type, or application/octet-stream
+func MIMEType(dataType string) string {
+	if mime, ok := mimeTypes[dataType]; ok {
+		return mime
+	}
+	return "application/octet-stream"
+}
+
+// FromFilename infers the canonical data type from a file name's extension
+// Compressed extensions such as .vcf.gz are recognized
+func FromFilename(name string) (string, bool) {
+	base := strings.ToLower(path.Base(name))
+
+	ext := strings.TrimPrefix(path.Ext(base), ".")
+	if ext == "gz" {
+		ext = strings.TrimPrefix(path.Ext(strings.TrimSuffix(base, ".gz")), ".") + ".gz"
+	}
+
+	canonical, ok := aliases[ext]
+	return canonical, ok
+}
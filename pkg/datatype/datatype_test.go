@@ -0,0 +1,89 @@
+package datatype
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		custom  bool
+		want    string
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "canonical", input: "vcf", want: VCF},
+		{name: "upper case", input: "VCF", want: VCF},
+		{name: "surrounding whitespace", input: "  Bam\n", want: BAM},
+		{name: "compressed extension", input: "fq.gz", want: FASTQ},
+		{name: "MIME type", input: "application/x-cram", want: CRAM},
+		{name: "mislabelled vcard", input: "text/vcard", want: VCF},
+		{name: "short alias", input: "fa", want: FASTA},
+		{name: "unknown", input: "hl7", wantErr: true, wantIs: ErrUnknownDataType},
+		{name: "unknown allowed", input: " HL7 ", custom: true, want: "hl7"},
+		{name: "alias wins over custom", input: "GFF3", custom: true, want: GFF},
+		{name: "empty", input: " ", wantErr: true},
+		{name: "empty with custom", input: "", custom: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalizer{AllowCustom: tt.custom}.Normalize(tt.input)
+			if tt.wantErr {
+				if err == nil || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Fatalf("got %q, %v; want error %v", got, err, tt.wantIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NormalizeDataType("hl7"); !errors.Is(err, ErrUnknownDataType) {
+		t.Errorf("NormalizeDataType accepted a custom type: %v", err)
+	}
+}
+
+func TestEveryTypeHasMIME(t *testing.T) {
+	for alias, canonical := range aliases {
+		if MIMEType(canonical) == "application/octet-stream" {
+			t.Errorf("%s (alias %s) has no MIME type", canonical, alias)
+		}
+		if got, err := NormalizeDataType(MIMEType(canonical)); err != nil || got != canonical {
+			t.Errorf("MIME type of %s normalizes to %q, %v", canonical, got, err)
+		}
+	}
+	if got := MIMEType("hl7"); got != "application/octet-stream" {
+		t.Errorf("custom type served as %s", got)
+	}
+}
+
+func TestFromFilename(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "sample.vcf", want: VCF, wantOK: true},
+		{name: "runs/2024/Sample.VCF.GZ", want: VCF, wantOK: true},
+		{name: "reads_R1.fq.gz", want: FASTQ, wantOK: true},
+		{name: "aligned.cram", want: CRAM, wantOK: true},
+		{name: "archive.tar.gz"},
+		{name: "notes.gz"},
+		{name: "README"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromFilename(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
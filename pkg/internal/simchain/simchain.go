@@ -0,0 +1,356 @@
+// Package simchain runs scripted contracts on a simulated chain for tests
+//
+// A scripted contract answers each call with a response configured in Go, keyed by the
+// call's exact calldata or, failing that, by its method selector; unconfigured calls revert.
+// Responses can emit events and replace other responses once the call is mined, which is
+// enough to model the state transitions the BioFS registries go through (mint, revoke, ...)
+// without compiling Solidity
+package simchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ChainID is the chain ID of every simulated chain
+var ChainID = big.NewInt(1337)
+
+// gasLimit is the block gas limit of simulated chains
+const gasLimit = 30_000_000
+
+// adminSelector prefixes calldata that stores raw (slot, value) pairs
+var adminSelector = []byte{0xff, 0xff, 0xff, 0xff}
+
+//go:embed scripted.easm
+var scriptedSource string
+
+// scriptedCode is the runtime bytecode shared by every scripted contract
+var scriptedCode = mustAssemble(scriptedSource)
+
+// mustAssemble compiles EVM assembly
+func mustAssemble(src string) []byte {
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex([]byte(src), false))
+	out, errs := compiler.Compile()
+	if len(errs) > 0 {
+		panic(fmt.Sprintf("simchain: invalid scripted contract: %v", errs))
+	}
+	return common.FromHex(out)
+}
+
+// Contract is a scripted contract at a fixed address
+type Contract struct {
+	Address common.Address
+	ABI     abi.ABI
+	calls   []*Call
+}
+
+// NewContract creates a scripted contract speaking contractABI
+func NewContract(addr common.Address, contractABI abi.ABI) *Contract {
+	return &Contract{Address: addr, ABI: contractABI}
+}
+
+// NewContractJSON creates a scripted contract from a JSON ABI
+func NewContractJSON(addr common.Address, abiJSON string) *Contract {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("simchain: invalid ABI: %v", err))
+	}
+	return NewContract(addr, parsed)
+}
+
+// Call is the scripted response to one call
+type Call struct {
+	contract *Contract
+	method   abi.Method
+	key      common.Hash
+	ret      []byte
+	revert   bool
+	logs     []scriptedLog
+	then     []*Call
+}
+
+// scriptedLog is an event emitted by a call
+type scriptedLog struct {
+	topics []common.Hash
+	data   []byte
+}
+
+// On configures the response to method called with args
+// Without args the response applies to every call of method not configured more specifically
+func (c *Contract) On(method string, args ...interface{}) *Call {
+	call := c.Next(method, args...)
+	c.calls = append(c.calls, call)
+	return call
+}
+
+// Next describes a response to install later with Then, leaving the current one in place
+func (c *Contract) Next(method string, args ...interface{}) *Call {
+	m, ok := c.ABI.Methods[method]
+	if !ok {
+		panic(fmt.Sprintf("simchain: unknown method %s", method))
+	}
+
+	calldata := append([]byte(nil), m.ID...)
+	if len(args) > 0 {
+		packed, err := m.Inputs.Pack(args...)
+		if err != nil {
+			panic(fmt.Sprintf("simchain: failed to pack %s arguments: %v", method, err))
+		}
+		calldata = append(calldata, packed...)
+	}
+
+	return &Call{contract: c, method: m, key: crypto.Keccak256Hash(calldata)}
+}
+
+// Returns sets the ABI-encoded return values
+func (k *Call) Returns(values ...interface{}) *Call {
+	out, err := k.method.Outputs.Pack(values...)
+	if err != nil {
+		panic(fmt.Sprintf("simchain: failed to pack %s results: %v", k.method.Name, err))
+	}
+	k.ret = out
+	return k
+}
+
+// ReturnsRaw sets the raw return data
+func (k *Call) ReturnsRaw(data []byte) *Call {
+	k.ret = data
+	return k
+}
+
+// Reverts makes the call revert without data
+func (k *Call) Reverts() *Call {
+	k.revert = true
+	return k
+}
+
+// Emits makes the call emit event with args given in declaration order
+func (k *Call) Emits(event string, args ...interface{}) *Call {
+	e, ok := k.contract.ABI.Events[event]
+	if !ok {
+		panic(fmt.Sprintf("simchain: unknown event %s", event))
+	}
+	if len(args) != len(e.Inputs) {
+		panic(fmt.Sprintf("simchain: event %s takes %d arguments, got %d", event, len(e.Inputs), len(args)))
+	}
+
+	log := scriptedLog{topics: []common.Hash{e.ID}}
+	var data []interface{}
+	for i, input := range e.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			continue
+		}
+		topics, err := abi.MakeTopics([]interface{}{args[i]})
+		if err != nil {
+			panic(fmt.Sprintf("simchain: failed to encode %s topic: %v", event, err))
+		}
+		log.topics = append(log.topics, topics[0][0])
+	}
+
+	packed, err := e.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		panic(fmt.Sprintf("simchain: failed to pack %s data: %v", event, err))
+	}
+	log.data = packed
+
+	k.logs = append(k.logs, log)
+	return k
+}
+
+// Then installs next (see Contract.Next) once this call is mined
+func (k *Call) Then(next ...*Call) *Call {
+	k.then = append(k.then, next...)
+	return k
+}
+
+// slots returns the storage holding the call's record
+func (k *Call) slots() map[common.Hash]common.Hash {
+	base := k.key.Big()
+	out := make(map[common.Hash]common.Hash)
+	next := 0
+	put := func(value common.Hash) {
+		slot := new(big.Int).Add(base, big.NewInt(int64(next)))
+		out[common.BigToHash(slot)] = value
+		next++
+	}
+	putData := func(data []byte) {
+		for i := 0; i < len(data); i += 32 {
+			var word common.Hash
+			copy(word[:], data[i:])
+			put(word)
+		}
+	}
+
+	var writes []common.Hash
+	for _, then := range k.then {
+		for slot, value := range then.slots() {
+			writes = append(writes, slot, value)
+		}
+	}
+
+	header := new(big.Int).SetUint64(uint64(len(k.ret)))
+	if k.revert {
+		header.SetBit(header, 32, 1)
+	}
+	header.Or(header, new(big.Int).Lsh(big.NewInt(int64(len(writes)/2)), 40))
+	header.Or(header, new(big.Int).Lsh(big.NewInt(int64(len(k.logs))), 56))
+	header.SetBit(header, 255, 1)
+
+	put(common.BigToHash(header))
+	putData(k.ret)
+	for _, log := range k.logs {
+		put(common.BigToHash(new(big.Int).Or(
+			big.NewInt(int64(len(log.data))),
+			new(big.Int).Lsh(big.NewInt(int64(len(log.topics))), 32),
+		)))
+		for _, topic := range log.topics {
+			put(topic)
+		}
+		putData(log.data)
+	}
+	for _, word := range writes {
+		put(word)
+	}
+
+	return out
+}
+
+// storage returns the storage holding every configured response
+func (c *Contract) storage() map[common.Hash]common.Hash {
+	out := make(map[common.Hash]common.Hash)
+	for _, call := range c.calls {
+		for slot, value := range call.slots() {
+			out[slot] = value
+		}
+	}
+	return out
+}
+
+// Chain is a simulated chain with funded accounts
+type Chain struct {
+	Backend *backends.SimulatedBackend
+	Keys    []*ecdsa.PrivateKey
+}
+
+// New starts a simulated chain with three funded accounts and the given contracts deployed
+// The chain is closed when the test ends
+func New(t testing.TB, contracts ...*Contract) *Chain {
+	t.Helper()
+
+	chain := &Chain{}
+	alloc := core.GenesisAlloc{}
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		chain.Keys = append(chain.Keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: balance}
+	}
+	for _, c := range contracts {
+		alloc[c.Address] = core.GenesisAccount{Code: scriptedCode, Storage: c.storage(), Balance: new(big.Int)}
+	}
+
+	chain.Backend = backends.NewSimulatedBackend(alloc, gasLimit)
+	t.Cleanup(func() { chain.Backend.Close() })
+	return chain
+}
+
+// Address returns the address of account i
+func (c *Chain) Address(i int) common.Address {
+	return crypto.PubkeyToAddress(c.Keys[i].PublicKey)
+}
+
+// Auth returns a transactor signing as account i
+func (c *Chain) Auth(t testing.TB, i int) *bind.TransactOpts {
+	t.Helper()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(c.Keys[i], ChainID)
+	if err != nil {
+		t.Fatalf("failed to create transactor: %v", err)
+	}
+	return auth
+}
+
+// Apply writes contract's configured responses to the chain and mines them
+// Responses configured after New only take effect once applied
+func (c *Chain) Apply(t testing.TB, contract *Contract) {
+	t.Helper()
+
+	calldata := append([]byte(nil), adminSelector...)
+	for slot, value := range contract.storage() {
+		calldata = append(calldata, slot[:]...)
+		calldata = append(calldata, value[:]...)
+	}
+
+	bound := bind.NewBoundContract(contract.Address, contract.ABI, c.Backend, c.Backend, c.Backend)
+	if _, err := bound.RawTransact(c.Auth(t, 0), calldata); err != nil {
+		t.Fatalf("failed to apply scripted responses: %v", err)
+	}
+	c.Backend.Commit()
+}
+
+// AutoCommit mines a block every interval until the test ends, so code waiting for receipts progresses
+func (c *Chain) AutoCommit(t testing.TB, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Backend.Commit()
+			}
+		}
+	}()
+}
+
+// Miner is a backend that mines every transaction as soon as it is sent
+type Miner struct {
+	*backends.SimulatedBackend
+}
+
+// Miner returns a backend for the chain that mines each sent transaction in its own block,
+// so code waiting for receipts does not depend on the polling interval of bind.WaitMined
+func (c *Chain) Miner() *Miner {
+	return &Miner{c.Backend}
+}
+
+// SendTransaction sends tx and mines it
+func (m *Miner) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := m.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	m.Commit()
+	return nil
+}
@@ -0,0 +1,129 @@
+package simchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testABI = `[
+	{"type":"function","name":"get","stateMutability":"view","inputs":[{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"id","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Set","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true},{"name":"by","type":"address","indexed":true},{"name":"note","type":"string","indexed":false}]}
+]`
+
+var testAddr = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func call(t *testing.T, chain *Chain, c *Contract, method string, args ...interface{}) ([]interface{}, error) {
+	t.Helper()
+
+	bound := bind.NewBoundContract(c.Address, c.ABI, chain.Backend, chain.Backend, chain.Backend)
+	var out []interface{}
+	err := bound.Call(&bind.CallOpts{Context: context.Background()}, &out, method, args...)
+	return out, err
+}
+
+func TestScriptedCalls(t *testing.T) {
+	c := NewContractJSON(testAddr, testABI)
+	c.On("get", big.NewInt(1)).Returns(big.NewInt(100))
+	c.On("get").Returns(big.NewInt(7))
+	c.On("name").Returns("a name longer than one thirty-two byte storage word")
+	chain := New(t, c)
+
+	tests := []struct {
+		name   string
+		method string
+		args   []interface{}
+		want   interface{}
+	}{
+		{"exact calldata", "get", []interface{}{big.NewInt(1)}, big.NewInt(100)},
+		{"selector fallback", "get", []interface{}{big.NewInt(2)}, big.NewInt(7)},
+		{"dynamic result", "name", nil, "a name longer than one thirty-two byte storage word"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := call(t, chain, c, tt.method, tt.args...)
+			if err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			switch want := tt.want.(type) {
+			case *big.Int:
+				if got := out[0].(*big.Int); got.Cmp(want) != 0 {
+					t.Errorf("got %s, want %s", got, want)
+				}
+			default:
+				if out[0] != want {
+					t.Errorf("got %v, want %v", out[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestScriptedRevert(t *testing.T) {
+	c := NewContractJSON(testAddr, testABI)
+	c.On("get", big.NewInt(1)).Reverts()
+	chain := New(t, c)
+
+	if _, err := call(t, chain, c, "get", big.NewInt(1)); err == nil {
+		t.Error("expected configured revert")
+	}
+	if _, err := call(t, chain, c, "name"); err == nil {
+		t.Error("expected unconfigured call to revert")
+	}
+}
+
+func TestScriptedTransitionsAndEvents(t *testing.T) {
+	c := NewContractJSON(testAddr, testABI)
+	chain := New(t, c)
+	sender := chain.Address(0)
+
+	c.On("get", big.NewInt(1)).Returns(big.NewInt(0))
+	c.On("set", big.NewInt(1)).
+		Emits("Set", big.NewInt(1), sender, "hello").
+		Then(c.Next("get", big.NewInt(1)).Returns(big.NewInt(42)))
+	chain.Apply(t, c)
+
+	out, err := call(t, chain, c, "get", big.NewInt(1))
+	if err != nil || out[0].(*big.Int).Sign() != 0 {
+		t.Fatalf("before set: got %v, %v", out, err)
+	}
+
+	bound := bind.NewBoundContract(c.Address, c.ABI, chain.Backend, chain.Backend, chain.Backend)
+	tx, err := bound.Transact(chain.Auth(t, 0), "set", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	chain.Backend.Commit()
+
+	receipt, err := chain.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("no receipt: %v", err)
+	}
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("got %d logs, want 1", len(receipt.Logs))
+	}
+	log := receipt.Logs[0]
+	if log.Topics[0] != c.ABI.Events["Set"].ID || log.Topics[2] != common.BytesToHash(sender.Bytes()) {
+		t.Errorf("unexpected topics %v", log.Topics)
+	}
+	data, err := c.ABI.Events["Set"].Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil || data[0] != "hello" {
+		t.Errorf("unexpected data %v, %v", data, err)
+	}
+
+	out, err = call(t, chain, c, "get", big.NewInt(1))
+	if err != nil || out[0].(*big.Int).Int64() != 42 {
+		t.Fatalf("after set: got %v, %v", out, err)
+	}
+
+	logs, err := chain.Backend.FilterLogs(context.Background(), ethereum.FilterQuery{Addresses: []common.Address{c.Address}})
+	if err != nil || len(logs) != 1 {
+		t.Errorf("FilterLogs: got %d logs, %v", len(logs), err)
+	}
+}
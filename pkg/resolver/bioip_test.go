@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/bioip"
+	"github.com/Genobank/biofs/pkg/chainrpc"
+	"github.com/Genobank/biofs/pkg/consent"
+	"github.com/Genobank/biofs/pkg/internal/simchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// registryAsset mirrors the BioIPRegistry.BioIPAsset tuple getBioIP returns
+type registryAsset struct {
+	Owner          common.Address
+	TokenId        *big.Int
+	ConsentState   uint8
+	CreatedAt      *big.Int
+	RevokedAt      *big.Int
+	ContentHash    [32]byte
+	DataType       string
+	DataSize       *big.Int
+	BioCID         [32]byte
+	IpAssetId      common.Address
+	LicenseTermsId *big.Int
+	HasLicense     bool
+	ParentTokenId  *big.Int
+	ChildTokenIds  []*big.Int
+	Generation     *big.Int
+	LicenseTokenId *big.Int
+}
+
+// registryTuple returns the getBioIP tuple of a root asset holding content hash {id}; id 0 is unminted
+func registryTuple(id int64, state consent.ConsentState) registryAsset {
+	asset := registryAsset{
+		TokenId:        big.NewInt(id),
+		ConsentState:   uint8(state),
+		CreatedAt:      new(big.Int),
+		RevokedAt:      new(big.Int),
+		DataSize:       new(big.Int),
+		LicenseTermsId: new(big.Int),
+		ParentTokenId:  new(big.Int),
+		ChildTokenIds:  []*big.Int{},
+		Generation:     new(big.Int),
+		LicenseTokenId: new(big.Int),
+	}
+	if id != 0 {
+		asset.Owner = wallet
+		asset.CreatedAt = big.NewInt(1700000000)
+		asset.ContentHash = [32]byte{byte(id)}
+		asset.DataType = "vcf"
+		asset.DataSize = big.NewInt(1024)
+	}
+	return asset
+}
+
+// TestResolveWithBioIPManager resolves biofs:// URIs, which carry no content hash, through a real
+// BioIPManager reading a scripted registry
+func TestResolveWithBioIPManager(t *testing.T) {
+	abiJSON, err := os.ReadFile("../bioip/BioIPRegistry.abi.json")
+	if err != nil {
+		t.Fatalf("failed to read the registry ABI: %v", err)
+	}
+	reg := simchain.NewContractJSON(common.HexToAddress(testCollection), string(abiJSON))
+	reg.On("getBioIP").Returns(registryTuple(0, 0))
+	reg.On("getBioIP", big.NewInt(1)).Returns(registryTuple(1, consent.ConsentActive))
+	reg.On("getBioIP", big.NewInt(2)).Returns(registryTuple(2, consent.ConsentRevoked))
+	chain := simchain.New(t, reg)
+
+	m := bioip.NewBioIPManager(
+		bioip.WithBackend("story", chain.Miner()),
+		bioip.WithRegistry("story", common.HexToAddress(testCollection)),
+		bioip.WithRetryPolicy(chainrpc.RetryPolicy{MaxAttempts: 1}),
+	)
+	defer m.Close()
+	r := NewResolver(fakeConsent{wallet: true}, m)
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantHash biocid.ContentHash
+		wantErr  error
+	}{
+		{name: "active", uri: uri("1", "/calls/chr1.vcf"), wantHash: biocid.ContentHash{1}},
+		{name: "revoked", uri: uri("2", "/x"), wantErr: consent.ErrConsentRevoked},
+		{name: "unminted", uri: uri("9", "/x"), wantErr: bioip.ErrTokenNotFound},
+		{name: "foreign collection", uri: "biofs://story/0x00000000000000000000000000000000000000C3/1/x", wantErr: bioip.ErrCollectionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.Resolve(context.Background(), tt.uri, wallet)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if res.ContentHash != tt.wantHash {
+				t.Errorf("got %s, want %s", res.ContentHashHex(), tt.wantHash.Hex())
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package resolver
+
+import "errors"
+
+var (
+	// ErrAccessDenied is returned when the caller's wallet has no consent for the token
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrNoFetcher is returned by Fetch when the resolver has no content fetcher configured
+	ErrNoFetcher = errors.New("no content fetcher configured")
+)
@@ -0,0 +1,134 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/bioip"
+	"github.com/Genobank/biofs/pkg/consent"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ConsentVerifier checks whether a wallet may access an NFT
+// *consent.ConsentChecker satisfies this interface
+type ConsentVerifier interface {
+	CheckConsent(ctx context.Context, nftRef biocid.NFTReference, wallet common.Address) (bool, error)
+}
+
+// AssetLookup resolves a BioCID to its on-chain BioIP asset
+// Lookups of a collection other than the chain's registry must fail with bioip.ErrCollectionMismatch
+// *bioip.BioIPManager satisfies this interface
+type AssetLookup interface {
+	BioCIDToBioIP(ctx context.Context, b *biocid.BioCID) (*bioip.BioIPAsset, error)
+}
+
+// FetchFunc retrieves the content stored under a content hash
+// path is the file path from the biofs URI, always starting with "/"
+type FetchFunc func(ctx context.Context, contentHash biocid.ContentHash, path string) (io.ReadCloser, error)
+
+// Resolver turns biofs:// URIs into content-addressed bytes, enforcing consent
+type Resolver struct {
+	consent ConsentVerifier
+	assets  AssetLookup
+	fetch   FetchFunc
+}
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithFetcher sets the hook used to retrieve content by hash
+func WithFetcher(fetch FetchFunc) Option {
+	return func(r *Resolver) {
+		r.fetch = fetch
+	}
+}
+
+// NewResolver creates a resolver backed by the given consent and asset lookups
+func NewResolver(consent ConsentVerifier, assets AssetLookup, opts ...Option) *Resolver {
+	r := &Resolver{
+		consent: consent,
+		assets:  assets,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolution is the result of resolving a biofs:// URI
+type Resolution struct {
+	NFTRef      biocid.NFTReference
+	Path        string
+	ContentHash biocid.ContentHash
+	Asset       *bioip.BioIPAsset
+}
+
+// ContentHashHex returns the content hash as lowercase hex, matching BioCID.ContentHash
+func (res *Resolution) ContentHashHex() string {
+	return res.ContentHash.Hex()
+}
+
+// Resolve verifies that wallet has consent for the URI's token and looks up its content hash
+// The URI's collection must be the chain's BioIPRegistry, which holds both the asset and its consent;
+// the asset lookup rejects any other collection before consent is checked against it
+// Revoked or deleted consent is rejected with consent.ErrConsentRevoked or consent.ErrConsentDeleted
+func (r *Resolver) Resolve(ctx context.Context, uri string, wallet common.Address) (*Resolution, error) {
+	nftRef, path, err := biocid.ParseBiofsURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := r.assets.BioCIDToBioIP(ctx, &biocid.BioCID{
+		Chain:      nftRef.Chain,
+		Collection: nftRef.Collection,
+		TokenID:    nftRef.TokenID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up bioip for %s: %w", nftRef, err)
+	}
+
+	ok, err := r.consent.CheckConsent(ctx, nftRef, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check consent: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no consent for %s", ErrAccessDenied, wallet.Hex(), nftRef)
+	}
+
+	switch consent.ConsentState(asset.ConsentState) {
+	case consent.ConsentRevoked:
+		return nil, fmt.Errorf("%s: %w", nftRef, consent.ErrConsentRevoked)
+	case consent.ConsentDeleted:
+		return nil, fmt.Errorf("%s: %w", nftRef, consent.ErrConsentDeleted)
+	}
+
+	return &Resolution{
+		NFTRef:      nftRef,
+		Path:        path,
+		ContentHash: asset.ContentHash,
+		Asset:       asset,
+	}, nil
+}
+
+// Fetch resolves the URI and opens its content through the configured fetcher
+func (r *Resolver) Fetch(ctx context.Context, uri string, wallet common.Address) (io.ReadCloser, error) {
+	if r.fetch == nil {
+		return nil, ErrNoFetcher
+	}
+
+	res, err := r.Resolve(ctx, uri, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := r.fetch(ctx, res.ContentHash, res.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", res.ContentHashHex(), err)
+	}
+
+	return rc, nil
+}
@@ -0,0 +1,162 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Genobank/biofs/pkg/biocid"
+	"github.com/Genobank/biofs/pkg/bioip"
+	"github.com/Genobank/biofs/pkg/consent"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testCollection = "0xC91940118822d247B46d1eba6b7Ed2a16F3adc36"
+
+var (
+	wallet   = common.HexToAddress("0x00000000000000000000000000000000000000A1")
+	stranger = common.HexToAddress("0x00000000000000000000000000000000000000B2")
+)
+
+// fakeConsent grants consent to the listed wallets and fails for token 500
+type fakeConsent map[common.Address]bool
+
+func (f fakeConsent) CheckConsent(ctx context.Context, nftRef biocid.NFTReference, w common.Address) (bool, error) {
+	if nftRef.TokenID == "500" {
+		return false, errors.New("rpc down")
+	}
+	return f[w], nil
+}
+
+// fakeAssets serves assets by token ID from the testCollection registry
+type fakeAssets map[string]*bioip.BioIPAsset
+
+func (f fakeAssets) BioCIDToBioIP(ctx context.Context, b *biocid.BioCID) (*bioip.BioIPAsset, error) {
+	if common.HexToAddress(b.Collection) != common.HexToAddress(testCollection) {
+		return nil, fmt.Errorf("%w: %s", bioip.ErrCollectionMismatch, b.Collection)
+	}
+	asset, ok := f[b.TokenID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", bioip.ErrTokenNotFound, b.TokenID)
+	}
+	return asset, nil
+}
+
+// fakeAsset is an asset holding content hash {id} in the given consent state
+func fakeAsset(id int64, state consent.ConsentState) *bioip.BioIPAsset {
+	return &bioip.BioIPAsset{
+		TokenID:      big.NewInt(id),
+		ConsentState: uint8(state),
+		ContentHash:  biocid.ContentHash{byte(id)},
+	}
+}
+
+func uri(tokenID, path string) string {
+	return "biofs://story/" + testCollection + "/" + tokenID + path
+}
+
+func TestResolve(t *testing.T) {
+	r := NewResolver(
+		fakeConsent{wallet: true},
+		fakeAssets{
+			"1":   fakeAsset(1, consent.ConsentActive),
+			"2":   fakeAsset(2, consent.ConsentRevoked),
+			"3":   fakeAsset(3, consent.ConsentDeleted),
+			"500": fakeAsset(500, consent.ConsentActive),
+		},
+	)
+
+	tests := []struct {
+		name     string
+		uri      string
+		wallet   common.Address
+		wantHash biocid.ContentHash
+		wantPath string
+		wantErr  error
+	}{
+		{name: "active", uri: uri("1", "/calls/chr1.vcf"), wallet: wallet, wantHash: biocid.ContentHash{1}, wantPath: "/calls/chr1.vcf"},
+		{name: "no path", uri: uri("1", ""), wallet: wallet, wantHash: biocid.ContentHash{1}, wantPath: "/"},
+		{name: "no consent", uri: uri("1", "/x"), wallet: stranger, wantErr: ErrAccessDenied},
+		{name: "revoked", uri: uri("2", "/x"), wallet: wallet, wantErr: consent.ErrConsentRevoked},
+		{name: "deleted", uri: uri("3", "/x"), wallet: wallet, wantErr: consent.ErrConsentDeleted},
+		{name: "unminted", uri: uri("9", "/x"), wallet: wallet, wantErr: bioip.ErrTokenNotFound},
+		{name: "malformed", uri: "biofs://story", wallet: wallet, wantErr: biocid.ErrInvalidURI},
+		{name: "lowercase collection", uri: "biofs://story/" + strings.ToLower(testCollection) + "/1/x", wallet: wallet, wantHash: biocid.ContentHash{1}, wantPath: "/x"},
+		{name: "foreign collection", uri: "biofs://story/0x00000000000000000000000000000000000000C3/1/x", wallet: wallet, wantErr: bioip.ErrCollectionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.Resolve(context.Background(), tt.uri, tt.wallet)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if res.ContentHash != tt.wantHash || res.Path != tt.wantPath {
+				t.Errorf("got %s at %q, want %s at %q", res.ContentHashHex(), res.Path, tt.wantHash.Hex(), tt.wantPath)
+			}
+		})
+	}
+
+	if _, err := r.Resolve(context.Background(), uri("500", "/x"), wallet); err == nil {
+		t.Error("expected consent check failures to be returned")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	store := map[biocid.ContentHash]string{{1}: "##fileformat=VCFv4.2"}
+	fetch := func(ctx context.Context, hash biocid.ContentHash, path string) (io.ReadCloser, error) {
+		content, ok := store[hash]
+		if !ok {
+			return nil, errors.New("not stored")
+		}
+		return io.NopCloser(strings.NewReader(path + ":" + content)), nil
+	}
+	assets := fakeAssets{
+		"1": fakeAsset(1, consent.ConsentActive),
+		"2": fakeAsset(2, consent.ConsentRevoked),
+		"4": fakeAsset(4, consent.ConsentActive),
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		uri     string
+		want    string
+		wantErr error
+	}{
+		{name: "fetched", opts: []Option{WithFetcher(fetch)}, uri: uri("1", "/a.vcf"), want: "/a.vcf:##fileformat=VCFv4.2"},
+		{name: "revoked", opts: []Option{WithFetcher(fetch)}, uri: uri("2", "/a.vcf"), wantErr: consent.ErrConsentRevoked},
+		{name: "backend fails", opts: []Option{WithFetcher(fetch)}, uri: uri("4", "/a.vcf")},
+		{name: "no fetcher", uri: uri("1", "/a.vcf"), wantErr: ErrNoFetcher},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewResolver(fakeConsent{wallet: true}, assets, tt.opts...)
+			rc, err := r.Fetch(context.Background(), tt.uri, wallet)
+			if tt.wantErr != nil || tt.want == "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			defer rc.Close()
+
+			got, err := io.ReadAll(rc)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}